@@ -22,54 +22,154 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"os"
+	"errors"
 
 	"github.com/2785/warframe-assistant/internal/discord"
 	"github.com/bwmarrin/discordgo"
-	"github.com/davecgh/go-spew/spew"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	registerGuildID string
+	registerSync    bool
+	registerDryRun  bool
+	registerPrune   bool
 )
 
 // registerCommandsCmd represents the registerCommands command
 var registerCommandsCmd = &cobra.Command{
 	Use:   "registerCommands",
 	Short: "Register known commands with discord",
+	Long: `Register the slash commands defined by the bot with discord.
+
+Commands are registered globally unless --guild is given, global commands
+can take up to an hour to propagate while guild commands show up instantly,
+which makes --guild handy during development.
+
+By default every local command is created or updated one by one. Use --sync
+to replace everything registered with the local definitions in one bulk
+overwrite, --prune to delete registered commands that no longer exist
+locally, and --dry-run to only print the difference.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dg, err := discordgo.New("Bot " + os.Getenv("BOT_TOKEN"))
+		if !viper.IsSet("bot_token") {
+			return errors.New("Bot token must be supplied")
+		}
+		dg, err := discordgo.New("Bot " + viper.GetString("bot_token"))
 		if err != nil {
 			return err
 		}
 
-		discordEventHandler := &discord.EventHandler{}
-
-		err = dg.Open()
+		// the application ID of a bot is its user ID, no need to open a gateway connection for it
+		self, err := dg.User("@me")
 		if err != nil {
 			return err
 		}
+		appID := self.ID
 
+		discordEventHandler := &discord.EventHandler{}
 		err = discordEventHandler.RegisterInteractionCreateHandlers(dg)
 		if err != nil {
 			return err
 		}
+		local := discordEventHandler.Commands
 
-		for _, v := range discordEventHandler.Commands {
-			_, err := dg.ApplicationCommandCreate(dg.State.User.ID, "", v)
+		remote, err := dg.ApplicationCommands(appID, registerGuildID)
+		if err != nil {
+			return err
+		}
+
+		diff := discord.DiffCommands(local, remote)
+		printCommandDiff(cmd, diff)
+
+		if registerDryRun {
+			return nil
+		}
+
+		switch {
+		case registerSync:
+			_, err := dg.ApplicationCommandBulkOverwrite(appID, registerGuildID, local)
 			if err != nil {
 				return err
 			}
+		default:
+			for _, v := range diff.Create {
+				_, err := dg.ApplicationCommandCreate(appID, registerGuildID, v)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, v := range diff.Update {
+				_, err := dg.ApplicationCommandEdit(appID, registerGuildID, v.ID, v)
+				if err != nil {
+					return err
+				}
+			}
+
+			if registerPrune {
+				for _, v := range diff.Stale {
+					err := dg.ApplicationCommandDelete(appID, registerGuildID, v.ID)
+					if err != nil {
+						return err
+					}
+				}
+			}
 		}
 
-		cmds, err := dg.ApplicationCommands(dg.State.User.ID, "")
+		cmds, err := dg.ApplicationCommands(appID, registerGuildID)
 		if err != nil {
 			return err
 		}
 
-		spew.Dump(cmds)
+		cmd.Printf("%d command(s) now registered:\n", len(cmds))
+		for _, v := range cmds {
+			cmd.Printf("  %s (%s)\n", v.Name, v.ID)
+		}
 
 		return nil
 	},
 }
 
+func printCommandDiff(cmd *cobra.Command, diff *discord.CommandDiff) {
+	scope := "global"
+	if registerGuildID != "" {
+		scope = "guild " + registerGuildID
+	}
+
+	if diff.Empty() {
+		cmd.Printf("Commands for %s are up to date\n", scope)
+		return
+	}
+
+	cmd.Printf("Changes for %s:\n", scope)
+	for _, v := range diff.Create {
+		cmd.Printf("  + %s\n", v.Name)
+	}
+	for _, v := range diff.Update {
+		cmd.Printf("  ~ %s\n", v.Name)
+	}
+	for _, v := range diff.Stale {
+		action := "stale, use --prune or --sync to remove"
+		if registerPrune || registerSync {
+			action = "stale, will be removed"
+		}
+		cmd.Printf("  - %s (%s)\n", v.Name, action)
+	}
+	for _, v := range diff.Unchanged {
+		cmd.Printf("  = %s\n", v)
+	}
+}
+
 func init() {
+	registerCommandsCmd.Flags().
+		StringVar(&registerGuildID, "guild", "", "Register commands for this guild ID only instead of globally")
+	registerCommandsCmd.Flags().
+		BoolVar(&registerSync, "sync", false, "Bulk overwrite the registered commands with the local definitions")
+	registerCommandsCmd.Flags().
+		BoolVar(&registerDryRun, "dry-run", false, "Print the difference between local and registered commands without changing anything")
+	registerCommandsCmd.Flags().
+		BoolVar(&registerPrune, "prune", false, "Delete registered commands that are no longer defined locally")
+
 	rootCmd.AddCommand(registerCommandsCmd)
 }
@@ -24,14 +24,23 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+	"go.uber.org/zap/zapcore"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
 
-var cfgFile string
+var (
+	cfgFile     string
+	botToken    string
+	databaseURL string
+	redisURL    string
+	logLevel    string
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "warframe-assistant",
@@ -41,7 +50,9 @@ from a variety of sources including config files, env
 var, and cli arguments. 
 
 Use 'registerCommands' to hook up slash commands with
-discord.`,
+discord.
+
+Use 'serveAPI' to serve the read-only HTTP API.`,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -58,6 +69,44 @@ func init() {
 
 	rootCmd.PersistentFlags().
 		StringVar(&cfgFile, "config", "", "config file (default is $HOME/.warframe-assistant.yaml)")
+
+	rootCmd.PersistentFlags().StringVar(&botToken, "bot-token", "", "Discord bot token")
+	err := viper.BindPFlag("bot_token", rootCmd.PersistentFlags().Lookup("bot-token"))
+	if err != nil {
+		panic(err)
+	}
+
+	rootCmd.PersistentFlags().
+		StringVar(&databaseURL, "database-url", "", "Database URL to connect to a Postgres instance")
+	err = viper.BindPFlag("database_url", rootCmd.PersistentFlags().Lookup("database-url"))
+	if err != nil {
+		panic(err)
+	}
+
+	rootCmd.PersistentFlags().StringVar(&redisURL, "redis-url", "", "URL to connect to redis")
+	err = viper.BindPFlag("redis_url", rootCmd.PersistentFlags().Lookup("redis-url"))
+	if err != nil {
+		panic(err)
+	}
+
+	rootCmd.PersistentFlags().
+		StringVar(&logLevel, "log-level", "info", "Log level, select between:\n"+strings.Join(
+			funk.Map(
+				[]zapcore.Level{
+					zapcore.DebugLevel,
+					zapcore.InfoLevel,
+					zapcore.WarnLevel,
+					zapcore.ErrorLevel,
+					zapcore.DPanicLevel,
+					zapcore.PanicLevel,
+					zapcore.FatalLevel,
+				}, func(l zapcore.Level) string { return l.String() }).([]string),
+			"\n",
+		))
+	err = viper.BindPFlag("log_level", rootCmd.PersistentFlags().Lookup("log-level"))
+	if err != nil {
+		panic(err)
+	}
 }
 
 // initConfig reads in config file and ENV variables if set.
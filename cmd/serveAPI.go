@@ -0,0 +1,112 @@
+/*
+Copyright © 2021 Shiqi Zhao <zhao.shiqi.art@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+var apiAddr string
+
+// serveAPICmd represents the serveAPI command
+var serveAPICmd = &cobra.Command{
+	Use:   "serveAPI",
+	Short: "Serve the read-only HTTP API for events and leaderboards",
+	Long: `Serve a read-only JSON API over the events of a guild, for
+showing standings on a website without discord access.
+
+Every request needs the API key of a guild, either as a bearer
+token or in the X-API-Key header, keys are generated with the
+/api-key slash command. The API does not need a discord
+connection, only the database and cache used by the bot.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger, err := newLogger("serveAPI")
+		if err != nil {
+			return err
+		}
+
+		db, err := openDatabase(logger)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		c, err := openCache(logger)
+		if err != nil {
+			return err
+		}
+
+		metadataService := newMetadataService(db, c, logger)
+
+		srv := &http.Server{
+			Addr: viper.GetString("api_addr"),
+			Handler: (&api.Server{
+				Events:        metadataService,
+				Participation: metadataService,
+				Keys:          metadataService,
+				Scores:        newScoresService(db, logger),
+				Logger:        logger.With(zap.String("co", "api-server")),
+			}).Handler(),
+		}
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- srv.ListenAndServe()
+		}()
+		logger.Info("serving api", zap.String("addr", srv.Addr))
+
+		sc := make(chan os.Signal, 1)
+		signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
+
+		select {
+		case err := <-errs:
+			return err
+		case <-sc:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err = srv.Shutdown(ctx)
+		logger.Info("server terminated")
+		return err
+	},
+}
+
+func init() {
+	serveAPICmd.Flags().StringVar(&apiAddr, "api-addr", ":8081", "Address to serve the API on")
+	err := viper.BindPFlag("api_addr", serveAPICmd.Flags().Lookup("api-addr"))
+	if err != nil {
+		panic(err)
+	}
+
+	rootCmd.AddCommand(serveAPICmd)
+}
@@ -22,53 +22,58 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"errors"
+	"io"
+	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
 	"github.com/2785/warframe-assistant/internal/cache"
+	"github.com/2785/warframe-assistant/internal/cluster"
+	"github.com/2785/warframe-assistant/internal/dashboard"
 	"github.com/2785/warframe-assistant/internal/discord"
-	"github.com/2785/warframe-assistant/internal/meta"
-	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/health"
+	"github.com/2785/warframe-assistant/internal/metrics"
+	"github.com/2785/warframe-assistant/internal/webhook"
 	"github.com/bwmarrin/discordgo"
-	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"github.com/thoas/go-funk"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
 var (
-	databaseURL string
-	redisURL    string
-	botToken    string
-	logLevel    string
+	httpAddr        string
+	dashboardURL    string
+	shutdownTimeout time.Duration
+	shardID         int
+	shardCount      int
+	prefix          string
 )
 
+// webhookWorkers is how many webhook deliveries can be in flight at once
+const webhookWorkers = 4
+
 // serveBotCmd represents the serveBot command
 var serveBotCmd = &cobra.Command{
 	Use:   "serveBot",
 	Short: "Connect to discord gateway and bring up the bot",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		zapConf := zap.NewProductionConfig()
-		l := zapcore.InfoLevel
-
-		err := l.Set(viper.GetString("log_level"))
+		logger, err := newLogger("serveBot")
 		if err != nil {
 			return err
 		}
-		zapConf.Level.SetLevel(l)
 
-		logger, err := zapConf.Build(
-			zap.Fields(zap.String("pl", "warframe-assistant"), zap.String("co", "serveBot")),
+		sigCtx, stopSignals := signal.NotifyContext(
+			context.Background(),
+			syscall.SIGINT,
+			syscall.SIGTERM,
+			os.Interrupt,
 		)
-		if err != nil {
-			return err
-		}
+		defer stopSignals()
 
 		if !viper.IsSet("bot_token") {
 			return errors.New("Bot token must be supplied")
@@ -78,122 +83,279 @@ var serveBotCmd = &cobra.Command{
 			return err
 		}
 
-		if !viper.IsSet("database_url") {
-			return errors.New("Database URL must be supplied")
+		db, err := openDatabase(logger)
+		if err != nil {
+			return err
 		}
-		db, err := sqlx.Open("postgres", viper.GetString("database_url"))
+
+		c, err := openCache(logger)
 		if err != nil {
 			return err
 		}
-		logger.Info("connected to postgres")
 
-		var c cache.Cache
+		pgService := newScoresService(db, logger)
+		metadataService := newMetadataService(db, c, logger)
 
-		if viper.IsSet("redis_url") {
-			c, err = cache.NewRedis(viper.GetString("redis_url"), 10*time.Minute)
-			if err != nil {
-				return err
-			}
-			logger.Info("connected to redis")
-		} else {
-			c = cache.NewMemory(10 * time.Minute)
-			logger.Warn("redis URL not found, using in memory cache")
-		}
-
-		pgService := &scores.PostgresService{
-			DB:                     db,
-			Logger:                 logger,
-			ScoresTableName:        "event_scores",
-			ParticipationTableName: "participation",
-			UserIGNTableName:       "users",
-		}
-
-		metadataService := meta.NewWithCache(
-			&meta.PostgresService{
-				DB:                 db,
-				ActionRoleTable:    "role_lookup",
-				IGNTable:           "users",
-				EventsTable:        "events",
-				ParticipationTable: "participation",
-				Logger:             logger.With(zap.String("co", "metadata-service-pg"))},
-			cache.Named("meta", c),
-			logger.With(zap.String("co", "metadata-service-cache")))
+		// replicas can only coordinate through redis, without it this is the only one
+		var cl *cluster.Cluster
+		if rc, ok := c.(*cache.Redis); ok {
+			cl = cluster.New(rc.Client(), logger.With(zap.String("co", "cluster")))
+		}
+
+		webhooks := webhook.NewDispatcher(metadataService, logger.With(zap.String("co", "webhooks")))
+		webhooks.Start(webhookWorkers)
 
 		discordEventHandler := &discord.EventHandler{
 			Cache:             cache.Named("dialog", c),
 			Logger:            logger,
-			Prefix:            "?!",
+			Prefix:            viper.GetString("prefix"),
 			EventScoreService: pgService,
 			MetadataService:   metadataService,
+			Settings:          newSettingsService(db, c, logger),
+			Members: discord.NewMemberService(
+				cache.Named("members", c),
+				logger.With(zap.String("co", "member-service")),
+			),
+			Webhooks: webhooks,
+		}
+		if cl != nil {
+			discordEventHandler.Coordinator = cl
+			cl.Handle(discord.LiveLeaderboardTopic, discordEventHandler.ScheduleLiveLeaderboardUpdate)
 		}
 
 		dg.Identify.Intents =
 			discordgo.IntentsGuildMessages +
 				discordgo.IntentsGuildIntegrations
 
+		// the commands have to be routable before the gateway delivers the first interaction
+		err = discordEventHandler.RegisterInteractionCreateHandlers(dg)
+		if err != nil {
+			return err
+		}
+
 		dg.AddHandler(discordEventHandler.HandleMessageCreate)
 		dg.AddHandler(discordEventHandler.HandleInteractionsCreate)
-		err = dg.Open()
+		// the dashboard is served next to the health endpoints, so it needs both to be configured
+		var dash *dashboard.Server
+		if viper.GetString("http_addr") != "" && viper.GetString("dashboard_url") != "" {
+			dash = &dashboard.Server{
+				Session:  dg,
+				Meta:     metadataService,
+				Scores:   pgService,
+				Cache:    cache.Named("dashboard", c),
+				BaseURL:  viper.GetString("dashboard_url"),
+				Logger:   logger.With(zap.String("co", "dashboard")),
+				OnChange: discordEventHandler.ScheduleLiveLeaderboardUpdate,
+				Webhooks: webhooks,
+				Notifier: discordEventHandler,
+			}
+			discordEventHandler.Dashboard = dash
+		}
+
+		dg.ShardID, dg.ShardCount, err = assignShard(sigCtx, cl)
 		if err != nil {
 			return err
 		}
 
-		err = discordEventHandler.RegisterInteractionCreateHandlers(dg)
+		// the shard claim is renewed from here on, connecting to the gateway can take longer than
+		// the claim lasts
+		clusterCtx, stopCluster := context.WithCancel(context.Background())
+		defer stopCluster()
+		clusterDone := make(chan error, 1)
+		if cl != nil {
+			go func() {
+				clusterDone <- cl.Run(clusterCtx)
+			}()
+		}
 
+		err = dg.Open()
 		if err != nil {
+			if cl != nil {
+				stopCluster()
+				<-clusterDone
+			}
 			return err
 		}
+		discordEventHandler.StartScheduler(dg)
 
 		logger.Info(
 			"established websocket to discord",
 			zap.String("session-id", dg.State.SessionID),
+			zap.Int("shard-id", dg.ShardID),
+			zap.Int("shard-count", dg.ShardCount),
 		)
 
-		sc := make(chan os.Signal, 1)
-		signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
-		<-sc
+		var srv *http.Server
+		if addr := viper.GetString("http_addr"); addr != "" {
+			prometheus.MustRegister(metrics.NewBacklogCollector(
+				metadataService,
+				pgService,
+				func() bool { return cl == nil || cl.IsLeader() },
+				logger.With(zap.String("co", "backlog-collector")),
+			))
+
+			healthCache := cache.Named("health", c)
+			mux := http.NewServeMux()
+			mux.Handle("/", (&health.Server{
+				Checks: map[string]health.Check{
+					"gateway": func(ctx context.Context) error {
+						if !dg.DataReady {
+							return errors.New("gateway session is not ready")
+						}
+						return nil
+					},
+					"postgres": db.PingContext,
+					"cache": func(ctx context.Context) error {
+						now := time.Now().Unix()
+						if err := healthCache.Set("probe", now); err != nil {
+							return err
+						}
+						var got int64
+						return healthCache.Get("probe", &got)
+					},
+				},
+				Logger: logger.With(zap.String("co", "health-server")),
+			}).Handler())
+
+			if dash != nil {
+				mux.Handle("/dashboard/", dash.Handler())
+			}
+
+			srv = &http.Server{Addr: addr, Handler: mux}
+
+			go func() {
+				if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					logger.Error("http server stopped", zap.Error(err))
+				}
+			}()
+			logger.Info(
+				"serving health and metrics endpoints",
+				zap.String("addr", addr),
+				zap.Bool("dashboard", dash != nil),
+			)
+		}
+
+		select {
+		case <-sigCtx.Done():
+		case err := <-clusterDone:
+			// another replica is connected as the same shard, nothing else to do but step aside
+			logger.Error("cluster coordination stopped", zap.Error(err))
+			clusterDone <- err
+		}
+
+		timeout := viper.GetDuration("shutdown_timeout")
+		logger.Info("shutting down", zap.Duration("timeout", timeout))
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		// the gateway stays up while draining so interactions coming in meanwhile still get told
+		// to retry instead of failing silently
+		if err := discordEventHandler.Drain(ctx); err != nil {
+			logger.Error("could not drain in-flight handlers", zap.Error(err))
+		}
+
+		if srv != nil {
+			if err := srv.Shutdown(ctx); err != nil {
+				logger.Error("could not shut down http server", zap.Error(err))
+			}
+		}
+
+		if err := dg.Close(); err != nil {
+			logger.Error("could not close discord session", zap.Error(err))
+		}
+
+		// leadership and the shard are released only now, so no other replica picks them up while
+		// this one is still draining
+		if cl != nil {
+			stopCluster()
+			<-clusterDone
+		}
+
+		// nothing queues deliveries once the handlers and http server are done
+		if err := webhooks.Close(ctx); err != nil {
+			logger.Error("could not deliver every pending webhook", zap.Error(err))
+		}
+
+		if err := db.Close(); err != nil {
+			logger.Error("could not close database", zap.Error(err))
+		}
+
+		if closer, ok := c.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.Error("could not close cache", zap.Error(err))
+			}
+		}
 
-		dg.Close()
 		logger.Info("server terminated")
 		return nil
 	},
 }
 
+// assignShard decides which shard this replica connects as, either as configured or by claiming a
+// free one through the cluster when only the shard count is set.
+func assignShard(ctx context.Context, cl *cluster.Cluster) (int, int, error) {
+	count := viper.GetInt("shard_count")
+	id := viper.GetInt("shard_id")
+
+	switch {
+	case count < 1:
+		return 0, 0, errors.New("Shard count must be at least 1")
+	case id >= count:
+		return 0, 0, errors.New("Shard ID must be lower than the shard count")
+	case id >= 0:
+		return id, count, nil
+	case count == 1:
+		return 0, 1, nil
+	case cl == nil:
+		return 0, 0, errors.New("Shard ID must be supplied when running multiple shards without redis")
+	}
+
+	id, err := cl.AcquireShard(ctx, count)
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, count, nil
+}
+
 func init() {
 	serveBotCmd.Flags().
-		StringVar(&databaseURL, "database-url", "", "Database URL to connect to a Postgres instance")
-	err := viper.BindPFlag("database_url", serveBotCmd.Flags().Lookup("database-url"))
+		StringVar(&httpAddr, "http-addr", "", "Address to serve /healthz, /readyz and /metrics on, disabled if empty")
+	err := viper.BindPFlag("http_addr", serveBotCmd.Flags().Lookup("http-addr"))
 	if err != nil {
 		panic(err)
 	}
 
-	serveBotCmd.Flags().StringVar(&redisURL, "redis-url", "", "URL to connect to redis")
-	err = viper.BindPFlag("redis_url", serveBotCmd.Flags().Lookup("redis-url"))
+	serveBotCmd.Flags().
+		StringVar(&dashboardURL, "dashboard-url", "", "Public URL the http listener is reachable at, enables the moderation dashboard")
+	err = viper.BindPFlag("dashboard_url", serveBotCmd.Flags().Lookup("dashboard-url"))
 	if err != nil {
 		panic(err)
 	}
 
-	serveBotCmd.Flags().StringVar(&botToken, "bot-token", "", "URL to connect to redis")
-	err = viper.BindPFlag("bot_token", serveBotCmd.Flags().Lookup("bot-token"))
+	serveBotCmd.Flags().
+		DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "How long to wait for in-flight work on shutdown")
+	err = viper.BindPFlag("shutdown_timeout", serveBotCmd.Flags().Lookup("shutdown-timeout"))
+	if err != nil {
+		panic(err)
+	}
+
+	serveBotCmd.Flags().
+		IntVar(&shardCount, "shard-count", 1, "Total number of gateway shards across every replica")
+	err = viper.BindPFlag("shard_count", serveBotCmd.Flags().Lookup("shard-count"))
+	if err != nil {
+		panic(err)
+	}
+
+	serveBotCmd.Flags().
+		IntVar(&shardID, "shard-id", -1, "Shard this replica connects as, claimed automatically through redis if negative")
+	err = viper.BindPFlag("shard_id", serveBotCmd.Flags().Lookup("shard-id"))
 	if err != nil {
 		panic(err)
 	}
 
 	serveBotCmd.Flags().
-		StringVar(&logLevel, "log-level", "info", "Log level, select between:\n"+strings.Join(
-			funk.Map(
-				[]zapcore.Level{
-					zapcore.DebugLevel,
-					zapcore.InfoLevel,
-					zapcore.WarnLevel,
-					zapcore.ErrorLevel,
-					zapcore.DPanicLevel,
-					zapcore.PanicLevel,
-					zapcore.FatalLevel,
-				}, func(l zapcore.Level) string { return l.String() }).([]string),
-			"\n",
-		))
-	err = viper.BindPFlag("log_level", serveBotCmd.Flags().Lookup("log-level"))
+		StringVar(&prefix, "prefix", "?!", "Default prefix of message commands, servers can change theirs with /config")
+	err = viper.BindPFlag("prefix", serveBotCmd.Flags().Lookup("prefix"))
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2021 Shiqi Zhao <zhao.shiqi.art@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/cache"
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/settings"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// the setup below is shared between every command that talks to the database
+
+func newLogger(component string) (*zap.Logger, error) {
+	zapConf := zap.NewProductionConfig()
+	l := zapcore.InfoLevel
+
+	err := l.Set(viper.GetString("log_level"))
+	if err != nil {
+		return nil, err
+	}
+	zapConf.Level.SetLevel(l)
+
+	return zapConf.Build(
+		zap.Fields(zap.String("pl", "warframe-assistant"), zap.String("co", component)),
+	)
+}
+
+func openDatabase(logger *zap.Logger) (*sqlx.DB, error) {
+	if !viper.IsSet("database_url") {
+		return nil, errors.New("Database URL must be supplied")
+	}
+	db, err := sqlx.Open("postgres", viper.GetString("database_url"))
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("connected to postgres")
+	return db, nil
+}
+
+func openCache(logger *zap.Logger) (cache.Cache, error) {
+	if viper.IsSet("redis_url") {
+		c, err := cache.NewRedis(viper.GetString("redis_url"), 10*time.Minute)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("connected to redis")
+		return c, nil
+	}
+
+	logger.Warn("redis URL not found, using in memory cache")
+	return cache.NewMemory(10 * time.Minute), nil
+}
+
+func newScoresService(db *sqlx.DB, logger *zap.Logger) *scores.PostgresService {
+	return &scores.PostgresService{
+		DB:                     db,
+		Logger:                 logger,
+		ScoresTableName:        "event_scores",
+		ParticipationTableName: "participation",
+		UserIGNTableName:       "users",
+		ResultsTableName:       "event_results",
+		ModifiersTableName:     "event_modifiers",
+		CategoriesTableName:    "event_categories",
+		HistoryTableName:       "submission_history",
+		AppealsTableName:       "submission_appeals",
+	}
+}
+
+func newMetadataService(db *sqlx.DB, c cache.Cache, logger *zap.Logger) *meta.CacheService {
+	return meta.NewWithCache(
+		&meta.PostgresService{
+			DB:                   db,
+			ActionRoleTable:      "role_lookup",
+			IGNTable:             "users",
+			EventsTable:          "events",
+			ParticipationTable:   "participation",
+			LiveLeaderboardTable: "live_leaderboards",
+			APIKeyTable:          "api_keys",
+			WebhookTable:         "webhooks",
+			EventTemplateTable:   "event_templates",
+			NotificationTable:    "notification_preferences",
+			Logger:               logger.With(zap.String("co", "metadata-service-pg"))},
+		cache.Named("meta", c),
+		logger.With(zap.String("co", "metadata-service-cache")))
+}
+
+func newSettingsService(db *sqlx.DB, c cache.Cache, logger *zap.Logger) *settings.CacheService {
+	return settings.NewWithCache(
+		&settings.PostgresService{
+			DB:        db,
+			TableName: "guild_settings",
+			Logger:    logger.With(zap.String("co", "settings-service-pg")),
+		},
+		cache.Named("settings", c),
+		logger.With(zap.String("co", "settings-service-cache")))
+}
@@ -1,24 +1,28 @@
-CREATE TABLE role_lookup (
+CREATE TABLE IF NOT EXISTS role_lookup (
     id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
     guild_id text NOT NULL,
     action text NOT NULL,
     role_id text NOT NULL,
     UNIQUE (guild_id, action)
 );
-CREATE TABLE events (
+CREATE TABLE IF NOT EXISTS events (
     id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
     guild_id text NOT NULL,
     name text NOT NULL,
     start_date timestamptz DEFAULT current_timestamp,
     end_date timestamptz NOT NULL,
     active boolean,
-    event_type text
+    event_type text,
+    archived boolean NOT NULL DEFAULT FALSE,
+    finalized_at timestamptz,
+    tie_break text NOT NULL DEFAULT '',
+    reminded_for timestamptz
 );
-CREATE TABLE users (
+CREATE TABLE IF NOT EXISTS users (
     id text NOT NULL PRIMARY KEY,
     ign text NOT NULL
 );
-CREATE TABLE participation (
+CREATE TABLE IF NOT EXISTS participation (
     id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
     user_id text,
     event_id uuid,
@@ -27,11 +31,156 @@ CREATE TABLE participation (
     FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
     UNIQUE (user_id, event_id)
 );
-CREATE TABLE event_scores (
+CREATE TABLE IF NOT EXISTS event_scores (
     id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
     score int NOT NULL,
     proof text NOT NULL,
     verified boolean DEFAULT FALSE,
+    verified_by text NOT NULL DEFAULT '',
+    created_at timestamptz DEFAULT current_timestamp,
+    raw_score int,
+    modifiers jsonb NOT NULL DEFAULT '[]',
+    category text NOT NULL DEFAULT '',
+    removed boolean NOT NULL DEFAULT FALSE,
+    withdrawn boolean NOT NULL DEFAULT FALSE,
     participation_id uuid,
     FOREIGN KEY (participation_id) REFERENCES participation(id) ON DELETE CASCADE
-);
\ No newline at end of file
+);
+CREATE TABLE IF NOT EXISTS live_leaderboards (
+    event_id uuid PRIMARY KEY,
+    channel_id text NOT NULL,
+    message_id text NOT NULL,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS api_keys (
+    guild_id text PRIMARY KEY,
+    key_hash text NOT NULL UNIQUE,
+    created_at timestamptz DEFAULT current_timestamp
+);
+CREATE TABLE IF NOT EXISTS webhooks (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    guild_id text NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    created_at timestamptz DEFAULT current_timestamp
+);
+CREATE TABLE IF NOT EXISTS guild_settings (
+    guild_id text PRIMARY KEY,
+    prefix text NOT NULL DEFAULT '',
+    announcement_channel_id text NOT NULL DEFAULT '',
+    submission_channel_ids text NOT NULL DEFAULT '',
+    timezone text NOT NULL DEFAULT '',
+    default_event_type text NOT NULL DEFAULT '',
+    language text NOT NULL DEFAULT '',
+    submission_threads text NOT NULL DEFAULT '',
+    delete_submissions text NOT NULL DEFAULT '',
+    updated_at timestamptz DEFAULT current_timestamp
+);
+CREATE TABLE IF NOT EXISTS event_results (
+    event_id uuid NOT NULL,
+    position int NOT NULL,
+    rank int NOT NULL,
+    user_id text NOT NULL,
+    ign text NOT NULL,
+    score int NOT NULL,
+    verified_by text[] NOT NULL DEFAULT '{}',
+    mode text NOT NULL DEFAULT '',
+    PRIMARY KEY (event_id, position),
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS event_modifiers (
+    event_id uuid NOT NULL,
+    name text NOT NULL,
+    kind text NOT NULL,
+    value double precision NOT NULL,
+    PRIMARY KEY (event_id, name),
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS event_categories (
+    event_id uuid NOT NULL,
+    name text NOT NULL,
+    mode text NOT NULL,
+    PRIMARY KEY (event_id, name),
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS event_templates (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    guild_id text NOT NULL,
+    name text NOT NULL,
+    name_pattern text NOT NULL,
+    event_type text NOT NULL,
+    duration bigint NOT NULL,
+    rules text NOT NULL DEFAULT '',
+    channel_id text NOT NULL DEFAULT '',
+    schedule text NOT NULL,
+    next_run timestamptz NOT NULL,
+    runs int NOT NULL DEFAULT 0,
+    UNIQUE (guild_id, name)
+);
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id text PRIMARY KEY,
+    submissions boolean NOT NULL DEFAULT FALSE,
+    events boolean NOT NULL DEFAULT FALSE,
+    reminders boolean NOT NULL DEFAULT FALSE,
+    dms_closed boolean NOT NULL DEFAULT FALSE
+);
+CREATE TABLE IF NOT EXISTS submission_history (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    submission_id uuid NOT NULL,
+    action text NOT NULL,
+    actor text NOT NULL,
+    previous_score int NOT NULL,
+    score int NOT NULL,
+    detail text NOT NULL DEFAULT '',
+    created_at timestamptz DEFAULT current_timestamp
+);
+CREATE TABLE IF NOT EXISTS submission_appeals (
+    submission_id uuid PRIMARY KEY,
+    reason text NOT NULL,
+    status text NOT NULL,
+    resolved_by text NOT NULL DEFAULT '',
+    created_at timestamptz DEFAULT current_timestamp,
+    resolved_at timestamptz,
+    FOREIGN KEY (submission_id) REFERENCES event_scores(id) ON DELETE CASCADE
+);
+-- Columns added to tables after they were first created. The whole script can be run again to
+-- upgrade an existing database, every statement is skipped if it was applied before.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS archived boolean NOT NULL DEFAULT FALSE;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS finalized_at timestamptz;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS tie_break text NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN IF NOT EXISTS reminded_for timestamptz;
+ALTER TABLE event_scores ADD COLUMN IF NOT EXISTS verified_by text NOT NULL DEFAULT '';
+ALTER TABLE event_scores ADD COLUMN IF NOT EXISTS created_at timestamptz DEFAULT current_timestamp;
+ALTER TABLE event_scores ADD COLUMN IF NOT EXISTS raw_score int;
+ALTER TABLE event_scores ADD COLUMN IF NOT EXISTS modifiers jsonb NOT NULL DEFAULT '[]';
+ALTER TABLE event_scores ADD COLUMN IF NOT EXISTS category text NOT NULL DEFAULT '';
+ALTER TABLE event_scores ADD COLUMN IF NOT EXISTS removed boolean NOT NULL DEFAULT FALSE;
+ALTER TABLE event_scores ADD COLUMN IF NOT EXISTS withdrawn boolean NOT NULL DEFAULT FALSE;
+ALTER TABLE event_results ADD COLUMN IF NOT EXISTS verified_by text[] NOT NULL DEFAULT '{}';
+ALTER TABLE event_results ADD COLUMN IF NOT EXISTS mode text NOT NULL DEFAULT '';
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS submission_threads text NOT NULL DEFAULT '';
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS delete_submissions text NOT NULL DEFAULT '';
+-- results were first keyed by rank, which shared ranks don't allow
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'event_results' AND column_name = 'position'
+    ) THEN
+        ALTER TABLE event_results ADD COLUMN position int;
+        UPDATE event_results SET position = rank;
+        ALTER TABLE event_results ALTER COLUMN position SET NOT NULL;
+        ALTER TABLE event_results DROP CONSTRAINT event_results_pkey;
+        ALTER TABLE event_results ADD PRIMARY KEY (event_id, position);
+    END IF;
+END $$;
+-- servers were first limited to a single submission channel
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'guild_settings' AND column_name = 'submission_channel_id'
+    ) THEN
+        ALTER TABLE guild_settings RENAME COLUMN submission_channel_id TO submission_channel_ids;
+    END IF;
+END $$;
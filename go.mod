@@ -7,18 +7,18 @@ go 1.16
 require (
 	github.com/Masterminds/squirrel v1.5.0
 	github.com/bwmarrin/discordgo v0.23.3-0.20210627161652-421e14965030
-	github.com/davecgh/go-spew v1.1.1
 	github.com/go-redis/cache/v8 v8.4.1
 	github.com/go-redis/redis/v8 v8.11.2
 	github.com/google/uuid v1.3.0
-	github.com/hako/durafmt v0.0.0-20210608085754-5c1018a4e16b
 	github.com/jmoiron/sqlx v1.3.4
 	github.com/lib/pq v1.10.2
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/ory/dockertest/v3 v3.7.0
+	github.com/prometheus/client_golang v1.11.0
 	github.com/spf13/cobra v1.2.1
 	github.com/spf13/viper v1.8.1
 	github.com/stretchr/testify v1.7.0
 	github.com/thoas/go-funk v0.9.0
 	go.uber.org/zap v1.19.0
+	golang.org/x/image v0.0.0-20210628002857-a66eb6448b8d
 )
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const basePath = "/api/v1/"
+
+// Server is a read-only JSON API over the events of a guild, every request needs an API key which
+// decides the guild the request is scoped to.
+type Server struct {
+	Events        meta.EventService
+	Participation meta.ParticipationService
+	Keys          meta.APIKeyService
+	Scores        scores.ScoresService
+	Logger        *zap.Logger
+}
+
+type Event struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Start       time.Time  `json:"start"`
+	End         time.Time  `json:"end"`
+	Active      bool       `json:"active"`
+	FinalizedAt *time.Time `json:"finalized_at,omitempty"`
+}
+
+type EventDetails struct {
+	Event
+	Participants int `json:"participants"`
+	Submissions  int `json:"submissions"`
+	Verified     int `json:"verified"`
+}
+
+type LeaderboardRow struct {
+	Rank   int    `json:"rank"`
+	UserID string `json:"user_id"`
+	IGN    string `json:"ign"`
+	Score  int    `json:"score"`
+	// VerifiedBy lists the IDs of the moderators who verified the submissions of the user, only
+	// given for final results
+	VerifiedBy []string `json:"verified_by,omitempty"`
+}
+
+type Leaderboard struct {
+	EventID string `json:"event_id"`
+	// Mode is sum when every submission counts, top when only the best one of each user does, and
+	// combined when the event has categories and users are ranked by their placements across them
+	Mode string `json:"mode"`
+	// Final is set once the results of the event are final, the rows won't change anymore
+	Final bool `json:"final"`
+	// TieBreak is how users with the same score are ranked, users still tied share their rank
+	TieBreak string           `json:"tie_break"`
+	Rows     []LeaderboardRow `json:"rows"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler serves everything under `/api/v1/`.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(basePath, s.authenticate(http.HandlerFunc(s.route)))
+	return mux
+}
+
+// guildKey holds the guild ID of the authenticated API key in the request context
+type guildKey struct{}
+
+// authenticate resolves the guild of the API key given as a bearer token or `X-API-Key` header
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			key = strings.TrimPrefix(auth, "Bearer ")
+		}
+
+		if key == "" {
+			s.writeError(w, http.StatusUnauthorized, "missing API key")
+			return
+		}
+
+		gid, err := s.Keys.GetGuildForAPIKey(key)
+		if err != nil {
+			if meta.AsErrNoRecord(err) {
+				s.writeError(w, http.StatusUnauthorized, "invalid API key")
+				return
+			}
+			s.Logger.Error("could not look up API key", zap.Error(err))
+			s.writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), guildKey{}, gid)))
+	})
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	gid, _ := r.Context().Value(guildKey{}).(string)
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, basePath), "/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "events":
+		s.handleListEvents(w, r, gid)
+	case len(parts) == 2 && parts[0] == "events":
+		s.handleGetEvent(w, gid, parts[1])
+	case len(parts) == 3 && parts[0] == "events" && parts[2] == "leaderboard":
+		s.handleLeaderboard(w, gid, parts[1])
+	default:
+		s.writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) handleListEvents(w http.ResponseWriter, r *http.Request, gid string) {
+	var events []*meta.Event
+	var err error
+
+	if r.URL.Query().Get("active") == "true" {
+		events, err = s.Events.ListActiveEventsForGuild(gid)
+	} else {
+		events, err = s.Events.ListEventsForGuild(gid)
+	}
+	if err != nil {
+		s.Logger.Error("could not list events", zap.Error(err), zap.String("gid", gid))
+		s.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	res := make([]Event, len(events))
+	for i, v := range events {
+		res[i] = toEvent(v)
+	}
+
+	s.writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleGetEvent(w http.ResponseWriter, gid, eid string) {
+	event, ok := s.mustGetEvent(w, gid, eid)
+	if !ok {
+		return
+	}
+
+	participants, _, err := s.Participation.ListUserForEvent(eid)
+	if err != nil {
+		s.Logger.Error("could not list participants", zap.Error(err), zap.String("eid", eid))
+		s.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	total, verified, err := s.Scores.VerificationStatus(eid)
+	if err != nil {
+		s.Logger.Error("could not get verification status", zap.Error(err), zap.String("eid", eid))
+		s.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, EventDetails{
+		Event:        toEvent(event),
+		Participants: len(participants),
+		Submissions:  total,
+		Verified:     verified,
+	})
+}
+
+func (s *Server) handleLeaderboard(w http.ResponseWriter, gid, eid string) {
+	event, ok := s.mustGetEvent(w, gid, eid)
+	if !ok {
+		return
+	}
+
+	var records []scores.SummaryRecord
+	var mode scores.LeaderboardMode
+	var err error
+
+	switch event.EventType {
+	case meta.EventTypeScoreCampaign:
+		mode = scores.LeaderboardSum
+	case meta.EventTypeScoreLeaderboard:
+		mode = scores.LeaderboardTop
+	default:
+		s.writeError(w, http.StatusUnprocessableEntity, "leaderboards are not supported for "+event.EventType+" events")
+		return
+	}
+
+	tieBreak := scores.TieBreak(event.TieBreak).OrDefault()
+
+	var categories []scores.Category
+	if !event.Finalized() {
+		categories, err = s.Scores.ListCategories(eid)
+		if err != nil {
+			s.Logger.Error("could not list categories", zap.Error(err), zap.String("eid", eid))
+			s.writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+
+	switch {
+	case event.Finalized():
+		var final scores.LeaderboardMode
+		final, records, err = s.Scores.GetResults(eid)
+		if scores.AsErrNoRecord(err) {
+			// nobody was ranked when the event was finalized
+			records, err = []scores.SummaryRecord{}, nil
+		}
+		// snapshots taken before their mode was kept go by the event type
+		if final != "" {
+			mode = final
+		}
+	case len(categories) > 0:
+		mode = scores.LeaderboardCombined
+		records, err = s.Scores.MakeReportCombined(eid, tieBreak)
+	case mode == scores.LeaderboardSum:
+		records, err = s.Scores.MakeReportScoreSum(eid, tieBreak)
+	default:
+		records, err = s.Scores.MakeReportScoreTop(eid, tieBreak)
+	}
+	if err != nil {
+		s.Logger.Error("could not make leaderboard", zap.Error(err), zap.String("eid", eid))
+		s.writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	rows := make([]LeaderboardRow, len(records))
+	for i, v := range records {
+		rows[i] = LeaderboardRow{Rank: v.Rank, UserID: v.UID, IGN: v.IGN, Score: v.Score, VerifiedBy: v.VerifiedBy}
+	}
+
+	s.writeJSON(w, http.StatusOK, Leaderboard{
+		EventID:  eid,
+		Mode:     string(mode),
+		Final:    event.Finalized(),
+		TieBreak: string(tieBreak),
+		Rows:     rows,
+	})
+}
+
+// mustGetEvent writes a 404 for events that don't exist or belong to another guild, so keys can't
+// be used to probe for events of other guilds.
+func (s *Server) mustGetEvent(w http.ResponseWriter, gid, eid string) (*meta.Event, bool) {
+	if _, err := uuid.Parse(eid); err != nil {
+		s.writeError(w, http.StatusNotFound, "event not found")
+		return nil, false
+	}
+
+	event, err := s.Events.GetEvent(eid)
+	if err != nil {
+		if meta.AsErrNoRecord(err) || errors.Is(err, sql.ErrNoRows) {
+			s.writeError(w, http.StatusNotFound, "event not found")
+			return nil, false
+		}
+		s.Logger.Error("could not get event", zap.Error(err), zap.String("eid", eid))
+		s.writeError(w, http.StatusInternalServerError, "internal error")
+		return nil, false
+	}
+
+	if event.GID != gid {
+		s.writeError(w, http.StatusNotFound, "event not found")
+		return nil, false
+	}
+
+	return event, true
+}
+
+func toEvent(e *meta.Event) Event {
+	return Event{
+		ID:          e.ID,
+		Name:        e.Name,
+		Type:        e.EventType,
+		Start:       e.Begin,
+		End:         e.End,
+		Active:      e.Active,
+		FinalizedAt: e.FinalizedAt,
+	}
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.Logger.Error("could not write response", zap.Error(err))
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, msg string) {
+	s.writeJSON(w, status, errorResponse{msg})
+}
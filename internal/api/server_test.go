@@ -0,0 +1,227 @@
+package api_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/api"
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	eventCampaign    = "5b1b5b4e-7f3c-4a55-9d0b-6f7f5b0b1a01"
+	eventLeaderboard = "5b1b5b4e-7f3c-4a55-9d0b-6f7f5b0b1a02"
+	eventOtherGuild  = "5b1b5b4e-7f3c-4a55-9d0b-6f7f5b0b1a03"
+	eventFinal       = "5b1b5b4e-7f3c-4a55-9d0b-6f7f5b0b1a04"
+	eventCategories  = "5b1b5b4e-7f3c-4a55-9d0b-6f7f5b0b1a05"
+)
+
+// fakeMeta only implements what the API uses, anything else panics on the nil embedded service
+type fakeMeta struct {
+	meta.Service
+	events map[string]*meta.Event
+}
+
+func (f *fakeMeta) GetGuildForAPIKey(key string) (string, error) {
+	if key == "key-1" {
+		return "guild-1", nil
+	}
+	return "", &meta.ErrNoRecord{}
+}
+
+func (f *fakeMeta) GetEvent(id string) (*meta.Event, error) {
+	if e, ok := f.events[id]; ok {
+		return e, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeMeta) ListEventsForGuild(gid string) ([]*meta.Event, error) {
+	events := []*meta.Event{}
+	for _, v := range []string{eventCampaign, eventLeaderboard, eventOtherGuild, eventFinal} {
+		if f.events[v].GID == gid {
+			events = append(events, f.events[v])
+		}
+	}
+	return events, nil
+}
+
+func (f *fakeMeta) ListUserForEvent(eid string) (map[string]string, map[string]string, error) {
+	return map[string]string{"user-1": "ign-1", "user-2": "ign-2"}, map[string]string{}, nil
+}
+
+type fakeScores struct {
+	scores.ScoresService
+}
+
+func (f *fakeScores) MakeReportScoreSum(eid string, tieBreak scores.TieBreak) ([]scores.SummaryRecord, error) {
+	return []scores.SummaryRecord{
+		{UID: "user-2", IGN: "ign-2", Score: 30, Rank: 1},
+		{UID: "user-1", IGN: "ign-1", Score: 10, Rank: 2},
+	}, nil
+}
+
+func (f *fakeScores) MakeReportScoreTop(eid string, tieBreak scores.TieBreak) ([]scores.SummaryRecord, error) {
+	rank := 2
+	if tieBreak == scores.TieBreakShared {
+		rank = 1
+	}
+	return []scores.SummaryRecord{
+		{UID: "user-1", IGN: "ign-1", Score: 20, Rank: 1},
+		{UID: "user-2", IGN: "ign-2", Score: 20, Rank: rank},
+	}, nil
+}
+
+func (f *fakeScores) ListCategories(eid string) ([]scores.Category, error) {
+	if eid != eventCategories {
+		return []scores.Category{}, nil
+	}
+	return []scores.Category{{Name: "tricap", Mode: scores.CategoryLowest}, {Name: "solo", Mode: scores.CategoryTop}}, nil
+}
+
+func (f *fakeScores) MakeReportCombined(eid string, tieBreak scores.TieBreak) ([]scores.SummaryRecord, error) {
+	return []scores.SummaryRecord{
+		{UID: "user-1", IGN: "ign-1", Score: 4, Rank: 1},
+		{UID: "user-2", IGN: "ign-2", Score: 2, Rank: 2},
+	}, nil
+}
+
+func (f *fakeScores) GetResults(eid string) (scores.LeaderboardMode, []scores.SummaryRecord, error) {
+	if eid != eventFinal {
+		return "", nil, &scores.ErrNoRecord{}
+	}
+	// the event had categories when it was finalized
+	return scores.LeaderboardCombined, []scores.SummaryRecord{
+		{UID: "user-1", IGN: "old-ign-1", Score: 15, Rank: 1, VerifiedBy: []string{"mod-1"}},
+	}, nil
+}
+
+func (f *fakeScores) VerificationStatus(eid string) (int, int, error) {
+	return 5, 3, nil
+}
+
+var finalizedAt = time.Date(2022, time.July, 1, 20, 0, 0, 0, time.UTC)
+
+func newServer() http.Handler {
+	m := &fakeMeta{events: map[string]*meta.Event{
+		eventCampaign:    {ID: eventCampaign, GID: "guild-1", Name: "campaign", EventType: meta.EventTypeScoreCampaign},
+		eventLeaderboard: {ID: eventLeaderboard, GID: "guild-1", Name: "leaderboard", EventType: meta.EventTypeScoreLeaderboard},
+		eventOtherGuild:  {ID: eventOtherGuild, GID: "guild-2", Name: "other", EventType: meta.EventTypeScoreCampaign},
+		eventCategories: {
+			ID: eventCategories, GID: "guild-1", Name: "categories", EventType: meta.EventTypeScoreLeaderboard,
+		},
+		eventFinal: {
+			ID: eventFinal, GID: "guild-1", Name: "final", EventType: meta.EventTypeScoreCampaign, FinalizedAt: &finalizedAt,
+		},
+	}}
+
+	return (&api.Server{
+		Events:        m,
+		Participation: m,
+		Keys:          m,
+		Scores:        &fakeScores{},
+		Logger:        zap.NewNop(),
+	}).Handler()
+}
+
+func get(h http.Handler, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuth(t *testing.T) {
+	assert := assert.New(t)
+	h := newServer()
+
+	assert.Equal(http.StatusUnauthorized, get(h, "/api/v1/events", "").Code)
+	assert.Equal(http.StatusUnauthorized, get(h, "/api/v1/events", "wrong-key").Code)
+	assert.Equal(http.StatusOK, get(h, "/api/v1/events", "key-1").Code)
+
+	// events of other guilds are indistinguishable from missing ones
+	assert.Equal(http.StatusNotFound, get(h, "/api/v1/events/"+eventOtherGuild, "key-1").Code)
+	assert.Equal(http.StatusNotFound, get(h, "/api/v1/events/not-a-uuid", "key-1").Code)
+}
+
+func TestEvents(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	h := newServer()
+
+	rec := get(h, "/api/v1/events", "key-1")
+	require.Equal(http.StatusOK, rec.Code)
+	events := []api.Event{}
+	require.NoError(json.Unmarshal(rec.Body.Bytes(), &events))
+	assert.Len(events, 3)
+
+	rec = get(h, "/api/v1/events/"+eventCampaign, "key-1")
+	require.Equal(http.StatusOK, rec.Code)
+	details := api.EventDetails{}
+	require.NoError(json.Unmarshal(rec.Body.Bytes(), &details))
+	assert.Equal("campaign", details.Name)
+	assert.Equal(2, details.Participants)
+	assert.Equal(5, details.Submissions)
+	assert.Equal(3, details.Verified)
+}
+
+func TestLeaderboard(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	h := newServer()
+
+	rec := get(h, "/api/v1/events/"+eventCampaign+"/leaderboard", "key-1")
+	require.Equal(http.StatusOK, rec.Code)
+	lb := api.Leaderboard{}
+	require.NoError(json.Unmarshal(rec.Body.Bytes(), &lb))
+	assert.Equal("sum", lb.Mode)
+	assert.Equal([]api.LeaderboardRow{
+		{Rank: 1, UserID: "user-2", IGN: "ign-2", Score: 30},
+		{Rank: 2, UserID: "user-1", IGN: "ign-1", Score: 10},
+	}, lb.Rows)
+
+	rec = get(h, "/api/v1/events/"+eventLeaderboard+"/leaderboard", "key-1")
+	require.Equal(http.StatusOK, rec.Code)
+	require.NoError(json.Unmarshal(rec.Body.Bytes(), &lb))
+	assert.Equal("top", lb.Mode)
+	assert.False(lb.Final)
+	// the event has no tie break set, tied users share their rank
+	assert.Equal("shared-rank", lb.TieBreak)
+	assert.Equal([]api.LeaderboardRow{
+		{Rank: 1, UserID: "user-1", IGN: "ign-1", Score: 20},
+		{Rank: 1, UserID: "user-2", IGN: "ign-2", Score: 20},
+	}, lb.Rows)
+
+	// events with categories rank users by their placements across the categories
+	rec = get(h, "/api/v1/events/"+eventCategories+"/leaderboard", "key-1")
+	require.Equal(http.StatusOK, rec.Code)
+	require.NoError(json.Unmarshal(rec.Body.Bytes(), &lb))
+	assert.Equal("combined", lb.Mode)
+	assert.Equal([]api.LeaderboardRow{
+		{Rank: 1, UserID: "user-1", IGN: "ign-1", Score: 4},
+		{Rank: 2, UserID: "user-2", IGN: "ign-2", Score: 2},
+	}, lb.Rows)
+
+	// final results come from the snapshot, not the live report
+	lb = api.Leaderboard{}
+	rec = get(h, "/api/v1/events/"+eventFinal+"/leaderboard", "key-1")
+	require.Equal(http.StatusOK, rec.Code)
+	require.NoError(json.Unmarshal(rec.Body.Bytes(), &lb))
+	assert.True(lb.Final)
+	// the mode is the one of the snapshot, not the one the event type implies
+	assert.Equal("combined", lb.Mode)
+	assert.Equal([]api.LeaderboardRow{
+		{Rank: 1, UserID: "user-1", IGN: "old-ign-1", Score: 15, VerifiedBy: []string{"mod-1"}},
+	}, lb.Rows)
+}
@@ -2,6 +2,8 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/go-redis/cache/v8"
@@ -10,12 +12,15 @@ import (
 type Memory struct {
 	C   *cache.Cache
 	TTL time.Duration
+
+	// takeMu makes Take a single step
+	takeMu sync.Mutex
 }
 
 func NewMemory(ttl time.Duration) *Memory {
-	return &Memory{cache.New(&cache.Options{
+	return &Memory{C: cache.New(&cache.Options{
 		LocalCache: cache.NewTinyLFU(1000, ttl),
-	}), ttl}
+	}), TTL: ttl}
 }
 
 func (m *Memory) Set(key string, val interface{}) error {
@@ -50,3 +55,18 @@ func (m *Memory) Drop(key string) error {
 	ctx := context.Background()
 	return m.C.Delete(ctx, key)
 }
+
+func (m *Memory) Take(key string, val interface{}) error {
+	m.takeMu.Lock()
+	defer m.takeMu.Unlock()
+
+	ctx := context.Background()
+	err := m.C.Get(ctx, key, val)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return &ErrNoRecord{}
+	}
+	if err != nil {
+		return err
+	}
+	return m.C.Delete(ctx, key)
+}
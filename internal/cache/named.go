@@ -26,3 +26,7 @@ func (c *NamedCache) Once(key string, recv interface{}, do func() (interface{},
 func (c *NamedCache) Drop(key string) error {
 	return c.c.Drop(c.prefix + ":" + key)
 }
+
+func (c *NamedCache) Take(key string, val interface{}) error {
+	return c.c.Take(c.prefix+":"+key, val)
+}
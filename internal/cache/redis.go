@@ -12,6 +12,8 @@ import (
 type Redis struct {
 	C   *cache.Cache
 	TTL time.Duration
+
+	client *redis.Client
 }
 
 func NewRedis(dsn string, ttl time.Duration) (*Redis, error) {
@@ -32,7 +34,17 @@ func NewRedis(dsn string, ttl time.Duration) (*Redis, error) {
 		LocalCache: cache.NewTinyLFU(1000, time.Minute),
 	})
 
-	return &Redis{C: rCache, TTL: ttl}, nil
+	return &Redis{C: rCache, TTL: ttl, client: rdb}, nil
+}
+
+// Client is the underlying redis client, for anything that needs more than caching
+func (r *Redis) Client() *redis.Client {
+	return r.client
+}
+
+// Close closes the connections to redis
+func (r *Redis) Close() error {
+	return r.client.Close()
 }
 
 func (r *Redis) Set(key string, val interface{}) error {
@@ -75,3 +87,17 @@ func (r *Redis) Drop(key string) error {
 	}
 	return err
 }
+
+// Take relies on GETDEL, which needs redis 6.2 or later
+func (r *Redis) Take(key string, val interface{}) error {
+	ctx := context.Background()
+	b, err := r.client.GetDel(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return &ErrNoRecord{}
+	}
+	if err != nil {
+		return err
+	}
+	r.C.DeleteFromLocalCache(key)
+	return r.C.Unmarshal(b, val)
+}
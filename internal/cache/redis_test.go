@@ -112,4 +112,16 @@ func TestRedisCache(t *testing.T) {
 	err = rCache.Get("thing1", wantThing1)
 	assert.NoError(err)
 	assert.Equal(thing1, wantThing1)
+
+	// only the first take gets the value
+	wantThing1 = &thing{}
+	err = rCache.Take("thing1", wantThing1)
+	assert.NoError(err)
+	assert.Equal(thing1, wantThing1)
+
+	err = rCache.Take("thing1", &thing{})
+	assert.True(AsErrNoRecord(err))
+
+	err = rCache.Get("thing1", &thing{})
+	assert.True(AsErrNoRecord(err))
 }
@@ -7,6 +7,9 @@ type Cache interface {
 	Get(key string, val interface{}) error
 	Once(key string, recv interface{}, do func() (interface{}, error)) error
 	Drop(key string) error
+	// Take gets the value and drops it in one go, of concurrent calls for the same key only one
+	// gets the value and the others ErrNoRecord
+	Take(key string, val interface{}) error
 }
 
 var _ error = &ErrNoRecord{}
@@ -0,0 +1,290 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	keyPrefix = "cluster:"
+	leaderKey = keyPrefix + "leader"
+
+	// leaseTTL is how long a shard or the leadership stays claimed without being renewed, a
+	// replica that died gets replaced within this time
+	leaseTTL = 15 * time.Second
+	// renewInterval is how often the claims are renewed, well within leaseTTL so a slow redis
+	// round trip doesn't lose them
+	renewInterval = 5 * time.Second
+	// jobPollTimeout is how long the leader blocks waiting on jobs before checking it still leads
+	jobPollTimeout = time.Second
+)
+
+// ErrShardLost is returned by Run when another replica took over the shard of this one, which
+// only happens when this replica failed to renew its claim in time. Both would be connected as the
+// same shard, so this replica has to go.
+var ErrShardLost = errors.New("shard claim was lost to another replica")
+
+// renew extends a claim only if it still belongs to the replica
+var renew = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// release drops a claim only if it still belongs to the replica
+var release = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// Cluster coordinates the replicas of the bot through redis. Each replica claims a shard of the
+// gateway connection, and one of them is elected leader to run the background jobs, jobs
+// published on any replica are handed to the leader.
+type Cluster struct {
+	client *redis.Client
+	logger *zap.Logger
+	id     string
+
+	shardKey string
+	leader   int32
+
+	mu       sync.Mutex
+	handlers map[string]func(payload string)
+}
+
+func New(client *redis.Client, logger *zap.Logger) *Cluster {
+	id := uuid.NewString()
+	return &Cluster{
+		client:   client,
+		logger:   logger.With(zap.String("replica-id", id)),
+		id:       id,
+		handlers: map[string]func(payload string){},
+	}
+}
+
+// ID identifies the replica within the cluster
+func (c *Cluster) ID() string {
+	return c.id
+}
+
+// AcquireShard claims the first shard no other replica holds, waiting for one to free up if they
+// are all taken. The claim is kept alive by Run.
+func (c *Cluster) AcquireShard(ctx context.Context, count int) (int, error) {
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+
+	for {
+		for i := 0; i < count; i++ {
+			key := fmt.Sprintf("%sshard:%d:%d", keyPrefix, count, i)
+			ok, err := c.client.SetNX(ctx, key, c.id, leaseTTL).Result()
+			if err != nil {
+				return 0, err
+			}
+			if ok {
+				c.shardKey = key
+				c.logger.Info("claimed shard", zap.Int("shard-id", i), zap.Int("shard-count", count))
+				return i, nil
+			}
+		}
+
+		c.logger.Info("every shard is claimed, waiting for one to free up", zap.Int("shard-count", count))
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsLeader reports whether this replica currently runs the background jobs
+func (c *Cluster) IsLeader() bool {
+	return atomic.LoadInt32(&c.leader) == 1
+}
+
+// Handle registers the handler the leader runs for jobs published on the topic, handlers have to
+// be registered before Run.
+func (c *Cluster) Handle(topic string, handler func(payload string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlers[topic] = handler
+}
+
+// Publish queues a job for the leader, jobs outlive leadership changes so nothing is lost while a
+// new leader is being elected.
+func (c *Cluster) Publish(topic, payload string) error {
+	return c.client.RPush(context.Background(), jobKey(topic), payload).Err()
+}
+
+func jobKey(topic string) string {
+	return keyPrefix + "jobs:" + topic
+}
+
+// Run keeps the shard claim alive, campaigns for leadership and runs the published jobs while
+// leading. It returns once ctx is done, after releasing every claim so another replica can take
+// over right away.
+func (c *Cluster) Run(ctx context.Context) error {
+	stopJobs := func() {}
+	var jobs sync.WaitGroup
+	defer func() {
+		stopJobs()
+		jobs.Wait()
+		c.releaseAll()
+	}()
+
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.renewShard(ctx); err != nil {
+			return err
+		}
+
+		wasLeader := c.IsLeader()
+		leading := c.campaign(ctx)
+		switch {
+		case leading && !wasLeader:
+			c.logger.Info("became leader")
+			stopJobs = c.startJobs(ctx, &jobs)
+		case !leading && wasLeader:
+			c.logger.Warn("lost leadership")
+			stopJobs()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Cluster) renewShard(ctx context.Context) error {
+	if c.shardKey == "" {
+		return nil
+	}
+
+	n, err := renew.Run(ctx, c.client, []string{c.shardKey}, c.id, leaseTTL.Milliseconds()).Int()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		// redis being briefly unavailable isn't fatal as long as the lease hasn't run out
+		c.logger.Error("could not renew shard claim", zap.Error(err))
+		return nil
+	}
+	if n == 0 {
+		// the claim expired, take it back unless someone else already did
+		ok, err := c.client.SetNX(ctx, c.shardKey, c.id, leaseTTL).Result()
+		if err != nil {
+			c.logger.Error("could not reclaim shard", zap.Error(err))
+			return nil
+		}
+		if !ok {
+			return ErrShardLost
+		}
+	}
+
+	return nil
+}
+
+// campaign renews the leadership if this replica holds it, or tries to take it otherwise
+func (c *Cluster) campaign(ctx context.Context) bool {
+	leading := false
+	defer func() {
+		if leading {
+			atomic.StoreInt32(&c.leader, 1)
+		} else {
+			atomic.StoreInt32(&c.leader, 0)
+		}
+	}()
+
+	ok, err := c.client.SetNX(ctx, leaderKey, c.id, leaseTTL).Result()
+	if err != nil {
+		c.logger.Error("could not campaign for leadership", zap.Error(err))
+		// stepping down on errors is safer than two leaders running the same jobs
+		return false
+	}
+	if ok {
+		leading = true
+		return leading
+	}
+
+	// the leadership might already be ours, in which case it needs renewing
+	n, err := renew.Run(ctx, c.client, []string{leaderKey}, c.id, leaseTTL.Milliseconds()).Int()
+	if err != nil {
+		c.logger.Error("could not renew leadership", zap.Error(err))
+		return false
+	}
+	leading = n == 1
+	return leading
+}
+
+// startJobs runs the published jobs in the background until the returned func is called
+func (c *Cluster) startJobs(ctx context.Context, wg *sync.WaitGroup) context.CancelFunc {
+	ctx, cancel := context.WithCancel(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.runJobs(ctx)
+	}()
+	return cancel
+}
+
+func (c *Cluster) runJobs(ctx context.Context) {
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.handlers))
+	handlers := make(map[string]func(string), len(c.handlers))
+	for topic, handler := range c.handlers {
+		keys = append(keys, jobKey(topic))
+		handlers[jobKey(topic)] = handler
+	}
+	c.mu.Unlock()
+
+	if len(keys) == 0 {
+		return
+	}
+
+	for ctx.Err() == nil {
+		res, err := c.client.BLPop(ctx, jobPollTimeout, keys...).Result()
+		if err != nil {
+			if err != redis.Nil && ctx.Err() == nil {
+				c.logger.Error("could not fetch jobs", zap.Error(err))
+				time.Sleep(jobPollTimeout)
+			}
+			continue
+		}
+
+		// BLPOP replies with the key the job came from followed by the job
+		handlers[res[0]](res[1])
+	}
+}
+
+func (c *Cluster) releaseAll() {
+	atomic.StoreInt32(&c.leader, 0)
+
+	// ctx is already done by now, releasing has to go through regardless
+	ctx, cancel := context.WithTimeout(context.Background(), renewInterval)
+	defer cancel()
+
+	keys := []string{leaderKey}
+	if c.shardKey != "" {
+		keys = append(keys, c.shardKey)
+	}
+	for _, key := range keys {
+		if err := release.Run(ctx, c.client, []string{key}, c.id).Err(); err != nil {
+			c.logger.Error("could not release claim", zap.Error(err), zap.String("key", key))
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package cluster_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/cluster"
+	"github.com/go-redis/redis/v8"
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var client *redis.Client
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	dockerHost := os.Getenv("DOCKER_HOST")
+	if dockerHost == "" {
+		dockerHost = "localhost"
+	}
+
+	redisContainer, err := pool.Run("redis", "6.2.3-alpine", []string{})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+
+	rOpt, err := redis.ParseURL(
+		fmt.Sprintf("redis://%s:%s", dockerHost, redisContainer.GetPort("6379/tcp")),
+	)
+	if err != nil {
+		log.Printf("Error parsing DSN: %s", err)
+		if err := pool.Purge(redisContainer); err != nil {
+			log.Fatalf("Could not purge resource: %s", err)
+		}
+		os.Exit(1)
+	}
+
+	client = redis.NewClient(rOpt)
+
+	if err := pool.Retry(func() error {
+		return client.Ping(context.Background()).Err()
+	}); err != nil {
+		log.Fatalf("Could not connect to redis docker container: %s", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(redisContainer); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+func TestAcquireShard(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	replicas := []*cluster.Cluster{
+		cluster.New(client, zap.NewNop()),
+		cluster.New(client, zap.NewNop()),
+		cluster.New(client, zap.NewNop()),
+	}
+
+	id, err := replicas[0].AcquireShard(context.Background(), 2)
+	require.NoError(err)
+	assert.Equal(0, id)
+
+	id, err = replicas[1].AcquireShard(context.Background(), 2)
+	require.NoError(err)
+	assert.Equal(1, id)
+
+	// every shard is taken, the third replica waits until its context runs out
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = replicas[2].AcquireShard(ctx, 2)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	// shutting a replica down frees its shard right away
+	runCtx, stop := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- replicas[0].Run(runCtx) }()
+	stop()
+	require.NoError(<-done)
+
+	id, err = replicas[2].AcquireShard(context.Background(), 2)
+	require.NoError(err)
+	assert.Equal(0, id)
+}
+
+func TestLeaderElection(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var mu sync.Mutex
+	received := []string{}
+
+	replicas := []*cluster.Cluster{
+		cluster.New(client, zap.NewNop()),
+		cluster.New(client, zap.NewNop()),
+	}
+	for _, v := range replicas {
+		v.Handle("test", func(payload string) {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, payload)
+		})
+	}
+
+	// jobs published before anyone leads are kept for the leader
+	require.NoError(replicas[1].Publish("test", "job-1"))
+
+	ctx0, stop0 := context.WithCancel(context.Background())
+	done0 := make(chan error)
+	go func() { done0 <- replicas[0].Run(ctx0) }()
+
+	require.Eventually(replicas[0].IsLeader, time.Second, 10*time.Millisecond)
+
+	ctx1, stop1 := context.WithCancel(context.Background())
+	defer stop1()
+	done1 := make(chan error)
+	go func() { done1 <- replicas[1].Run(ctx1) }()
+
+	require.NoError(replicas[1].Publish("test", "job-2"))
+	require.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal([]string{"job-1", "job-2"}, received)
+	assert.False(replicas[1].IsLeader())
+
+	// the leader stepping down lets the other replica take over on its next renewal
+	stop0()
+	require.NoError(<-done0)
+	assert.False(replicas[0].IsLeader())
+	require.Eventually(replicas[1].IsLeader, 10*time.Second, 100*time.Millisecond)
+
+	stop1()
+	require.NoError(<-done1)
+}
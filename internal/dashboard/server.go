@@ -0,0 +1,489 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/2785/warframe-assistant/internal/cache"
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/metrics"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/webhook"
+	"github.com/bwmarrin/discordgo"
+	"github.com/thoas/go-funk"
+	"go.uber.org/zap"
+)
+
+const (
+	basePath   = "/dashboard/"
+	cookieName = "wfa_dashboard"
+
+	// verificationAction is the role_lookup action moderators need, same as the verify buttons
+	verificationAction = "verification"
+
+	// pageSize is how many pending submissions are shown at once
+	pageSize = 60
+)
+
+//go:embed templates/*.html static/*
+var files embed.FS
+
+var templates = template.Must(template.ParseFS(files, "templates/*.html"))
+
+// Server is a small web UI for moderators to go through pending submissions in bulk. Moderators
+// log in through a one time link handed out by the bot, and have to hold the verification role of
+// their guild for as long as they use it.
+type Server struct {
+	Session *discordgo.Session
+	Meta    meta.Service
+	Scores  scores.ScoresService
+	// Cache holds login links and sessions, both expire with the cache TTL
+	Cache cache.Cache
+	// BaseURL is where the dashboard is reachable from, without the /dashboard path
+	BaseURL string
+	Logger  *zap.Logger
+	// OnChange is called with the event ID whenever the scores of an event changed
+	OnChange func(eid string)
+	// Webhooks gets told about verified, amended and rejected submissions, nil disables them
+	Webhooks *webhook.Dispatcher
+	// Notifier tells submitters what was done with their submissions, nil disables it
+	Notifier Notifier
+}
+
+// Notifier sends the user who made a submission a DM about what a moderator did with it, if they
+// opted in to those
+type Notifier interface {
+	NotifySubmitter(s *discordgo.Session, uid, sid, eventName, outcome string)
+}
+
+type session struct {
+	UID  string
+	GID  string
+	CSRF string
+}
+
+// LoginURL hands out a one time link that logs the user into the dashboard for the guild.
+func (s *Server) LoginURL(uid, gid string) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.Cache.Set("login:"+token, &session{UID: uid, GID: gid})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(s.BaseURL, "/") + basePath + "login?token=" + token, nil
+}
+
+// Handler serves everything under `/dashboard/`.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(basePath+"static/", http.StripPrefix(basePath, http.FileServer(http.FS(files))))
+	mux.HandleFunc(basePath+"login", s.handleLogin)
+	mux.Handle(basePath, s.authenticate(http.HandlerFunc(s.route)))
+	return mux
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	login := &session{}
+	if token == "" {
+		s.renderError(w, http.StatusUnauthorized, "This login link is invalid or has expired, ask the bot for a new one with /dashboard.")
+		return
+	}
+	// links are single use, of two requests racing with the same link only one gets the session
+	if err := s.Cache.Take("login:"+token, login); err != nil {
+		if !cache.AsErrNoRecord(err) {
+			s.Logger.Error("could not take login token", zap.Error(err))
+		}
+		s.renderError(w, http.StatusUnauthorized, "This login link is invalid or has expired, ask the bot for a new one with /dashboard.")
+		return
+	}
+
+	sid, err := randomToken()
+	if err != nil {
+		s.Logger.Error("could not generate session ID", zap.Error(err))
+		s.renderError(w, http.StatusInternalServerError, "Something went wrong, please try again.")
+		return
+	}
+	login.CSRF, err = randomToken()
+	if err != nil {
+		s.Logger.Error("could not generate CSRF token", zap.Error(err))
+		s.renderError(w, http.StatusInternalServerError, "Something went wrong, please try again.")
+		return
+	}
+
+	if err := s.Cache.Set("session:"+sid, login); err != nil {
+		s.Logger.Error("could not save session", zap.Error(err))
+		s.renderError(w, http.StatusInternalServerError, "Something went wrong, please try again.")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    sid,
+		Path:     basePath,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(s.BaseURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, basePath, http.StatusSeeOther)
+}
+
+type sessionKey struct{}
+
+func withSession(ctx context.Context, sess *session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sess)
+}
+
+func sessionFrom(ctx context.Context) *session {
+	sess, _ := ctx.Value(sessionKey{}).(*session)
+	return sess
+}
+
+// authenticate loads the session from the cookie, refreshes its expiry and makes sure the user
+// still holds the verification role of the guild.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(cookieName)
+		sess := &session{}
+		if err != nil || s.Cache.Get("session:"+cookie.Value, sess) != nil {
+			s.renderError(w, http.StatusUnauthorized, "You are not logged in, use /dashboard in your server to get a login link.")
+			return
+		}
+
+		if err := s.Cache.Set("session:"+cookie.Value, sess); err != nil {
+			s.Logger.Warn("could not refresh session", zap.Error(err))
+		}
+
+		logger := s.Logger.With(zap.String("uid", sess.UID), zap.String("gid", sess.GID))
+
+		rid, err := s.Meta.GetRoleRequirementForGuild(verificationAction, sess.GID)
+		if err != nil {
+			logger.Error("could not fetch role requirement", zap.Error(err))
+			s.renderError(w, http.StatusInternalServerError, "Could not check your permissions, please try again.")
+			return
+		}
+
+		if rid != "" {
+			member, err := s.Session.GuildMember(sess.GID, sess.UID)
+			if err != nil {
+				logger.Error("could not fetch member", zap.Error(err))
+				s.renderError(w, http.StatusInternalServerError, "Could not check your permissions, please try again.")
+				return
+			}
+			if !funk.ContainsString(member.Roles, rid) {
+				s.renderError(w, http.StatusForbidden, "You no longer have the role needed to verify submissions.")
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(withSession(r.Context(), sess)))
+	})
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFrom(r.Context())
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, basePath), "/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "" && r.Method == http.MethodGet:
+		s.handleEvents(w, sess)
+	case len(parts) == 2 && parts[0] == "events" && r.Method == http.MethodGet:
+		s.handleEvent(w, r, sess, parts[1])
+	case len(parts) == 2 && parts[0] == "events" && r.Method == http.MethodPost:
+		s.handleReview(w, r, sess, parts[1])
+	default:
+		s.renderError(w, http.StatusNotFound, "Page not found.")
+	}
+}
+
+type eventSummary struct {
+	*meta.Event
+	Pending int
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, sess *session) {
+	events, err := s.Meta.ListActiveEventsForGuild(sess.GID)
+	if err != nil {
+		s.Logger.Error("could not list events", zap.Error(err))
+		s.renderError(w, http.StatusInternalServerError, "Could not list events, please try again.")
+		return
+	}
+
+	summaries := make([]eventSummary, len(events))
+	for i, v := range events {
+		total, verified, err := s.Scores.VerificationStatus(v.ID)
+		if err != nil {
+			s.Logger.Error("could not get verification status", zap.Error(err), zap.String("eid", v.ID))
+			s.renderError(w, http.StatusInternalServerError, "Could not list events, please try again.")
+			return
+		}
+		summaries[i] = eventSummary{v, total - verified}
+	}
+
+	s.render(w, http.StatusOK, "events.html", map[string]interface{}{"Events": summaries})
+}
+
+func (s *Server) handleEvent(w http.ResponseWriter, r *http.Request, sess *session, eid string) {
+	event, ok := s.mustGetEvent(w, sess, eid)
+	if !ok {
+		return
+	}
+
+	records, err := s.Scores.ListUnverifiedForEvent(eid, pageSize)
+	if err != nil {
+		s.Logger.Error("could not list pending submissions", zap.Error(err), zap.String("eid", eid))
+		s.renderError(w, http.StatusInternalServerError, "Could not list submissions, please try again.")
+		return
+	}
+
+	total, verified, err := s.Scores.VerificationStatus(eid)
+	if err != nil {
+		s.Logger.Error("could not get verification status", zap.Error(err), zap.String("eid", eid))
+		s.renderError(w, http.StatusInternalServerError, "Could not list submissions, please try again.")
+		return
+	}
+
+	s.render(w, http.StatusOK, "event.html", map[string]interface{}{
+		"Event":       event,
+		"Submissions": records,
+		"Pending":     total - verified,
+		"Message":     r.URL.Query().Get("msg"),
+		"CSRF":        sess.CSRF,
+	})
+}
+
+// handleReview applies a bulk verify or reject to the selected submissions, or amends the score of
+// a single submission when one of the inline save buttons was used.
+func (s *Server) handleReview(w http.ResponseWriter, r *http.Request, sess *session, eid string) {
+	event, ok := s.mustGetEvent(w, sess, eid)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		s.renderError(w, http.StatusBadRequest, "Could not read the form.")
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(r.PostForm.Get("csrf")), []byte(sess.CSRF)) != 1 {
+		s.renderError(w, http.StatusForbidden, "The form has expired, please reload the page and try again.")
+		return
+	}
+
+	// only act on submissions that are actually pending in this event
+	records, err := s.Scores.ListUnverifiedForEvent(eid, pageSize*10)
+	if err != nil {
+		s.Logger.Error("could not list pending submissions", zap.Error(err), zap.String("eid", eid))
+		s.renderError(w, http.StatusInternalServerError, "Could not list submissions, please try again.")
+		return
+	}
+	pending := make(map[string]scores.ScoreRecord, len(records))
+	for _, v := range records {
+		pending[v.ID] = v
+	}
+
+	logger := s.Logger.With(zap.String("uid", sess.UID), zap.String("eid", eid))
+
+	var done, failed int
+	var msg string
+
+	switch {
+	case r.PostForm.Get("amend") != "":
+		sid := r.PostForm.Get("amend")
+		record, ok := pending[sid]
+		if !ok {
+			failed++
+			break
+		}
+		if err := s.amend(sess, event, record, r.PostForm.Get("score-"+sid)); err != nil {
+			logger.Error("could not amend score", zap.Error(err), zap.String("sid", sid))
+			failed++
+			break
+		}
+		done++
+		msg = "Amended and verified %d submission(s)"
+
+	case r.PostForm.Get("action") == "verify":
+		for _, sid := range r.PostForm["sid"] {
+			record, ok := pending[sid]
+			if !ok {
+				failed++
+				continue
+			}
+
+			var err error
+			if score := r.PostForm.Get("score-" + sid); score != "" && score != strconv.Itoa(record.RawScore) {
+				err = s.amend(sess, event, record, score)
+			} else {
+				err = s.Scores.Verify(sid, sess.UID)
+				if err == nil {
+					metrics.Verifications.WithLabelValues(metrics.VerificationVerify).Inc()
+					s.dispatchWebhook(sess.GID, eid, webhook.SubmissionVerified, record, "")
+					s.recordHistory(sess, record, scores.HistoryVerified, record.RawScore, "")
+					s.notify(record, event, fmt.Sprintf("was verified by a moderator with a score of %d", record.Score))
+				}
+			}
+			if err != nil {
+				logger.Error("could not verify score", zap.Error(err), zap.String("sid", sid))
+				failed++
+				continue
+			}
+			done++
+		}
+		msg = "Verified %d submission(s)"
+
+	case r.PostForm.Get("action") == "reject":
+		reason := strings.TrimSpace(r.PostForm.Get("reason"))
+		for _, sid := range r.PostForm["sid"] {
+			record, ok := pending[sid]
+			if !ok {
+				failed++
+				continue
+			}
+			if err := s.Scores.DeleteScore(sid); err != nil {
+				logger.Error("could not remove score", zap.Error(err), zap.String("sid", sid))
+				failed++
+				continue
+			}
+			metrics.Verifications.WithLabelValues(metrics.VerificationRemove).Inc()
+			s.recordHistory(sess, record, scores.HistoryRemoved, record.RawScore, reason)
+			s.notifyRejected(record, event, reason)
+			s.dispatchWebhook(sess.GID, eid, webhook.SubmissionRejected, record, reason)
+			done++
+		}
+		msg = "Rejected %d submission(s)"
+
+	default:
+		s.renderError(w, http.StatusBadRequest, "Unknown action.")
+		return
+	}
+
+	if done > 0 && s.OnChange != nil {
+		s.OnChange(eid)
+	}
+
+	msg = fmt.Sprintf(msg, done)
+	if failed > 0 {
+		msg += fmt.Sprintf(", %d could not be processed as they were already handled or something went wrong", failed)
+	}
+
+	http.Redirect(w, r, basePath+"events/"+eid+"?msg="+url.QueryEscape(msg), http.StatusSeeOther)
+}
+
+func (s *Server) amend(sess *session, event *meta.Event, record scores.ScoreRecord, scoreStr string) error {
+	score, err := strconv.Atoi(strings.TrimSpace(scoreStr))
+	if err != nil {
+		return fmt.Errorf("invalid score %q: %w", scoreStr, err)
+	}
+	err = s.Scores.UpdateScoreAndVerify(record.ID, score, sess.UID)
+	if err != nil {
+		return err
+	}
+	metrics.Verifications.WithLabelValues(metrics.VerificationAmend).Inc()
+	s.recordHistory(sess, record, scores.HistoryAmended, score, "")
+	s.notify(record, event, fmt.Sprintf(
+		"was amended by a moderator from %d to %d, contest it with `/events appeal` if you disagree",
+		record.RawScore,
+		score,
+	))
+	record.RawScore = score
+	record.Score = scores.Apply(score, record.Modifiers)
+	s.dispatchWebhook(sess.GID, event.ID, webhook.SubmissionAmended, record, "")
+	return nil
+}
+
+// recordHistory adds what the moderator did to the history of the submission, record is the
+// submission as it was before and score its raw score after
+func (s *Server) recordHistory(
+	sess *session,
+	record scores.ScoreRecord,
+	action scores.HistoryAction,
+	score int,
+	detail string,
+) {
+	err := s.Scores.RecordHistory(scores.HistoryEntry{
+		SID:           record.ID,
+		Action:        action,
+		Actor:         sess.UID,
+		PreviousScore: record.RawScore,
+		Score:         score,
+		Detail:        detail,
+	})
+	if err != nil {
+		s.Logger.Warn("could not record submission history", zap.Error(err), zap.String("sid", record.ID))
+	}
+}
+
+func (s *Server) dispatchWebhook(gid, eid, eventType string, record scores.ScoreRecord, reason string) {
+	s.Webhooks.Dispatch(gid, eventType, webhook.Submission{
+		ID:        record.ID,
+		EventID:   eid,
+		Category:  record.Category,
+		UserID:    record.UID,
+		IGN:       record.IGN,
+		Score:     record.Score,
+		RawScore:  record.RawScore,
+		Modifiers: record.Modifiers.Names(),
+		Proof:     record.Proof,
+		Reason:    reason,
+	})
+}
+
+// notifyRejected lets the submitter know their submission was thrown out and why
+func (s *Server) notifyRejected(record scores.ScoreRecord, event *meta.Event, reason string) {
+	outcome := "was rejected by a moderator"
+	if reason != "" {
+		outcome += ", reason: " + reason
+	}
+	s.notify(record, event, outcome+", contest it with `/events appeal` if you disagree")
+}
+
+// notify goes through the notifications of the bot, so submitters only get DMs they opted in to
+func (s *Server) notify(record scores.ScoreRecord, event *meta.Event, outcome string) {
+	if s.Notifier == nil {
+		return
+	}
+	s.Notifier.NotifySubmitter(s.Session, record.UID, record.ID, event.Name, outcome)
+}
+
+func (s *Server) mustGetEvent(w http.ResponseWriter, sess *session, eid string) (*meta.Event, bool) {
+	event, err := s.Meta.GetEvent(eid)
+	if err != nil || event.GID != sess.GID {
+		s.renderError(w, http.StatusNotFound, "Event not found.")
+		return nil, false
+	}
+	return event, true
+}
+
+func (s *Server) render(w http.ResponseWriter, status int, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := templates.ExecuteTemplate(w, name, data); err != nil {
+		s.Logger.Error("could not render template", zap.Error(err), zap.String("template", name))
+	}
+}
+
+func (s *Server) renderError(w http.ResponseWriter, status int, msg string) {
+	s.render(w, status, "error.html", map[string]interface{}{"Message": msg})
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,235 @@
+package dashboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/cache"
+	"github.com/2785/warframe-assistant/internal/dashboard"
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const eid = "event-1"
+
+// fakeMeta only implements what the dashboard uses, anything else panics on the nil embedded service
+type fakeMeta struct {
+	meta.Service
+}
+
+// no role requirement so the tests never have to look up guild members
+func (f *fakeMeta) GetRoleRequirementForGuild(action, gid string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeMeta) GetEvent(id string) (*meta.Event, error) {
+	return &meta.Event{ID: id, GID: "guild-1", Name: "Test Event"}, nil
+}
+
+type fakeScores struct {
+	scores.ScoresService
+	pending   []scores.ScoreRecord
+	verified  map[string]int
+	verifiers map[string]string
+	history   []scores.HistoryEntry
+	removed   []string
+}
+
+func (f *fakeScores) ListUnverifiedForEvent(eid string, limit uint64) ([]scores.ScoreRecord, error) {
+	return f.pending, nil
+}
+
+func (f *fakeScores) VerificationStatus(eid string) (int, int, error) {
+	return len(f.pending) + len(f.verified), len(f.verified), nil
+}
+
+func (f *fakeScores) Verify(sid, verifier string) error {
+	for _, v := range f.pending {
+		if v.ID == sid {
+			f.verified[sid] = v.Score
+			f.verifiers[sid] = verifier
+		}
+	}
+	return nil
+}
+
+func (f *fakeScores) UpdateScoreAndVerify(sid string, score int, verifier string) error {
+	f.verified[sid] = score
+	f.verifiers[sid] = verifier
+	return nil
+}
+
+func (f *fakeScores) RecordHistory(entry scores.HistoryEntry) error {
+	f.history = append(f.history, entry)
+	return nil
+}
+
+func (f *fakeScores) DeleteScore(sid string) error {
+	f.removed = append(f.removed, sid)
+	return nil
+}
+
+// fakeNotifier keeps the DMs instead of sending them, opting in is up to the bot
+type fakeNotifier struct {
+	sent []string
+}
+
+func (f *fakeNotifier) NotifySubmitter(s *discordgo.Session, uid, sid, eventName, outcome string) {
+	f.sent = append(f.sent, uid+": "+sid+" "+outcome)
+}
+
+func TestReview(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	sc := &fakeScores{
+		pending: []scores.ScoreRecord{
+			{ID: "sub-1", UID: "user-1", IGN: "ign-1", Score: 10, RawScore: 10, Proof: "https://example.com/1.png"},
+			{ID: "sub-2", UID: "user-2", IGN: "ign-2", Score: 20, RawScore: 20, Proof: "https://example.com/2.png"},
+			{
+				ID: "sub-3", UID: "user-3", IGN: "ign-3", Score: 45, RawScore: 30, Proof: "https://example.com/3.png",
+				Modifiers: scores.AppliedModifiers{{Name: "steel-path", Kind: scores.ModifierMultiplier, Value: 1.5}},
+			},
+		},
+		verified:  map[string]int{},
+		verifiers: map[string]string{},
+	}
+
+	changed := []string{}
+	notifier := &fakeNotifier{}
+	s := &dashboard.Server{
+		Meta:     &fakeMeta{},
+		Scores:   sc,
+		Cache:    cache.NewMemory(time.Minute),
+		BaseURL:  "http://localhost:8080",
+		Logger:   zap.NewNop(),
+		OnChange: func(eid string) { changed = append(changed, eid) },
+		Notifier: notifier,
+	}
+	h := s.Handler()
+
+	// not logged in
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard/", nil))
+	assert.Equal(http.StatusUnauthorized, rec.Code)
+
+	link, err := s.LoginURL("mod-1", "guild-1")
+	require.NoError(err)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, link, nil))
+	require.Equal(http.StatusSeeOther, rec.Code)
+	cookies := rec.Result().Cookies()
+	require.Len(cookies, 1)
+
+	// login links only work once
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, link, nil))
+	assert.Equal(http.StatusUnauthorized, rec.Code)
+
+	// even when used at the same time
+	racedLink, err := s.LoginURL("mod-1", "guild-1")
+	require.NoError(err)
+	codes := make(chan int, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(codes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, racedLink, nil))
+			codes <- rec.Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+	loggedIn := 0
+	for code := range codes {
+		if code == http.StatusSeeOther {
+			loggedIn++
+		}
+	}
+	assert.Equal(1, loggedIn)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.AddCookie(cookies[0])
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec = get("/dashboard/events/" + eid)
+	require.Equal(http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(body, "https://example.com/2.png")
+	assert.Contains(body, "3 submission(s) pending")
+	// the score is amended before modifiers, which are shown along with it
+	assert.Contains(body, `name="score-sub-3" value="30"`)
+	assert.Contains(body, "30 × 1.5 (steel-path) = 45")
+
+	csrf := body[strings.Index(body, `name="csrf" value="`)+len(`name="csrf" value="`):]
+	csrf = csrf[:strings.Index(csrf, `"`)]
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/dashboard/events/"+eid, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookies[0])
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// forms without the CSRF token are refused
+	rec = post(url.Values{"action": {"verify"}, "sid": {"sub-1"}})
+	assert.Equal(http.StatusForbidden, rec.Code)
+	assert.Empty(sc.verified)
+
+	// bulk verify, with one score amended inline and one submission that is not pending
+	rec = post(url.Values{
+		"csrf":        {csrf},
+		"action":      {"verify"},
+		"sid":         {"sub-1", "sub-2", "sub-9"},
+		"score-sub-1": {"10"},
+		"score-sub-2": {"25"},
+	})
+	assert.Equal(http.StatusSeeOther, rec.Code)
+	assert.Contains(rec.Header().Get("Location"), url.QueryEscape("Verified 2 submission(s), 1 could not"))
+	assert.Equal(map[string]int{"sub-1": 10, "sub-2": 25}, sc.verified)
+	assert.Equal(map[string]string{"sub-1": "mod-1", "sub-2": "mod-1"}, sc.verifiers)
+	assert.Equal([]string{eid}, changed)
+
+	// amending a single submission
+	rec = post(url.Values{"csrf": {csrf}, "amend": {"sub-3"}, "score-sub-3": {"35"}})
+	assert.Equal(http.StatusSeeOther, rec.Code)
+	assert.Equal(35, sc.verified["sub-3"])
+
+	// amendments can be appealed, so they go in the history along with the score before
+	assert.Equal([]scores.HistoryEntry{
+		{SID: "sub-1", Action: scores.HistoryVerified, Actor: "mod-1", PreviousScore: 10, Score: 10},
+		{SID: "sub-2", Action: scores.HistoryAmended, Actor: "mod-1", PreviousScore: 20, Score: 25},
+		{SID: "sub-3", Action: scores.HistoryAmended, Actor: "mod-1", PreviousScore: 30, Score: 35},
+	}, sc.history)
+
+	// rejecting removes the submission
+	rec = post(url.Values{"csrf": {csrf}, "action": {"reject"}, "sid": {"sub-2"}, "reason": {"wrong mission"}})
+	assert.Equal(http.StatusSeeOther, rec.Code)
+	assert.Equal([]string{"sub-2"}, sc.removed)
+
+	// submitters are told through the notifications of the bot, which only DMs those who opted in
+	assert.Equal([]string{
+		"user-1: sub-1 was verified by a moderator with a score of 10",
+		"user-2: sub-2 was amended by a moderator from 20 to 25, contest it with `/events appeal` if you disagree",
+		"user-3: sub-3 was amended by a moderator from 30 to 35, contest it with `/events appeal` if you disagree",
+		"user-2: sub-2 was rejected by a moderator, reason: wrong mission, contest it with `/events appeal` if you disagree",
+	}, notifier.sent)
+}
@@ -0,0 +1,310 @@
+package dates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Style is how discord renders a timestamp tag, it's rendered in the timezone of whoever reads it
+type Style string
+
+const (
+	ShortTime     Style = "t"
+	LongTime      Style = "T"
+	ShortDate     Style = "d"
+	LongDate      Style = "D"
+	ShortDateTime Style = "f"
+	LongDateTime  Style = "F"
+	Relative      Style = "R"
+)
+
+// Timestamp renders t as a discord timestamp tag
+func Timestamp(t time.Time, style Style) string {
+	return fmt.Sprintf("<t:%d:%s>", t.Unix(), style)
+}
+
+// Display renders t as a full date followed by how long ago or until it is, e.g.
+// "Friday, July 1, 2022 8:00 PM (in 3 days)"
+func Display(t time.Time) string {
+	return fmt.Sprintf("%s (%s)", Timestamp(t, LongDateTime), Timestamp(t, Relative))
+}
+
+// Examples of inputs Parse understands, meant to be shown to users
+const Examples = "`2022-07-01 20:00`, `in 3d`, `tomorrow 8pm` or `next friday 20:00`"
+
+// ErrInvalidDate is returned when the input can't be read as a date, the message is meant for
+// the user.
+type ErrInvalidDate struct{ M string }
+
+func (e *ErrInvalidDate) Error() string {
+	return e.M
+}
+
+func AsErrInvalidDate(e error) (*ErrInvalidDate, bool) {
+	iv := &ErrInvalidDate{}
+	return iv, errors.As(e, &iv)
+}
+
+// absoluteLayouts are tried in order, layouts without a zone are read in the guild timezone
+var absoluteLayouts = []struct {
+	layout  string
+	hasZone bool
+}{
+	{time.RFC3339, true},
+	{"2006-01-02T15:04Z07:00", true},
+	{"2006-01-02 15:04:05Z07:00", true},
+	{"2006-01-02 15:04Z07:00", true},
+	{"2006-01-02T15:04:05", false},
+	{"2006-01-02T15:04", false},
+	{"2006-01-02 15:04:05", false},
+	{"2006-01-02 15:04", false},
+	{"2006-01-02", false},
+	{"Jan 2, 2006 at 3:04pm", false},
+	{"Jan 2, 2006 at 3:04 pm", false},
+	{"Jan 2, 2006 15:04", false},
+	{"Jan 2, 2006", false},
+}
+
+var (
+	discordTagRe = regexp.MustCompile(`^<t:(-?\d+)(?::[tTdDfFR])?>$`)
+	// legacyZoneRe matches the trailing abbreviation of the old `Jan 2, 2006 at 3:04pm (MST)` format
+	legacyZoneRe = regexp.MustCompile(`^(.*?)\s*\(([A-Za-z]+)\)$`)
+	inRe         = regexp.MustCompile(`^in\s+(.+)$`)
+	amountRe     = regexp.MustCompile(`(\d+)\s*([a-z]+)`)
+	dayRe        = regexp.MustCompile(`^(?:(today|tomorrow)|(?:next\s+)?([a-z]+))(?:\s+(?:at\s+)?(.+))?$`)
+	clockRe      = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+var units = map[string]time.Duration{
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// Parse reads a date given by a user, relative to now. It understands discord timestamp tags,
+// ISO 8601 dates, relative inputs such as "in 3d" or "next friday 20:00", and the old
+// `Jan 2, 2006 at 3:04pm` format. Inputs without an explicit UTC offset are read in loc.
+func Parse(input string, now time.Time, loc *time.Location) (time.Time, error) {
+	input = strings.TrimSpace(input)
+	now = now.In(loc)
+
+	if match := discordTagRe.FindStringSubmatch(input); match != nil {
+		sec, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return time.Time{}, invalid(input)
+		}
+		return time.Unix(sec, 0).In(loc), nil
+	}
+
+	if t, ok, err := parseAbsolute(input, loc); ok || err != nil {
+		return t, err
+	}
+
+	lower := strings.ToLower(input)
+	if lower == "now" {
+		return now, nil
+	}
+
+	if match := inRe.FindStringSubmatch(lower); match != nil {
+		d, ok := parseDuration(match[1])
+		if !ok {
+			return time.Time{}, invalid(input)
+		}
+		return now.Add(d), nil
+	}
+
+	if t, ok := parseDay(lower, now); ok {
+		return t, nil
+	}
+
+	return time.Time{}, invalid(input)
+}
+
+func invalid(input string) error {
+	return &ErrInvalidDate{fmt.Sprintf("Could not read '%s' as a date, try e.g. %s", input, Examples)}
+}
+
+func parseAbsolute(input string, loc *time.Location) (time.Time, bool, error) {
+	zone := ""
+	if match := legacyZoneRe.FindStringSubmatch(input); match != nil {
+		input, zone = match[1], strings.ToUpper(match[2])
+	}
+
+	for _, v := range absoluteLayouts {
+		var t time.Time
+		var err error
+		if v.hasZone {
+			t, err = time.Parse(v.layout, input)
+		} else {
+			t, err = time.ParseInLocation(v.layout, input, loc)
+		}
+		if err != nil {
+			continue
+		}
+
+		if zone == "" {
+			return t, true, nil
+		}
+		if v.hasZone {
+			return time.Time{}, false, invalid(input)
+		}
+		return checkZone(t, zone, loc)
+	}
+
+	return time.Time{}, false, nil
+}
+
+// checkZone makes sure a timezone abbreviation matches the guild timezone, abbreviations are
+// ambiguous (CST alone is used by three different zones) so they are never guessed.
+func checkZone(t time.Time, zone string, loc *time.Location) (time.Time, bool, error) {
+	if zone == "UTC" || zone == "GMT" {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC), true, nil
+	}
+
+	if name, _ := t.Zone(); name == zone {
+		return t, true, nil
+	}
+
+	return time.Time{}, false, &ErrInvalidDate{fmt.Sprintf(
+		"Timezone abbreviations such as %s are ambiguous and only work if they match the timezone "+
+			"of the server (%s), leave it out or give an offset instead, e.g. `2022-07-01T20:00-05:00`",
+		zone,
+		loc,
+	)}
+}
+
+// ParseDuration reads durations given by a user, such as "3d", "2 hours" or "1w 2d 3h"
+func ParseDuration(input string) (time.Duration, error) {
+	d, ok := parseDuration(strings.ToLower(strings.TrimSpace(input)))
+	if !ok || d <= 0 {
+		return 0, &ErrInvalidDate{fmt.Sprintf("Could not read '%s' as a duration, try e.g. `2d` or `1w 12h`", input)}
+	}
+	return d, nil
+}
+
+// FormatDuration renders d the way ParseDuration reads it, e.g. "1w 2d 3h", anything below a
+// minute is left out
+func FormatDuration(d time.Duration) string {
+	parts := []string{}
+	for _, v := range []struct {
+		unit   time.Duration
+		suffix string
+	}{
+		{7 * 24 * time.Hour, "w"},
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+	} {
+		if n := d / v.unit; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, v.suffix))
+			d -= n * v.unit
+		}
+	}
+	if len(parts) == 0 {
+		return "0m"
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseDuration reads durations such as "3d", "2 hours" or "1w 2d 3h"
+func parseDuration(s string) (time.Duration, bool) {
+	matches := amountRe.FindAllStringSubmatch(s, -1)
+	if matches == nil || strings.TrimSpace(amountRe.ReplaceAllString(s, "")) != "" {
+		return 0, false
+	}
+
+	var d time.Duration
+	for _, v := range matches {
+		n, err := strconv.Atoi(v[1])
+		if err != nil {
+			return 0, false
+		}
+		unit, ok := units[v[2]]
+		if !ok {
+			return 0, false
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, true
+}
+
+// parseDay reads "today", "tomorrow" or a weekday, optionally followed by a time of the day.
+// Weekdays always mean the next one, a week from now if it is that day today.
+func parseDay(s string, now time.Time) (time.Time, bool) {
+	match := dayRe.FindStringSubmatch(s)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case match[1] == "today":
+	case match[1] == "tomorrow":
+		day = day.AddDate(0, 0, 1)
+	default:
+		wd, ok := parseWeekday(match[2])
+		if !ok {
+			return time.Time{}, false
+		}
+		days := (int(wd) - int(now.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		day = day.AddDate(0, 0, days)
+	}
+
+	if match[3] == "" {
+		return day, true
+	}
+
+	hour, min, ok := parseClock(match[3])
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, min, 0, 0, day.Location()), true
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock reads times of the day such as "20:00", "8pm" or "8:30 am"
+func parseClock(s string) (int, int, bool) {
+	match := clockRe.FindStringSubmatch(s)
+	if match == nil {
+		return 0, 0, false
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	min := 0
+	if match[2] != "" {
+		min, _ = strconv.Atoi(match[2])
+	}
+
+	switch match[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if match[3] == "pm" {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || min > 59 {
+		return 0, 0, false
+	}
+	return hour, min, true
+}
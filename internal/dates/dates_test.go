@@ -0,0 +1,91 @@
+package dates_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/dates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// a Wednesday
+	now := time.Date(2022, time.June, 29, 15, 30, 0, 0, berlin)
+
+	cases := []struct {
+		input string
+		want  time.Time
+	}{
+		{"<t:1656698400>", time.Unix(1656698400, 0)},
+		{"<t:1656698400:R>", time.Unix(1656698400, 0)},
+		{"2022-07-01T20:00:00Z", time.Date(2022, time.July, 1, 20, 0, 0, 0, time.UTC)},
+		{"2022-07-01T20:00-05:00", time.Date(2022, time.July, 1, 20, 0, 0, 0, time.FixedZone("", -5*3600))},
+		{"2022-07-01 20:00", time.Date(2022, time.July, 1, 20, 0, 0, 0, berlin)},
+		{"2022-07-01", time.Date(2022, time.July, 1, 0, 0, 0, 0, berlin)},
+		{"Jul 1, 2022 at 8:00pm", time.Date(2022, time.July, 1, 20, 0, 0, 0, berlin)},
+		{"Jul 1, 2022 at 8:00pm (CEST)", time.Date(2022, time.July, 1, 20, 0, 0, 0, berlin)},
+		{"Jul 1, 2022 at 8:00pm (UTC)", time.Date(2022, time.July, 1, 20, 0, 0, 0, time.UTC)},
+		{"now", now},
+		{"in 3d", now.Add(3 * 24 * time.Hour)},
+		{"in 1w 2d 3h", now.Add((9*24 + 3) * time.Hour)},
+		{"in 90 minutes", now.Add(90 * time.Minute)},
+		{"today 20:00", time.Date(2022, time.June, 29, 20, 0, 0, 0, berlin)},
+		{"tomorrow at 8pm", time.Date(2022, time.June, 30, 20, 0, 0, 0, berlin)},
+		{"tomorrow", time.Date(2022, time.June, 30, 0, 0, 0, 0, berlin)},
+		{"next friday 20:00", time.Date(2022, time.July, 1, 20, 0, 0, 0, berlin)},
+		{"Friday 12am", time.Date(2022, time.July, 1, 0, 0, 0, 0, berlin)},
+		// it's wednesday already, so it's the one next week
+		{"next wed 9:30am", time.Date(2022, time.July, 6, 9, 30, 0, 0, berlin)},
+	}
+
+	for _, v := range cases {
+		got, err := dates.Parse(v.input, now, berlin)
+		if assert.NoError(t, err, v.input) {
+			assert.True(t, v.want.Equal(got), "%s: want %s, got %s", v.input, v.want, got)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	now := time.Date(2022, time.June, 29, 15, 30, 0, 0, time.UTC)
+
+	for _, v := range []string{
+		"",
+		"soon",
+		"in a while",
+		"in 3 fortnights",
+		"next someday",
+		"tomorrow 25:00",
+		"friday 13pm",
+		"2022-13-01",
+		// abbreviations are ambiguous, only the one of the guild timezone is accepted
+		"Jul 1, 2022 at 8:00pm (CST)",
+	} {
+		_, err := dates.Parse(v, now, time.UTC)
+		_, ok := dates.AsErrInvalidDate(err)
+		assert.True(t, ok, v)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	d := time.Date(2022, time.July, 1, 20, 0, 0, 0, time.UTC)
+	assert.Equal(t, "<t:1656705600:R>", dates.Timestamp(d, dates.Relative))
+	assert.Equal(t, "<t:1656705600:F> (<t:1656705600:R>)", dates.Display(d))
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := dates.ParseDuration("1w 2d 3h")
+	assert.NoError(t, err)
+	assert.Equal(t, (9*24+3)*time.Hour, d)
+
+	assert.Equal(t, "1w 2d 3h", dates.FormatDuration(d))
+	assert.Equal(t, "1h 30m", dates.FormatDuration(90*time.Minute))
+
+	_, err = dates.ParseDuration("a while")
+	_, ok := dates.AsErrInvalidDate(err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,215 @@
+package dates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduleExamples are examples of recurrence schedules ParseSchedule understands, meant to be
+// shown to users
+const ScheduleExamples = "`weekly sunday 18:00`, `monthly 1 8pm` or `cron 0 18 * * 0`"
+
+// scheduleHorizon is how many years ahead Next looks for an occurrence before giving up
+const scheduleHorizon = 8
+
+var (
+	weeklyRe  = regexp.MustCompile(`^weekly\s+([a-z]+)\s+(?:at\s+)?(.+)$`)
+	monthlyRe = regexp.MustCompile(`^monthly\s+(\d{1,2})(?:st|nd|rd|th)?\s+(?:at\s+)?(.+)$`)
+	cronRe    = regexp.MustCompile(`^cron\s+(.+)$`)
+)
+
+// Schedule is when something recurs, it's kept as the five fields of a cron expression: minute,
+// hour, day of the month, month and day of the week.
+type Schedule struct {
+	spec string
+
+	minute, hour, dom, month, dow uint64
+	// anyDom and anyDow are set when the field was given as `*`, if only one of the two is
+	// restricted only that one counts, if both are either of them has to match like in cron
+	anyDom, anyDow bool
+}
+
+// ParseSchedule reads a recurrence schedule given by a user, either `weekly <weekday> <time>`,
+// `monthly <day> <time>` or `cron <expression>` with a standard five field cron expression.
+// Monthly schedules on days some months don't have skip those months.
+func ParseSchedule(input string) (*Schedule, error) {
+	spec := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	s := &Schedule{spec: spec}
+
+	switch {
+	case weeklyRe.MatchString(spec):
+		match := weeklyRe.FindStringSubmatch(spec)
+		wd, ok := parseWeekday(match[1])
+		if !ok {
+			return nil, invalidSchedule(input)
+		}
+		hour, min, ok := parseClock(match[2])
+		if !ok {
+			return nil, invalidSchedule(input)
+		}
+		s.minute, s.hour, s.dow = bit(min), bit(hour), bit(int(wd))
+		s.dom, s.month = span(1, 31), span(1, 12)
+		s.anyDom = true
+
+	case monthlyRe.MatchString(spec):
+		match := monthlyRe.FindStringSubmatch(spec)
+		day, _ := strconv.Atoi(match[1])
+		hour, min, ok := parseClock(match[2])
+		if !ok || day < 1 || day > 31 {
+			return nil, invalidSchedule(input)
+		}
+		s.minute, s.hour, s.dom = bit(min), bit(hour), bit(day)
+		s.month, s.dow = span(1, 12), span(0, 6)
+		s.anyDow = true
+
+	case cronRe.MatchString(spec):
+		fields := strings.Fields(cronRe.FindStringSubmatch(spec)[1])
+		if len(fields) != 5 {
+			return nil, invalidSchedule(input)
+		}
+
+		var err error
+		bounds := []struct {
+			field    *uint64
+			min, max int
+		}{
+			{&s.minute, 0, 59},
+			{&s.hour, 0, 23},
+			{&s.dom, 1, 31},
+			{&s.month, 1, 12},
+			// both 0 and 7 are sunday
+			{&s.dow, 0, 7},
+		}
+		for i, v := range bounds {
+			*v.field, err = parseCronField(fields[i], v.min, v.max)
+			if err != nil {
+				return nil, &ErrInvalidDate{fmt.Sprintf("Invalid cron expression '%s': %s", fields[i], err)}
+			}
+		}
+		if s.dow&bit(7) != 0 {
+			s.dow |= bit(0)
+		}
+		s.anyDom = strings.HasPrefix(fields[2], "*")
+		s.anyDow = strings.HasPrefix(fields[4], "*")
+
+	default:
+		return nil, invalidSchedule(input)
+	}
+
+	if s.Next(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), time.UTC).IsZero() {
+		return nil, &ErrInvalidDate{fmt.Sprintf("The schedule '%s' never comes around", input)}
+	}
+
+	return s, nil
+}
+
+func invalidSchedule(input string) error {
+	return &ErrInvalidDate{fmt.Sprintf("Could not read '%s' as a schedule, try e.g. %s", input, ScheduleExamples)}
+}
+
+// String is the schedule as it was given, normalized to lowercase
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+// Next is the first occurrence strictly after the given time, read in loc. The zero time is
+// returned if there is none within the next years.
+func (s *Schedule) Next(after time.Time, loc *time.Location) time.Time {
+	after = after.In(loc)
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute()+1, 0, 0, loc)
+	limit := after.Year() + scheduleHorizon
+
+	for t.Year() <= limit {
+		switch {
+		case s.month&bit(int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&bit(t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&bit(t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&bit(t.Day()) != 0
+	dow := s.dow&bit(int(t.Weekday())) != 0
+
+	switch {
+	case s.anyDom:
+		return dow
+	case s.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// parseCronField reads a comma separated list of `*`, single values and ranges, each optionally
+// followed by a step such as `*/15` or `1-5/2`
+func parseCronField(field string, min, max int) (uint64, error) {
+	var out uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in '%s'", item)
+			}
+			rng, step = item[:i], n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			parts := strings.SplitN(rng, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(parts[0])
+			hi, err2 = strconv.Atoi(parts[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid range '%s'", rng)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value '%s'", rng)
+			}
+			lo, hi = n, n
+			if step > 1 {
+				// `5/15` means every 15 starting at 5, like `5-max/15`
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("'%s' is out of range %d-%d", rng, min, max)
+		}
+		for i := lo; i <= hi; i += step {
+			out |= bit(i)
+		}
+	}
+	return out, nil
+}
+
+func bit(i int) uint64 {
+	return 1 << uint(i)
+}
+
+// span sets every bit from lo to hi
+func span(lo, hi int) uint64 {
+	var out uint64
+	for i := lo; i <= hi; i++ {
+		out |= bit(i)
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package dates_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/dates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// a Wednesday
+	now := time.Date(2022, time.June, 29, 15, 30, 0, 0, berlin)
+
+	cases := []struct {
+		input string
+		want  time.Time
+	}{
+		{"weekly sunday 18:00", time.Date(2022, time.July, 3, 18, 0, 0, 0, berlin)},
+		{"Weekly Wed at 8pm", time.Date(2022, time.June, 29, 20, 0, 0, 0, berlin)},
+		// it's past 15:00 already, so it's the one next week
+		{"weekly wednesday 15:00", time.Date(2022, time.July, 6, 15, 0, 0, 0, berlin)},
+		{"monthly 1 18:00", time.Date(2022, time.July, 1, 18, 0, 0, 0, berlin)},
+		{"monthly 1st at 6pm", time.Date(2022, time.July, 1, 18, 0, 0, 0, berlin)},
+		// july has a 31st, june doesn't
+		{"monthly 31 12:00", time.Date(2022, time.July, 31, 12, 0, 0, 0, berlin)},
+		{"cron 0 18 * * 0", time.Date(2022, time.July, 3, 18, 0, 0, 0, berlin)},
+		{"cron 0 18 * * 7", time.Date(2022, time.July, 3, 18, 0, 0, 0, berlin)},
+		{"cron */15 * * * *", time.Date(2022, time.June, 29, 15, 45, 0, 0, berlin)},
+		{"cron 0 9 * * 1-5", time.Date(2022, time.June, 30, 9, 0, 0, 0, berlin)},
+		{"cron 0 0 1 1,7 *", time.Date(2022, time.July, 1, 0, 0, 0, 0, berlin)},
+		// with both the day of the month and of the week restricted, either of them matches
+		{"cron 0 12 15 * 5", time.Date(2022, time.July, 1, 12, 0, 0, 0, berlin)},
+	}
+
+	for _, v := range cases {
+		s, err := dates.ParseSchedule(v.input)
+		if assert.NoError(t, err, v.input) {
+			got := s.Next(now, berlin)
+			assert.True(t, v.want.Equal(got), "%s: want %s, got %s", v.input, v.want, got)
+		}
+	}
+}
+
+func TestScheduleNextDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	s, err := dates.ParseSchedule("weekly sunday 18:00")
+	require.NoError(t, err)
+
+	// clocks go back an hour on the last sunday of october, the event still starts at 18:00
+	first := s.Next(time.Date(2022, time.October, 24, 0, 0, 0, 0, berlin), berlin)
+	second := s.Next(first, berlin)
+	assert.Equal(t, time.Date(2022, time.October, 30, 18, 0, 0, 0, berlin), first)
+	assert.Equal(t, time.Date(2022, time.November, 6, 18, 0, 0, 0, berlin), second)
+	assert.Equal(t, 7*24*time.Hour, second.Sub(first))
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, v := range []string{
+		"",
+		"every sunday",
+		"weekly someday 18:00",
+		"weekly sunday 25:00",
+		"monthly 32 18:00",
+		"cron 0 18 * *",
+		"cron 60 18 * * *",
+		"cron 0 18 * * mon",
+		"cron 0 18 */0 * *",
+		// february never has a 31st
+		"cron 0 0 31 2 *",
+	} {
+		_, err := dates.ParseSchedule(v)
+		_, ok := dates.AsErrInvalidDate(err)
+		assert.True(t, ok, v)
+	}
+}
@@ -0,0 +1,65 @@
+package discord
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+// maxMessageLength is the most characters discord takes in a single message
+const maxMessageLength = 2000
+
+// announcementChannel is where announcements of the guild go, the announcement channel if the
+// guild set one, fallback otherwise
+func (h *EventHandler) announcementChannel(gid, fallback string) string {
+	if cid := h.guildSettings(gid).AnnouncementChannel; cid != "" {
+		return cid
+	}
+	return fallback
+}
+
+// announce posts msg in the announcement channel of the guild, pinging the given users. Long
+// mention lists are spread over as many messages as needed.
+func (h *EventHandler) announce(
+	s *discordgo.Session,
+	gid, fallbackChannel, msg string,
+	uids []string,
+	l *zap.Logger,
+) {
+	cid := h.announcementChannel(gid, fallbackChannel)
+
+	for _, content := range mentionMessages(msg, uids) {
+		_, err := s.ChannelMessageSendComplex(cid, &discordgo.MessageSend{
+			Content: content,
+			// only the participants get pinged, never roles or everyone a moderator typed in
+			AllowedMentions: &discordgo.MessageAllowedMentions{Users: uids},
+		})
+		if err != nil {
+			l.Error("could not send announcement", zap.Error(err), WithChannelID(cid))
+			return
+		}
+	}
+}
+
+// mentionMessages appends the user mentions to msg, splitting them up so every message stays
+// within the length limit
+func mentionMessages(msg string, uids []string) []string {
+	messages := []string{}
+	b := strings.Builder{}
+	b.WriteString(msg)
+	if len(uids) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, v := range uids {
+		mention := "<@" + v + "> "
+		if b.Len()+len(mention) > maxMessageLength {
+			messages = append(messages, strings.TrimSpace(b.String()))
+			b.Reset()
+		}
+		b.WriteString(mention)
+	}
+
+	return append(messages, strings.TrimSpace(b.String()))
+}
@@ -0,0 +1,51 @@
+package discord
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+func (h *EventHandler) apiKeySubcommands() []*subcommand {
+	return []*subcommand{
+		{
+			Name:        "generate",
+			Description: "Generate an API key for this server, replacing the current one",
+			RoleAction:  manageEventDialog,
+			Handler:     h.handleAPIKeyGenerate,
+		},
+		{
+			Name:        "revoke",
+			Description: "Revoke the API key of this server",
+			RoleAction:  manageEventDialog,
+			Handler:     h.handleAPIKeyRevoke,
+		},
+	}
+}
+
+func (h *EventHandler) handleAPIKeyGenerate(c *commandContext) {
+	key, err := h.MetadataService.CreateAPIKey(c.i.GuildID)
+	if err != nil {
+		c.logger.Error("could not create api key", zap.Error(err))
+		c.r.errorOrLog("Could not generate an API key." + internalError)
+		return
+	}
+
+	// only a hash is stored, this is the one chance to see the key
+	c.r.errorOrLog(fmt.Sprintf(
+		"Here's the API key for this server, keep it somewhere safe as it can't be shown again. "+
+			"Any previous key no longer works.\n```\n%s\n```",
+		key,
+	))
+}
+
+func (h *EventHandler) handleAPIKeyRevoke(c *commandContext) {
+	err := h.MetadataService.DeleteAPIKey(c.i.GuildID)
+	if err != nil {
+		c.logger.Error("could not delete api key", zap.Error(err))
+		c.r.errorOrLog("Could not revoke the API key." + internalError)
+		return
+	}
+
+	c.r.errorOrLog("The API key of this server has been revoked")
+}
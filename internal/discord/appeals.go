@@ -0,0 +1,436 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/2785/warframe-assistant/internal/dates"
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/webhook"
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxAppealReasonLength leaves room for the rest of the appeal in the listing of the queue
+	maxAppealReasonLength = 300
+	// maxListedAppeals keeps `/appeals list` within the size limit of a single embed
+	maxListedAppeals = 8
+
+	appealOutcomeGrant = "grant"
+	appealOutcomeDeny  = "deny"
+)
+
+var historyDescriptions = map[scores.HistoryAction]string{
+	scores.HistoryVerified:      "verified",
+	scores.HistoryRejected:      "rejected",
+	scores.HistoryAmended:       "amended",
+	scores.HistoryRemoved:       "removed",
+	scores.HistoryWithdrawn:     "withdrawn",
+	scores.HistoryAppealed:      "appealed",
+	scores.HistoryAppealDenied:  "appeal denied",
+	scores.HistoryAppealGranted: "appeal granted",
+}
+
+func submissionIDOption(description string) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "submission-id",
+		Description: description,
+		Required:    true,
+	}
+}
+
+func (h *EventHandler) appealSubcommand() *subcommand {
+	return &subcommand{
+		Name:        "appeal",
+		Description: "Ask for a moderator's rejection or amendment of your submission to be reviewed",
+		Options: []*discordgo.ApplicationCommandOption{
+			submissionIDOption("The ID of the submission, see `/events my-submissions`"),
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "reason",
+				Description: "Why the decision was wrong, e.g. what the screenshot shows",
+				Required:    true,
+			},
+		},
+		Handler: h.handleEventsAppeal,
+	}
+}
+
+func (h *EventHandler) appealsSubcommands() []*subcommand {
+	return []*subcommand{
+		{
+			Name:        "list",
+			Description: "List the open appeals of an event, oldest first",
+			RoleAction:  appealDialog,
+			EventID:     optionalEventID,
+			Handler:     h.handleAppealsList,
+		},
+		{
+			Name:        "resolve",
+			Description: "Grant or deny the appeal of a submission",
+			Options: []*discordgo.ApplicationCommandOption{
+				submissionIDOption("The ID of the appealed submission"),
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "outcome",
+					Description: "Grant to overturn the decision of the moderator, deny to keep it",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: appealOutcomeGrant, Value: appealOutcomeGrant},
+						{Name: appealOutcomeDeny, Value: appealOutcomeDeny},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "score",
+					Description: "Score before modifiers for granted appeals, defaults to the score it was submitted with",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "note",
+					Description: "Why the appeal was granted or denied, kept in the history of the submission",
+				},
+			},
+			RoleAction: appealDialog,
+			Handler:    h.handleAppealsResolve,
+		},
+		{
+			Name:        "history",
+			Description: "Show what moderators and the submitter did with a submission",
+			Options:     []*discordgo.ApplicationCommandOption{submissionIDOption("The ID of the submission")},
+			RoleAction:  appealDialog,
+			Handler:     h.handleAppealsHistory,
+		},
+	}
+}
+
+// recordHistory adds the entry to the history of its submission, a failure doesn't undo the
+// action it records
+func (h *EventHandler) recordHistory(entry scores.HistoryEntry, l *zap.Logger) {
+	if err := h.EventScoreService.RecordHistory(entry); err != nil {
+		l.Warn("could not record submission history", zap.Error(err), zap.String("action", string(entry.Action)))
+	}
+}
+
+// recordDecision adds a moderator decision that leaves the score as it is to the history of the
+// submission
+func (h *EventHandler) recordDecision(sid string, action scores.HistoryAction, actor string, l *zap.Logger) {
+	record, err := h.EventScoreService.GetSubmission(sid)
+	if err != nil {
+		l.Warn("could not fetch submission for its history", zap.Error(err))
+		return
+	}
+
+	h.recordHistory(scores.HistoryEntry{
+		SID:           sid,
+		Action:        action,
+		Actor:         actor,
+		PreviousScore: record.RawScore,
+		Score:         record.RawScore,
+	}, l)
+}
+
+// guildSubmission looks up the submission along with its event, replying if it's not one of the
+// guild
+func (h *EventHandler) guildSubmission(c *commandContext, sid string) (*scores.ScoreRecord, *meta.Event, bool) {
+	notFound := fmt.Sprintf("No submission with ID '%s'", sid)
+	if _, err := uuid.Parse(sid); err != nil {
+		c.r.errorOrLog(notFound)
+		return nil, nil, false
+	}
+
+	record, err := h.EventScoreService.GetSubmission(sid)
+	if err != nil {
+		if scores.AsErrNoRecord(err) {
+			c.r.errorOrLog(notFound)
+			return nil, nil, false
+		}
+		c.logger.Error("could not fetch submission", zap.Error(err), WithSubmissionID(sid))
+		c.r.errorOrLog("Could not fetch the submission." + internalError)
+		return nil, nil, false
+	}
+
+	event, err := h.MetadataService.GetEvent(record.EID)
+	if err != nil {
+		c.logger.Error("could not fetch event", zap.Error(err), WithSubmissionID(sid))
+		c.r.errorOrLog("Could not fetch event information." + internalError)
+		return nil, nil, false
+	}
+
+	if event.GID != c.i.GuildID {
+		c.r.errorOrLog(notFound)
+		return nil, nil, false
+	}
+
+	return record, event, true
+}
+
+func (h *EventHandler) handleEventsAppeal(c *commandContext) {
+	sid, _ := c.stringOption("submission-id")
+	sid = strings.TrimSpace(sid)
+	logger := c.logger.With(WithSubmissionID(sid))
+
+	reason, _ := c.stringOption("reason")
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxAppealReasonLength {
+		c.r.errorOrLog(fmt.Sprintf("The reason must be between 1 and %d characters", maxAppealReasonLength))
+		return
+	}
+
+	record, event, ok := h.guildSubmission(c, sid)
+	if !ok {
+		return
+	}
+
+	// submissions of other users are reported missing the same as ones that don't exist
+	if record.UID != c.uid() {
+		c.r.errorOrLog(fmt.Sprintf("No submission with ID '%s'", sid))
+		return
+	}
+
+	if event.Finalized() {
+		c.r.errorOrLog("The results of this event are final, its submissions can't be appealed anymore")
+		return
+	}
+
+	history, err := h.EventScoreService.GetHistory(sid)
+	if err != nil {
+		logger.Error("could not fetch submission history", zap.Error(err))
+		c.r.errorOrLog("Could not fetch the history of the submission." + internalError)
+		return
+	}
+
+	if !scores.Appealable(history) {
+		c.r.errorOrLog("Only submissions a moderator rejected, amended or removed can be appealed")
+		return
+	}
+
+	err = h.EventScoreService.FileAppeal(sid, reason)
+	if err != nil {
+		if errors.Is(err, scores.ErrAppealExists) {
+			c.r.errorOrLog("This submission was appealed already, every submission can be appealed once")
+			return
+		}
+		logger.Error("could not file appeal", zap.Error(err))
+		c.r.errorOrLog("Could not file the appeal." + internalError)
+		return
+	}
+
+	h.recordHistory(scores.HistoryEntry{
+		SID:           sid,
+		Action:        scores.HistoryAppealed,
+		Actor:         c.uid(),
+		PreviousScore: record.RawScore,
+		Score:         record.RawScore,
+		Detail:        reason,
+	}, logger)
+
+	c.r.replyOrLog(fmt.Sprintf(
+		"Appealed submission `%s`, it counts as it is now until the appeal is resolved. "+
+			"Opt in with `/notify set submissions: true` to get a DM with the outcome.",
+		sid,
+	))
+}
+
+func (h *EventHandler) handleAppealsList(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	appeals, err := h.EventScoreService.ListPendingAppeals(c.eid, maxListedAppeals)
+	if err != nil {
+		c.logger.Error("could not list appeals", zap.Error(err))
+		c.r.errorOrLog("Could not list the appeals of the event." + internalError)
+		return
+	}
+
+	if len(appeals) == 0 {
+		c.r.replyOrLog(fmt.Sprintf(":tada: There are no open appeals in '%s'", event.Name))
+		return
+	}
+
+	fields := make([]*discordgo.MessageEmbedField, len(appeals))
+	for i, v := range appeals {
+		claimed, now := v.RawScore, fmt.Sprintf("now %d", v.RawScore)
+		if v.Removed {
+			now = "removed"
+		}
+		if history, err := h.EventScoreService.GetHistory(v.ID); err == nil {
+			claimed = scores.ClaimedScore(history, v.RawScore)
+		} else {
+			c.logger.Warn("could not fetch submission history", zap.Error(err), WithSubmissionID(v.ID))
+		}
+
+		fields[i] = &discordgo.MessageEmbedField{
+			Name: v.ID,
+			Value: fmt.Sprintf(
+				"`%s` submitted %d, %s, appealed %s\n> %s\n[Proof](%s)",
+				v.IGN,
+				claimed,
+				now,
+				dates.Timestamp(v.AppealedAt, dates.Relative),
+				v.Reason,
+				v.Proof,
+			),
+		}
+	}
+
+	err = c.r.Respond(&discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:  fmt.Sprintf("Open appeals of '%s'", event.Name),
+				Fields: fields,
+				Footer: &discordgo.MessageEmbedFooter{Text: "Resolve them with /appeals resolve, oldest first"},
+			},
+		},
+		Flags: ephemeralFlag,
+	})
+	if err != nil {
+		c.logger.Error("could not respond to interaction", zap.Error(err))
+	}
+}
+
+func (h *EventHandler) handleAppealsResolve(c *commandContext) {
+	sid, _ := c.stringOption("submission-id")
+	sid = strings.TrimSpace(sid)
+	logger := c.logger.With(WithSubmissionID(sid))
+
+	record, event, ok := h.guildSubmission(c, sid)
+	if !ok {
+		return
+	}
+
+	if record.UID == c.uid() {
+		c.r.errorOrLog("You can't resolve the appeal of your own submission, it has to be another moderator")
+		return
+	}
+
+	outcome, _ := c.stringOption("outcome")
+	granted := outcome == appealOutcomeGrant
+	if granted && event.Finalized() {
+		c.r.errorOrLog("The results of this event are final, its appeals can only be denied")
+		return
+	}
+
+	history, err := h.EventScoreService.GetHistory(sid)
+	if err != nil {
+		logger.Error("could not fetch submission history", zap.Error(err))
+		c.r.errorOrLog("Could not fetch the history of the submission." + internalError)
+		return
+	}
+
+	score := record.RawScore
+	status, action := scores.AppealDenied, scores.HistoryAppealDenied
+	if granted {
+		status, action = scores.AppealGranted, scores.HistoryAppealGranted
+		score = scores.ClaimedScore(history, record.RawScore)
+		if given, ok := c.intOption("score"); ok {
+			score = given
+		}
+	}
+
+	// resolving goes first, of two moderators resolving the same appeal only one gets through
+	err = h.EventScoreService.ResolveAppeal(sid, status, c.uid())
+	if err != nil {
+		if scores.AsErrNoRecord(err) {
+			c.r.errorOrLog("This submission has no open appeal")
+			return
+		}
+		logger.Error("could not resolve appeal", zap.Error(err))
+		c.r.errorOrLog("Could not resolve the appeal." + internalError)
+		return
+	}
+
+	note, _ := c.stringOption("note")
+	h.recordHistory(scores.HistoryEntry{
+		SID:           sid,
+		Action:        action,
+		Actor:         c.uid(),
+		PreviousScore: record.RawScore,
+		Score:         score,
+		Detail:        strings.TrimSpace(note),
+	}, logger)
+
+	if !granted {
+		kept := fmt.Sprintf("it keeps its score of %d", record.RawScore)
+		if record.Removed {
+			kept = "it stays removed"
+		}
+		c.r.replyOrLog(fmt.Sprintf("Denied the appeal of submission `%s`, %s", sid, kept))
+		h.notifySubmitter(c.s, record.UID, sid, event.Name, "had its appeal denied, the decision of the moderator stands", logger)
+		return
+	}
+
+	err = h.EventScoreService.UpdateScoreAndVerify(sid, score, c.uid())
+	if err != nil {
+		logger.Error("could not update score of granted appeal", zap.Error(err))
+		c.r.errorOrLog(fmt.Sprintf(
+			"Granted the appeal, but the score could not be changed, set it with `/events update-score submission-id: %s new-score: %d`",
+			sid,
+			score,
+		))
+		return
+	}
+
+	h.ScheduleLiveLeaderboardUpdate(record.EID)
+	h.dispatchSubmissionWebhook(c.i.GuildID, webhook.SubmissionAmended, sid, logger)
+
+	c.r.replyOrLog(fmt.Sprintf("Granted the appeal of submission `%s`, its score is now %d", sid, score))
+	h.notifySubmitter(
+		c.s,
+		record.UID,
+		sid,
+		event.Name,
+		fmt.Sprintf("had its appeal granted, its score is now %d", score),
+		logger,
+	)
+}
+
+func (h *EventHandler) handleAppealsHistory(c *commandContext) {
+	sid, _ := c.stringOption("submission-id")
+	sid = strings.TrimSpace(sid)
+
+	if _, _, ok := h.guildSubmission(c, sid); !ok {
+		return
+	}
+
+	history, err := h.EventScoreService.GetHistory(sid)
+	if err != nil {
+		c.logger.Error("could not fetch submission history", zap.Error(err), WithSubmissionID(sid))
+		c.r.errorOrLog("Could not fetch the history of the submission." + internalError)
+		return
+	}
+
+	if len(history) == 0 {
+		c.r.privateReplyOrLog(fmt.Sprintf("Nothing was recorded for submission `%s`", sid))
+		return
+	}
+
+	lines := make([]string, len(history))
+	for i, v := range history {
+		score := fmt.Sprint(v.Score)
+		if v.PreviousScore != v.Score {
+			score = fmt.Sprintf("%d → %d", v.PreviousScore, v.Score)
+		}
+		lines[i] = fmt.Sprintf(
+			"%s - **%s** by <@%s>, score %s",
+			dates.Timestamp(v.CreatedAt, dates.ShortDateTime),
+			historyDescriptions[v.Action],
+			v.Actor,
+			score,
+		)
+		if v.Detail != "" {
+			lines[i] += "\n> " + v.Detail
+		}
+	}
+
+	// the history mentions moderators and the submitter, it's only shown to whoever asked so nobody
+	// gets pinged
+	c.r.privateReplyOrLog(fmt.Sprintf("History of submission `%s`\n%s", sid, strings.Join(lines, "\n")))
+}
@@ -0,0 +1,245 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/2785/warframe-assistant/internal/dates"
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/webhook"
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	eventDeleteConfirmButton = "event-delete-confirm-btn"
+	eventDeleteCancelButton  = "event-delete-cancel-btn"
+)
+
+// buttonIDSeparator separates the button from the argument it carries in a custom ID, only the
+// button part is used for auth and metrics
+const buttonIDSeparator = ":"
+
+func splitButtonID(customID string) (btn, arg string) {
+	i := strings.Index(customID, buttonIDSeparator)
+	if i < 0 {
+		return customID, ""
+	}
+	return customID[:i], customID[i+len(buttonIDSeparator):]
+}
+
+// guildEvent fetches the event for a command, replying with an error if it doesn't exist in the
+// guild the command was used in
+func (h *EventHandler) guildEvent(eid, gid string, reply MessageReplier, l *zap.Logger) (*meta.Event, bool) {
+	if _, err := uuid.Parse(eid); err != nil {
+		replyWithErrorLogging(reply, fmt.Sprintf("No event with ID '%s'", eid), l)
+		return nil, false
+	}
+
+	event, err := h.MetadataService.GetEvent(eid)
+	if err != nil && !meta.AsErrNoRecord(err) {
+		l.Error("could not fetch event", zap.Error(err))
+		replyWithErrorLogging(reply, "Could not fetch event information."+internalError, l)
+		return nil, false
+	}
+	if err != nil || event.GID != gid {
+		replyWithErrorLogging(reply, fmt.Sprintf("No event with ID '%s'", eid), l)
+		return nil, false
+	}
+	return event, true
+}
+
+// finalizeEvent snapshots the current standings of the event and makes them final, returning how
+// many participants were ranked
+func (h *EventHandler) finalizeEvent(event *meta.Event) (int, error) {
+	leaderboard, mode, _, err := h.standings(event)
+	if err != nil && !errors.Is(err, errUnsupportedLeaderboard) {
+		return 0, err
+	}
+
+	// the snapshot goes first, a final event without one would show an empty leaderboard
+	err = h.EventScoreService.SaveResults(event.ID, mode, leaderboard)
+	if err != nil && !errors.Is(err, scores.ErrResultsFinal) {
+		return 0, err
+	}
+
+	// losing a race with another moderator finalizing the same event is fine
+	if err := h.MetadataService.FinalizeEvent(event.ID); err != nil && !meta.AsErrNoRecord(err) {
+		return 0, err
+	}
+
+	return len(leaderboard), nil
+}
+
+func (h *EventHandler) handleEventsFinalize(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	if event.Finalized() {
+		c.r.errorOrLog(fmt.Sprintf(
+			"The results of '%s' are already final since %s",
+			event.Name,
+			dates.Timestamp(*event.FinalizedAt, dates.ShortDateTime),
+		))
+		return
+	}
+
+	ranked, err := h.finalizeEvent(event)
+	if err != nil {
+		c.logger.Error("could not finalize event", zap.Error(err))
+		c.r.errorOrLog("Could not save the final leaderboard." + internalError)
+		return
+	}
+
+	c.r.replyOrLog(fmt.Sprintf(
+		"The results of '%s' are now final with %d ranked participants, later changes to scores or IGNs "+
+			"won't affect them",
+		event.Name,
+		ranked,
+	))
+
+	h.ScheduleLiveLeaderboardUpdate(c.eid)
+	h.dispatchEventWebhook(c.i.GuildID, webhook.EventFinalized, c.eid, c.logger)
+	h.notifyParticipants(c.s, c.eid, webhook.EventFinalized, c.logger)
+}
+
+func (h *EventHandler) handleEventsArchive(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	if event.Archived {
+		c.r.errorOrLog(fmt.Sprintf("Event '%s' is already archived", event.Name))
+		return
+	}
+
+	if !event.Finalized() {
+		if _, err := h.finalizeEvent(event); err != nil {
+			c.logger.Error("could not finalize event", zap.Error(err))
+			c.r.errorOrLog("Could not save the final leaderboard." + internalError)
+			return
+		}
+	}
+
+	if err := h.MetadataService.ArchiveEvent(c.eid); err != nil {
+		c.logger.Error("could not archive event", zap.Error(err))
+		c.r.errorOrLog("Could not archive the event." + internalError)
+		return
+	}
+
+	c.r.replyOrLog(fmt.Sprintf(
+		"Archived event '%s', its final leaderboard is kept and can still be seen with "+
+			"`/events progress event-id: %s`",
+		event.Name,
+		c.eid,
+	))
+
+	h.ScheduleLiveLeaderboardUpdate(c.eid)
+	h.dispatchEventWebhook(c.i.GuildID, webhook.EventArchived, c.eid, c.logger)
+	if !event.Finalized() {
+		h.notifyParticipants(c.s, c.eid, webhook.EventFinalized, c.logger)
+	}
+}
+
+func (h *EventHandler) handleEventsDelete(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	participations, submissions, err := h.EventScoreService.CountForEvent(c.eid)
+	if err != nil {
+		c.logger.Error("could not count event records", zap.Error(err))
+		c.r.errorOrLog("Could not check what would be deleted." + internalError)
+		return
+	}
+
+	err = c.r.Respond(&discordgo.InteractionResponseData{
+		Content: fmt.Sprintf(
+			"Deleting event '%s' also deletes **%d** participation records and **%d** submissions, "+
+				"this can't be undone. Use `/events archive` instead to keep the results.",
+			event.Name,
+			participations,
+			submissions,
+		),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Delete",
+						Style:    discordgo.DangerButton,
+						CustomID: eventDeleteConfirmButton + buttonIDSeparator + c.eid,
+					},
+					discordgo.Button{
+						Label:    "Cancel",
+						Style:    discordgo.SecondaryButton,
+						CustomID: eventDeleteCancelButton,
+					},
+				},
+			},
+		},
+		Flags: ephemeralFlag,
+	})
+	if err != nil {
+		c.logger.Error("could not respond to interaction", zap.Error(err))
+	}
+}
+
+func (h *EventHandler) handleEventDeleteConfirmButton(
+	eid string,
+	i *discordgo.Interaction,
+	r *Responder,
+	l *zap.Logger,
+) {
+	l = l.With(WithEventID(eid))
+
+	event, ok := h.guildEvent(eid, i.GuildID, r.ReplyEphemeral, l)
+	if !ok {
+		return
+	}
+
+	participations, submissions, err := h.EventScoreService.CountForEvent(eid)
+	if err != nil {
+		l.Error("could not count event records", zap.Error(err))
+		replyWithErrorLogging(r.ReplyEphemeral, "Could not delete the event."+internalError, l)
+		return
+	}
+
+	if err := h.MetadataService.DeleteEvent(eid); err != nil {
+		l.Error("could not delete event", zap.Error(err))
+		replyWithErrorLogging(r.ReplyEphemeral, "Could not delete the event."+internalError, l)
+		return
+	}
+
+	l.Info("deleted event", zap.Int("participations", participations), zap.Int("submissions", submissions))
+	h.Webhooks.Dispatch(i.GuildID, webhook.EventDeleted, webhook.ToEvent(event))
+
+	err = r.Update(&discordgo.InteractionResponseData{
+		Content: fmt.Sprintf(
+			"Deleted event '%s' along with %d participation records and %d submissions",
+			event.Name,
+			participations,
+			submissions,
+		),
+		Components: []discordgo.MessageComponent{},
+	})
+	if err != nil {
+		l.Error("could not edit interaction response", zap.Error(err))
+	}
+}
+
+func (h *EventHandler) handleEventDeleteCancelButton(r *Responder, l *zap.Logger) {
+	err := r.Update(&discordgo.InteractionResponseData{
+		Content:    "Nothing was deleted",
+		Components: []discordgo.MessageComponent{},
+	})
+	if err != nil {
+		l.Error("could not edit interaction response", zap.Error(err))
+	}
+}
@@ -2,8 +2,11 @@ package discord
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/2785/warframe-assistant/internal/metrics"
 	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/webhook"
 	"github.com/bwmarrin/discordgo"
 	"github.com/thoas/go-funk"
 	"go.uber.org/zap"
@@ -17,17 +20,22 @@ const (
 )
 
 var buttonAuth map[string]string = map[string]string{
-	scoreVerificationBotton: string(verificationDialog),
-	scoreRejectButton:       string(verificationDialog),
-	scoreNextButton:         string(verificationDialog),
-	scoreRemoveButton:       string(verificationDialog),
+	scoreVerificationBotton:  string(verificationDialog),
+	scoreRejectButton:        string(verificationDialog),
+	scoreNextButton:          string(verificationDialog),
+	scoreRemoveButton:        string(verificationDialog),
+	eventDeleteConfirmButton: string(manageEventDialog),
+	eventDeleteCancelButton:  string(manageEventDialog),
 }
 
 func (h *EventHandler) handleInteractionButtons(
 	s *discordgo.Session,
 	i *discordgo.Interaction,
-	btn string,
+	r *Responder,
+	customID string,
 ) {
+	btn, arg := splitButtonID(customID)
+
 	logger := h.Logger.With(
 		WithComponent("interaction-button-handler"),
 		WithGuildID(i.GuildID),
@@ -38,24 +46,40 @@ func (h *EventHandler) handleInteractionButtons(
 
 	logger.Debug("handleInteractionButtons", zap.String("btn", btn))
 
+	// custom IDs come from the client, keep anything we don't know about out of the labels
+	if _, ok := buttonAuth[btn]; ok {
+		defer metrics.ObserveButton(btn, time.Now())
+	} else {
+		defer metrics.ObserveButton("unknown", time.Now())
+	}
+
 	// handle auth - now admittedly this auth should probably be taken from a config file as opposed
 	// to hard coded in code.
-	if r, ok := buttonAuth[btn]; ok {
-		rid, err := h.MetadataService.GetRoleRequirementForGuild(r, i.GuildID)
+	if action, ok := buttonAuth[btn]; ok {
+		rid, err := h.MetadataService.GetRoleRequirementForGuild(action, i.GuildID)
 		if err != nil {
 			replyWithErrorLogging(
-				interactionReplier(s, i),
+				r.ReplyEphemeral,
 				"Could not retrieve role requirement for action."+internalError,
-				logger.With(zap.String("dialog-id", r)),
+				logger.With(zap.String("dialog-id", action)),
 			)
 			return
 		}
 
-		if !h.mustHaveRoleWithID(i.Member.User.ID, rid, i.GuildID, interactionReplier(s, i), s) {
+		if !h.mustHaveRoleWithID(i.Member.User.ID, rid, i.GuildID, r.ReplyEphemeral, s) {
 			return
 		}
 	}
 
+	switch btn {
+	case eventDeleteConfirmButton:
+		h.handleEventDeleteConfirmButton(arg, i, r, logger)
+		return
+	case eventDeleteCancelButton:
+		h.handleEventDeleteCancelButton(r, logger)
+		return
+	}
+
 	// extract submission information for each of the verification dialog button options
 	if funk.Contains(
 		[]string{scoreVerificationBotton, scoreRejectButton, scoreNextButton, scoreRemoveButton},
@@ -65,7 +89,7 @@ func (h *EventHandler) handleInteractionButtons(
 		if err != nil {
 			logger.Error("could not parse message embeds", zap.Error(err))
 			replyWithErrorLogging(
-				interactionReplier(s, i),
+				r.ReplyEphemeral,
 				"Error parsing message."+internalError,
 				logger,
 			)
@@ -74,16 +98,16 @@ func (h *EventHandler) handleInteractionButtons(
 
 		switch btn {
 		case scoreVerificationBotton:
-			h.handleVerifyButton(dialog, s, i, logger)
+			h.handleVerifyButton(dialog, s, i, r, logger)
 		case scoreRejectButton:
-			h.handleRejectButton(dialog, s, i, logger)
+			h.handleRejectButton(dialog, s, i, r, logger)
 		case scoreNextButton:
-			h.handleNextButton(dialog, s, i, logger)
+			h.handleNextButton(dialog, s, i, r, logger)
 		case scoreRemoveButton:
-			h.handleRemoveButton(dialog, s, i, logger)
+			h.handleRemoveButton(dialog, s, i, r, logger)
 		default:
 			replyWithErrorLogging(
-				interactionReplier(s, i),
+				r.ReplyEphemeral,
 				"Unknown button interaction."+internalError,
 				logger,
 			)
@@ -93,7 +117,7 @@ func (h *EventHandler) handleInteractionButtons(
 	}
 
 	replyWithErrorLogging(
-		interactionReplier(s, i),
+		r.ReplyEphemeral,
 		"Unknown button interaction."+internalError,
 		logger,
 	)
@@ -104,33 +128,48 @@ func (h *EventHandler) handleVerifyButton(
 	d *VerificationDialog,
 	s *discordgo.Session,
 	i *discordgo.Interaction,
+	r *Responder,
 	l *zap.Logger,
 ) {
 
-	err := h.EventScoreService.Verify(d.SID)
+	err := h.EventScoreService.Verify(d.SID, i.Member.User.ID)
+	if scores.AsErrNoRecord(err) {
+		replyWithErrorLogging(r.ReplyEphemeral, "This submission was withdrawn or removed already", l)
+		return
+	}
 	if err != nil {
 		l.Error("could not verify score", zap.Error(err))
-		replyWithErrorLogging(interactionReplier(s, i), "Could not verify score."+internalError, l)
+		replyWithErrorLogging(r.ReplyEphemeral, "Could not verify score."+internalError, l)
 		return
 	}
 
+	metrics.Verifications.WithLabelValues(metrics.VerificationVerify).Inc()
+	h.recordDecision(d.SID, scores.HistoryVerified, i.Member.User.ID, l)
+	h.ScheduleLiveLeaderboardUpdate(d.EID)
+	h.dispatchSubmissionWebhook(i.GuildID, webhook.SubmissionVerified, d.SID, l)
+	h.notifySubmitter(
+		s,
+		h.submitterOf(d.SID, l),
+		d.SID,
+		d.EventName,
+		fmt.Sprintf("was verified by a moderator with a score of %s", d.Score),
+		l,
+	)
+
 	d.Verified = true
 	d.VerifiedBy = formatMember(i.Member)
 
 	l.Debug("interaction in verify button", zap.Any("interaction", i))
 
-	err = s.InteractionRespond(i, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseUpdateMessage,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{d.ToEmbed()},
-			Components: []discordgo.MessageComponent{
-				discordgo.ActionsRow{
-					Components: []discordgo.MessageComponent{
-						discordgo.Button{
-							Label:    "Next",
-							Style:    discordgo.PrimaryButton,
-							CustomID: scoreNextButton,
-						},
+	err = r.Update(&discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{d.ToEmbed()},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Next",
+						Style:    discordgo.PrimaryButton,
+						CustomID: scoreNextButton,
 					},
 				},
 			},
@@ -139,7 +178,7 @@ func (h *EventHandler) handleVerifyButton(
 
 	if err != nil {
 		l.Error("could not edit interaction response", zap.Error(err))
-		replyWithErrorLogging(interactionReplier(s, i), "Could not update embed."+internalError, l)
+		replyWithErrorLogging(r.ReplyEphemeral, "Could not update embed."+internalError, l)
 	}
 }
 
@@ -147,47 +186,61 @@ func (h *EventHandler) handleRejectButton(
 	d *VerificationDialog,
 	s *discordgo.Session,
 	i *discordgo.Interaction,
+	r *Responder,
 	l *zap.Logger,
 ) {
-	err := h.EventScoreService.Verify(d.SID)
+	err := h.EventScoreService.Verify(d.SID, i.Member.User.ID)
+	if scores.AsErrNoRecord(err) {
+		replyWithErrorLogging(r.ReplyEphemeral, "This submission was withdrawn or removed already", l)
+		return
+	}
 	if err != nil {
 		l.Error("could not verify score", zap.Error(err))
-		replyWithErrorLogging(interactionReplier(s, i), "Could not verify score."+internalError, l)
+		replyWithErrorLogging(r.ReplyEphemeral, "Could not verify score."+internalError, l)
 		return
 	}
 
-	err = s.InteractionRespond(i, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseUpdateMessage,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{
-				d.ToEmbed(),
-				{
-					Title:       "Instruction",
-					Description: "Please use the update command to give the submission a new score or remove this entry",
-					Fields: []*discordgo.MessageEmbedField{
-						{
-							Name: "Template",
-							Value: fmt.Sprintf(
-								"```\n/events update-score submission-id: %s new-score: <new score>\n```",
-								d.SID,
-							),
-						},
+	metrics.Verifications.WithLabelValues(metrics.VerificationReject).Inc()
+	h.recordDecision(d.SID, scores.HistoryRejected, i.Member.User.ID, l)
+	h.ScheduleLiveLeaderboardUpdate(d.EID)
+	h.notifySubmitter(
+		s,
+		h.submitterOf(d.SID, l),
+		d.SID,
+		d.EventName,
+		"was rejected by a moderator, its score will be corrected or it will be removed",
+		l,
+	)
+
+	err = r.Update(&discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			d.ToEmbed(),
+			{
+				Title:       "Instruction",
+				Description: "Please use the update command to give the submission a new score or remove this entry",
+				Fields: []*discordgo.MessageEmbedField{
+					{
+						Name: "Template",
+						Value: fmt.Sprintf(
+							"```\n/events update-score submission-id: %s new-score: <new score>\n```",
+							d.SID,
+						),
 					},
 				},
 			},
-			Components: []discordgo.MessageComponent{
-				discordgo.ActionsRow{
-					Components: []discordgo.MessageComponent{
-						discordgo.Button{
-							Label:    "Next",
-							Style:    discordgo.PrimaryButton,
-							CustomID: scoreNextButton,
-						},
-						discordgo.Button{
-							Label:    "Remove",
-							Style:    discordgo.DangerButton,
-							CustomID: scoreRemoveButton,
-						},
+		},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Next",
+						Style:    discordgo.PrimaryButton,
+						CustomID: scoreNextButton,
+					},
+					discordgo.Button{
+						Label:    "Remove",
+						Style:    discordgo.DangerButton,
+						CustomID: scoreRemoveButton,
 					},
 				},
 			},
@@ -196,7 +249,7 @@ func (h *EventHandler) handleRejectButton(
 
 	if err != nil {
 		l.Error("could not edit interaction response", zap.Error(err))
-		replyWithErrorLogging(interactionReplier(s, i), "Could not update embed."+internalError, l)
+		replyWithErrorLogging(r.ReplyEphemeral, "Could not update embed."+internalError, l)
 	}
 }
 
@@ -204,66 +257,55 @@ func (h *EventHandler) handleNextButton(
 	d *VerificationDialog,
 	s *discordgo.Session,
 	i *discordgo.Interaction,
+	r *Responder,
 	l *zap.Logger,
 ) {
 	record, err := h.EventScoreService.GetOneUnverifiedForEvent(d.EID)
 	if err != nil {
 		if scores.AsErrNoRecord(err) {
 			replyWithErrorLogging(
-				interactionReplier(s, i),
+				r.ReplyEphemeral,
 				":tada: There are no pending submissions to be verified",
 				l,
 			)
 			return
 		}
 		replyWithErrorLogging(
-			interactionReplier(s, i),
+			r.ReplyEphemeral,
 			"Sorry, something's borked."+internalError,
 			l,
 		)
 		return
 	}
 
-	member, err := s.GuildMember(i.GuildID, record.UID)
-	if err != nil {
-		l.Error("could not fetch user information", zap.Error(err))
-		replyWithErrorLogging(
-			interactionReplier(s, i),
-			"Something went wrong."+internalError,
-			l,
-		)
-		return
-	}
-
 	verifDialog := &VerificationDialog{
-		UserDisplay: formatMember(member),
+		UserDisplay: h.Members.DisplayName(s, i.GuildID, record.UID, record.IGN),
 		SID:         record.ID,
 		IGN:         "`" + record.IGN + "`",
 		Score:       fmt.Sprintf("%v", record.Score),
+		Breakdown:   record.Breakdown(),
+		Category:    record.Category,
 		URL:         record.Proof,
 		EID:         d.EID,
 		EventName:   d.EventName,
 	}
 
-	err = s.InteractionRespond(i, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseUpdateMessage,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{
-				verifDialog.ToEmbed(),
-			},
-			Components: []discordgo.MessageComponent{
-				discordgo.ActionsRow{
-					Components: []discordgo.MessageComponent{
-						discordgo.Button{
-							Label:    "Verify",
-							Style:    discordgo.SuccessButton,
-							CustomID: scoreVerificationBotton,
-						},
-						discordgo.Button{
-							Label:    "Reject",
-							Style:    discordgo.DangerButton,
-							CustomID: scoreRejectButton,
-						},
+	err = r.Update(&discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			verifDialog.ToEmbed(),
+		},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Verify",
+						Style:    discordgo.SuccessButton,
+						CustomID: scoreVerificationBotton,
+					},
+					discordgo.Button{
+						Label:    "Reject",
+						Style:    discordgo.DangerButton,
+						CustomID: scoreRejectButton,
 					},
 				},
 			},
@@ -279,14 +321,45 @@ func (h *EventHandler) handleRemoveButton(
 	d *VerificationDialog,
 	s *discordgo.Session,
 	i *discordgo.Interaction,
+	r *Responder,
 	l *zap.Logger,
 ) {
-	err := h.EventScoreService.DeleteScore(d.SID)
+	// webhooks, notifications and the history need the submission as it was before it's removed
+	record, err := h.EventScoreService.GetSubmission(d.SID)
+	if err != nil && !scores.AsErrNoRecord(err) {
+		l.Error("could not fetch submission", zap.Error(err))
+		replyWithErrorLogging(r.ReplyEphemeral, "Error deleting score."+internalError, l)
+		return
+	}
+	if err != nil || record.Removed {
+		replyWithErrorLogging(r.ReplyEphemeral, "This submission was withdrawn or removed already", l)
+		return
+	}
+
+	// removed submissions are kept for their submitter to appeal, of two moderators removing the
+	// same one only the first gets through
+	err = h.EventScoreService.DeleteScore(d.SID)
+	if scores.AsErrNoRecord(err) {
+		replyWithErrorLogging(r.ReplyEphemeral, "This submission was withdrawn or removed already", l)
+		return
+	}
 	if err != nil {
 		l.Error("could not delete score", zap.Error(err))
-		replyWithErrorLogging(interactionReplier(s, i), "Error deleting score."+internalError, l)
+		replyWithErrorLogging(r.ReplyEphemeral, "Error deleting score."+internalError, l)
 		return
 	}
 
-	h.handleNextButton(d, s, i, l)
+	metrics.Verifications.WithLabelValues(metrics.VerificationRemove).Inc()
+	h.recordHistory(scores.HistoryEntry{
+		SID:           d.SID,
+		Action:        scores.HistoryRemoved,
+		Actor:         i.Member.User.ID,
+		PreviousScore: record.RawScore,
+		Score:         record.RawScore,
+	}, l)
+	h.ScheduleLiveLeaderboardUpdate(d.EID)
+	h.Webhooks.Dispatch(i.GuildID, webhook.SubmissionRejected, submissionPayload(record))
+	h.notifySubmitter(s, record.UID, d.SID, d.EventName, "was removed by a moderator, contest it with `/events appeal` if you disagree", l)
+
+	h.handleNextButton(d, s, i, r, l)
 }
@@ -0,0 +1,209 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+// categoryHighlightRows caps how many rows of each category are shown next to combined standings
+const categoryHighlightRows = 5
+
+func (h *EventHandler) categorySubcommands() []*subcommand {
+	modeOption := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "mode",
+		Description: "How submissions are counted: all added up, only the highest or only the lowest",
+		Required:    true,
+	}
+	for _, v := range scores.CategoryModes {
+		modeOption.Choices = append(modeOption.Choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  string(v),
+			Value: string(v),
+		})
+	}
+
+	nameOption := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "name",
+		Description: "Name submitters pick the category by, e.g. `tricap`",
+		Required:    true,
+	}
+
+	return []*subcommand{
+		{
+			Name:        "list",
+			Description: "List the categories of an event",
+			EventID:     optionalEventID,
+			Handler:     h.handleCategoriesList,
+		},
+		{
+			Name:        "set",
+			Description: "Add a category with its own leaderboard to an event, or change the mode of one",
+			Options:     []*discordgo.ApplicationCommandOption{nameOption, modeOption},
+			RoleAction:  manageEventDialog,
+			EventID:     requiredEventID,
+			Handler:     h.handleCategoriesSet,
+		},
+		{
+			Name:        "remove",
+			Description: "Remove a category nobody submitted to from an event",
+			Options:     []*discordgo.ApplicationCommandOption{nameOption},
+			RoleAction:  manageEventDialog,
+			EventID:     requiredEventID,
+			Handler:     h.handleCategoriesRemove,
+		},
+	}
+}
+
+// describeCategories lists the categories of an event for submitters to pick from
+func describeCategories(categories []scores.Category) string {
+	names := make([]string, len(categories))
+	for i, v := range categories {
+		names[i] = "`" + v.Name + "`"
+	}
+	return "this event has the categories " + strings.Join(names, ", ")
+}
+
+// pickCategory finds the category by name, names are not case sensitive
+func pickCategory(name string, categories []scores.Category) (scores.Category, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, v := range categories {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return scores.Category{}, false
+}
+
+func (h *EventHandler) handleCategoriesList(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	categories, err := h.EventScoreService.ListCategories(c.eid)
+	if err != nil {
+		c.logger.Error("could not list categories", zap.Error(err))
+		c.r.errorOrLog("Could not list the categories of the event." + internalError)
+		return
+	}
+
+	if len(categories) == 0 {
+		c.r.replyOrLog(fmt.Sprintf("Event '%s' has no categories", event.Name))
+		return
+	}
+
+	lines := make([]string, len(categories))
+	for i, v := range categories {
+		lines[i] = fmt.Sprintf("`%s` - %s", v.Name, v.Description())
+	}
+
+	c.r.replyOrLog(fmt.Sprintf(
+		"Categories of '%s':\n%s\nPick one when submitting with `%ssubmit <score> category: %s`",
+		event.Name,
+		strings.Join(lines, "\n"),
+		h.guildPrefix(c.i.GuildID),
+		categories[0].Name,
+	))
+}
+
+func (h *EventHandler) handleCategoriesSet(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	if event.Finalized() {
+		c.r.errorOrLog("The results of this event are final, its categories can't change anymore")
+		return
+	}
+
+	name, _ := c.stringOption("name")
+	mode, _ := c.stringOption("mode")
+
+	category := scores.Category{
+		Name: strings.ToLower(strings.TrimSpace(name)),
+		Mode: scores.CategoryMode(mode),
+	}
+	if err := category.Validate(); err != nil {
+		c.r.errorOrLog("Invalid category: " + err.Error())
+		return
+	}
+
+	if err := h.EventScoreService.SetCategory(c.eid, category); err != nil {
+		c.logger.Error("could not set category", zap.Error(err))
+		c.r.errorOrLog("Could not save the category." + internalError)
+		return
+	}
+
+	c.r.replyOrLog(fmt.Sprintf(
+		"Submissions to '%s' can now pick category `%s` (%s), submissions made without a category "+
+			"don't count towards the standings of events with categories",
+		event.Name,
+		category.Name,
+		category.Description(),
+	))
+	h.ScheduleLiveLeaderboardUpdate(c.eid)
+}
+
+func (h *EventHandler) handleCategoriesRemove(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	name, _ := c.stringOption("name")
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	err := h.EventScoreService.DeleteCategory(c.eid, name)
+	if err != nil {
+		switch {
+		case scores.AsErrNoRecord(err):
+			c.r.errorOrLog(fmt.Sprintf("Event '%s' has no category named '%s'", event.Name, name))
+		case errors.Is(err, scores.ErrCategoryInUse):
+			c.r.errorOrLog(fmt.Sprintf(
+				"Submissions were already made for category '%s', reject them first to remove it",
+				name,
+			))
+		default:
+			c.logger.Error("could not delete category", zap.Error(err))
+			c.r.errorOrLog("Could not remove the category." + internalError)
+		}
+		return
+	}
+
+	c.r.replyOrLog(fmt.Sprintf("Removed category `%s` from '%s'", name, event.Name))
+	h.ScheduleLiveLeaderboardUpdate(c.eid)
+}
+
+// categoryHighlights shows the top of each category, for alongside the combined standings
+func categoryHighlights(
+	categories []scores.Category,
+	standings [][]scores.SummaryRecord,
+) []*discordgo.MessageEmbedField {
+	fields := make([]*discordgo.MessageEmbedField, 0, len(categories))
+	for i, v := range categories {
+		lines := []string{}
+		for j, r := range standings[i] {
+			if j == categoryHighlightRows {
+				lines = append(lines, fmt.Sprintf("... and %d more", len(standings[i])-categoryHighlightRows))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("%s - `%s` - %v", rankLabel(standings[i], j), r.IGN, r.Score))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "No verified submissions yet")
+		}
+
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%s (%s)", v.Name, v.Description()),
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+	return fields
+}
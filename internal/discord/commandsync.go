@@ -0,0 +1,108 @@
+package discord
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// CommandDiff is the difference between the locally defined slash commands and the ones
+// discord currently has registered.
+type CommandDiff struct {
+	// Create are local commands discord doesn't know about
+	Create []*discordgo.ApplicationCommand
+	// Update are local commands whose registered version differs, ID is taken from the remote
+	Update []*discordgo.ApplicationCommand
+	// Stale are registered commands that no longer have a local definition
+	Stale []*discordgo.ApplicationCommand
+	// Unchanged are the names of commands that are identical on both sides
+	Unchanged []string
+}
+
+// Empty is true when local and remote definitions are in sync
+func (d *CommandDiff) Empty() bool {
+	return len(d.Create) == 0 && len(d.Update) == 0 && len(d.Stale) == 0
+}
+
+// DiffCommands compares commands by name, and by description and options for the ones present
+// on both sides.
+func DiffCommands(local, remote []*discordgo.ApplicationCommand) *CommandDiff {
+	diff := &CommandDiff{}
+
+	remoteByName := make(map[string]*discordgo.ApplicationCommand, len(remote))
+	for _, v := range remote {
+		remoteByName[v.Name] = v
+	}
+
+	localNames := make(map[string]bool, len(local))
+	for _, v := range local {
+		localNames[v.Name] = true
+
+		r, ok := remoteByName[v.Name]
+		if !ok {
+			diff.Create = append(diff.Create, v)
+			continue
+		}
+
+		if commandSignature(v) == commandSignature(r) {
+			diff.Unchanged = append(diff.Unchanged, v.Name)
+			continue
+		}
+
+		updated := *v
+		updated.ID = r.ID
+		diff.Update = append(diff.Update, &updated)
+	}
+
+	for _, v := range remote {
+		if !localNames[v.Name] {
+			diff.Stale = append(diff.Stale, v)
+		}
+	}
+
+	sort.Strings(diff.Unchanged)
+
+	return diff
+}
+
+// commandSignature flattens the user facing parts of a command for comparison, discord fills in
+// IDs and versions on its side so those are left out.
+func commandSignature(c *discordgo.ApplicationCommand) string {
+	type option struct {
+		Type        discordgo.ApplicationCommandOptionType      `json:"type"`
+		Name        string                                      `json:"name"`
+		Description string                                      `json:"description"`
+		Required    bool                                        `json:"required"`
+		Choices     []*discordgo.ApplicationCommandOptionChoice `json:"choices"`
+		Options     []*option                                   `json:"options"`
+	}
+
+	var convert func(opts []*discordgo.ApplicationCommandOption) []*option
+	convert = func(opts []*discordgo.ApplicationCommandOption) []*option {
+		if len(opts) == 0 {
+			return nil
+		}
+		out := make([]*option, len(opts))
+		for i, v := range opts {
+			out[i] = &option{
+				Type:        v.Type,
+				Name:        v.Name,
+				Description: v.Description,
+				Required:    v.Required,
+				Options:     convert(v.Options),
+			}
+			if len(v.Choices) > 0 {
+				out[i].Choices = v.Choices
+			}
+		}
+		return out
+	}
+
+	b, _ := json.Marshal(struct {
+		Description string    `json:"description"`
+		Options     []*option `json:"options"`
+	}{c.Description, convert(c.Options)})
+
+	return string(b)
+}
@@ -0,0 +1,52 @@
+package discord_test
+
+import (
+	"testing"
+
+	"github.com/2785/warframe-assistant/internal/discord"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCommands(t *testing.T) {
+	assert := assert.New(t)
+
+	local := []*discordgo.ApplicationCommand{
+		{Name: "help", Description: "help"},
+		{
+			Name:        "ign",
+			Description: "ign",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "register", Description: "register"},
+			},
+		},
+		{Name: "events", Description: "events"},
+	}
+
+	remote := []*discordgo.ApplicationCommand{
+		{ID: "1", Version: "3", Name: "help", Description: "help"},
+		{ID: "2", Name: "ign", Description: "ign"},
+		{ID: "3", Name: "test", Description: "test"},
+	}
+
+	diff := discord.DiffCommands(local, remote)
+
+	assert.False(diff.Empty())
+	assert.Equal([]string{"help"}, diff.Unchanged)
+
+	if assert.Len(diff.Create, 1) {
+		assert.Equal("events", diff.Create[0].Name)
+	}
+
+	if assert.Len(diff.Update, 1) {
+		assert.Equal("ign", diff.Update[0].Name)
+		assert.Equal("2", diff.Update[0].ID)
+	}
+	assert.Empty(local[1].ID, "local definitions should not be modified")
+
+	if assert.Len(diff.Stale, 1) {
+		assert.Equal("test", diff.Stale[0].Name)
+	}
+
+	assert.True(discord.DiffCommands(local, local).Empty())
+}
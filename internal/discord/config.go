@@ -0,0 +1,196 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/2785/warframe-assistant/internal/settings"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+func (h *EventHandler) configSubcommands() []*subcommand {
+	keyOption := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "setting",
+		Description: "The setting to change",
+		Required:    true,
+	}
+	for _, v := range settings.Keys {
+		keyOption.Choices = append(keyOption.Choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  v.Name,
+			Value: v.Name,
+		})
+	}
+
+	return []*subcommand{
+		{
+			Name:        "view",
+			Description: "Show the settings of this server",
+			RoleAction:  manageEventDialog,
+			Handler:     h.handleConfigView,
+		},
+		{
+			Name:        "set",
+			Description: "Change a setting of this server",
+			Options: []*discordgo.ApplicationCommandOption{
+				keyOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "value",
+					Description: "The new value, see `/config view` for what each setting takes",
+					Required:    true,
+				},
+			},
+			RoleAction: manageEventDialog,
+			Handler:    h.handleConfigSet,
+		},
+		{
+			Name:        "reset",
+			Description: "Reset a setting of this server to its default",
+			Options:     []*discordgo.ApplicationCommandOption{keyOption},
+			RoleAction:  manageEventDialog,
+			Handler:     h.handleConfigReset,
+		},
+	}
+}
+
+// guildSettings returns the settings of the guild, falling back to the defaults if they can't be
+// fetched so a settings outage doesn't take every command down with it.
+func (h *EventHandler) guildSettings(gid string) *settings.Settings {
+	if h.Settings == nil || gid == "" {
+		return &settings.Settings{GID: gid}
+	}
+
+	s, err := h.Settings.GetSettings(gid)
+	if err != nil {
+		h.Logger.Error("could not fetch guild settings", zap.Error(err), WithGuildID(gid))
+		return &settings.Settings{GID: gid}
+	}
+	return s
+}
+
+// guildPrefix is the prefix message commands in the guild start with
+func (h *EventHandler) guildPrefix(gid string) string {
+	if prefix := h.guildSettings(gid).Prefix; prefix != "" {
+		return prefix
+	}
+	return h.Prefix
+}
+
+func (h *EventHandler) settingDisplay(k *settings.Key, s *settings.Settings) string {
+	v := k.Get(s)
+	switch {
+	case v == "" && k.Name == "prefix":
+		return fmt.Sprintf("`%s` (default)", h.Prefix)
+	case v == "":
+		return k.Default + " (default)"
+	case strings.HasSuffix(k.Name, "-channel"):
+		return "<#" + v + ">"
+	case strings.HasSuffix(k.Name, "-channels"):
+		return channelMentions(strings.Split(v, ","))
+	default:
+		return "`" + v + "`"
+	}
+}
+
+func (h *EventHandler) handleConfigView(c *commandContext) {
+	if h.Settings == nil {
+		c.r.errorOrLog("Settings are not enabled on this bot")
+		return
+	}
+
+	s, err := h.Settings.GetSettings(c.i.GuildID)
+	if err != nil {
+		c.logger.Error("could not fetch guild settings", zap.Error(err))
+		c.r.errorOrLog("Could not fetch the settings of this server." + internalError)
+		return
+	}
+
+	fields := make([]*discordgo.MessageEmbedField, len(settings.Keys))
+	for i, v := range settings.Keys {
+		fields[i] = &discordgo.MessageEmbedField{
+			Name:  v.Name,
+			Value: fmt.Sprintf("%s\n*%s*", h.settingDisplay(v, s), v.Description),
+		}
+	}
+
+	err = c.r.Respond(&discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:  "Server settings",
+				Fields: fields,
+				Footer: &discordgo.MessageEmbedFooter{Text: "Change them with /config set, or /config reset"},
+			},
+		},
+		Flags: ephemeralFlag,
+	})
+	if err != nil {
+		c.logger.Error("could not respond to interaction", zap.Error(err))
+	}
+}
+
+func (h *EventHandler) handleConfigSet(c *commandContext) {
+	name, _ := c.stringOption("setting")
+	value, ok := c.stringOption("value")
+	if !ok {
+		c.r.errorOrLog("`value` must be supplied")
+		return
+	}
+
+	h.updateSetting(c, name, value)
+}
+
+func (h *EventHandler) handleConfigReset(c *commandContext) {
+	name, _ := c.stringOption("setting")
+	h.updateSetting(c, name, "")
+}
+
+func (h *EventHandler) updateSetting(c *commandContext, name, value string) {
+	if h.Settings == nil {
+		c.r.errorOrLog("Settings are not enabled on this bot")
+		return
+	}
+
+	k, ok := settings.Lookup(name)
+	if !ok {
+		c.r.errorOrLog(fmt.Sprintf("Unknown setting '%s'", name))
+		return
+	}
+
+	logger := c.logger.With(zap.String("setting", name))
+
+	s, err := h.Settings.GetSettings(c.i.GuildID)
+	if err != nil {
+		logger.Error("could not fetch guild settings", zap.Error(err))
+		c.r.errorOrLog("Could not fetch the settings of this server." + internalError)
+		return
+	}
+
+	if err := k.Set(s, value); err != nil {
+		if iv, ok := settings.AsErrInvalidValue(err); ok {
+			c.r.errorOrLog(iv.M)
+			return
+		}
+		logger.Error("could not set setting", zap.Error(err))
+		c.r.errorOrLog("Could not change the setting." + internalError)
+		return
+	}
+
+	s.GID = c.i.GuildID
+	if err := h.Settings.UpdateSettings(s); err != nil {
+		logger.Error("could not update guild settings", zap.Error(err))
+		c.r.errorOrLog("Could not save the setting." + internalError)
+		return
+	}
+
+	c.r.errorOrLog(fmt.Sprintf("`%s` is now %s", k.Name, h.settingDisplay(k, s)))
+}
+
+func channelMentions(ids []string) string {
+	mentions := make([]string, len(ids))
+	for i, v := range ids {
+		mentions[i] = "<#" + v + ">"
+	}
+	return strings.Join(mentions, ", ")
+}
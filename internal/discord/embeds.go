@@ -16,6 +16,8 @@ const (
 	sidFieldName        = "Submission ID"
 	ignFieldName        = "IGN"
 	scoresFieldName     = "Scores Claimed"
+	breakdownFieldName  = "Score Breakdown"
+	categoryFieldName   = "Category"
 	verifiedByFieldName = "Verified By"
 )
 
@@ -29,6 +31,10 @@ type VerificationDialog struct {
 	Verified    bool
 	VerifiedBy  string
 	URL         string
+	// Breakdown shows how the modifiers of the submission make up the score, empty without any
+	Breakdown string
+	// Category is empty for submissions to events without categories
+	Category string
 }
 
 func (v *VerificationDialog) ToEmbed() *discordgo.MessageEmbed {
@@ -59,6 +65,14 @@ func (v *VerificationDialog) ToEmbed() *discordgo.MessageEmbed {
 		},
 	}
 
+	if v.Category != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: categoryFieldName, Value: v.Category})
+	}
+
+	if v.Breakdown != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: breakdownFieldName, Value: v.Breakdown})
+	}
+
 	if v.Verified {
 		by := "Unknown"
 		if v.VerifiedBy != "" {
@@ -134,6 +148,8 @@ func FromEmbed(embeds []*discordgo.MessageEmbed) (*VerificationDialog, error) {
 		URL:         url,
 		EID:         eid,
 		EventName:   eName,
+		Breakdown:   fieldMap[breakdownFieldName],
+		Category:    fieldMap[categoryFieldName],
 	}
 
 	if verifiedBy, ok := fieldMap[verifiedByFieldName]; ok {
@@ -4,18 +4,47 @@ import (
 	"github.com/2785/warframe-assistant/internal/cache"
 	"github.com/2785/warframe-assistant/internal/meta"
 	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/settings"
+	"github.com/2785/warframe-assistant/internal/webhook"
 	"github.com/bwmarrin/discordgo"
 	"go.uber.org/zap"
 )
 
 type EventHandler struct {
-	Cache                     cache.Cache
-	Logger                    *zap.Logger
-	Prefix                    string
-	EventScoreService         scores.ScoresService
-	MetadataService           meta.Service
-	InteractionCreateHandlers map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate)
-	Commands                  []*discordgo.ApplicationCommand
+	Cache  cache.Cache
+	Logger *zap.Logger
+	// Prefix is the default prefix of message commands, guilds can set their own
+	Prefix            string
+	EventScoreService scores.ScoresService
+	MetadataService   meta.Service
+	Settings          settings.Service
+	Members           *MemberService
+	// Dashboard hands out login links to the moderation dashboard, nil if it's not served
+	Dashboard DashboardLinker
+	// Webhooks sends lifecycle updates to the webhooks of each guild, nil disables them
+	Webhooks *webhook.Dispatcher
+	// Coordinator hands background jobs to the replica elected to run them, nil if this is the
+	// only replica
+	Coordinator Coordinator
+	Commands    []*discordgo.ApplicationCommand
+
+	router           *commandRouter
+	liveLeaderboards *liveLeaderboardUpdater
+	inflight         inflight
+	// stopScheduler stops the background jobs, nil if they weren't started
+	stopScheduler func()
+}
+
+// DashboardLinker creates one time login links to the moderation dashboard
+type DashboardLinker interface {
+	LoginURL(uid, gid string) (string, error)
+}
+
+// Coordinator decides which replica runs the background jobs, jobs published on other replicas
+// are handed to it
+type Coordinator interface {
+	IsLeader() bool
+	Publish(topic, payload string) error
 }
 
 type dialogType string
@@ -24,12 +53,21 @@ const (
 	verificationDialog dialogType = dialogType("verification")
 	submissionDialog   dialogType = dialogType("submission")
 	manageEventDialog  dialogType = dialogType("manage-event")
+	// appealDialog resolves appeals against the decisions of moderators, servers usually give it
+	// to a higher role than verification
+	appealDialog dialogType = dialogType("appeal")
 )
 
+// roleFallbacks are the actions whose roles are required, in order, for actions the guild didn't
+// set up a role for. Servers set up before an action existed don't open it up to everyone.
+var roleFallbacks = map[dialogType][]dialogType{
+	appealDialog: {verificationDialog, manageEventDialog},
+}
+
 const (
-	eventTypeTournament       string = "tournament"
-	eventTypeScoreCampaign    string = "scoreboard-campaign"
-	eventTypeScoreLeaderboard string = "scoreboard-leaderboard"
+	eventTypeTournament       = meta.EventTypeTournament
+	eventTypeScoreCampaign    = meta.EventTypeScoreCampaign
+	eventTypeScoreLeaderboard = meta.EventTypeScoreLeaderboard
 )
 
 var supportedEventTypes = []string{eventTypeScoreCampaign, eventTypeScoreLeaderboard, eventTypeTournament}
@@ -0,0 +1,1076 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/dates"
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/metrics"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/webhook"
+	"github.com/bwmarrin/discordgo"
+	"github.com/thoas/go-funk"
+	"go.uber.org/zap"
+)
+
+func (h *EventHandler) eventSubcommands() []*subcommand {
+	return []*subcommand{
+		{
+			Name:        "list",
+			Description: "List events in this guild",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "print-all",
+					Description: "If all events should be printed",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "archived",
+					Description: "List the archived events instead",
+				},
+			},
+			Handler: h.handleEventsList,
+		},
+		{
+			Name:        "create",
+			Description: "Create a new event",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "Name of the new event",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "end-date",
+					Description: "End date, e.g. `2022-07-01 20:00` or `next friday 20:00`, in the server timezone",
+					Required:    true,
+				},
+				{
+					Type: discordgo.ApplicationCommandOptionString,
+					Name: "type",
+					Description: fmt.Sprintf(
+						"Type of the new event, supported types: %s",
+						strings.Join(supportedEventTypes, ", "),
+					),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "start-date",
+					Description: "Start date, e.g. `2022-07-01 20:00` or `in 3d`, defaults to now",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "active",
+					Description: "If the event is created as an active event, defaults to yes",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tie-break",
+					Description: "How users with the same score are ranked, defaults to sharing the rank",
+					Choices:     tieBreakChoices(),
+				},
+			},
+			RoleAction: manageEventDialog,
+			Handler:    h.handleEventsCreate,
+		},
+		{
+			Name:        "edit",
+			Description: "Change an event, only the given fields are changed",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "New name of the event",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "type",
+					Description: "New type of the event",
+					Choices:     eventTypeChoices(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "start-date",
+					Description: "New start date, e.g. `2022-07-01 20:00` or `in 3d`, in the server timezone",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "end-date",
+					Description: "New end date, participants are told about the change",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "active",
+					Description: "If the event is active",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tie-break",
+					Description: "How users with the same score are ranked",
+					Choices:     tieBreakChoices(),
+				},
+			},
+			RoleAction: manageEventDialog,
+			EventID:    requiredEventID,
+			Handler:    h.handleEventsEdit,
+		},
+		{
+			Name:        "join",
+			Description: "Join the active event if ID unspecified, else join the specified event",
+			NeedsIGN:    true,
+			EventID:     optionalEventID,
+			Handler:     h.handleEventsJoin,
+		},
+		{
+			Name:        "purge-participation",
+			Description: "Purge all participation records regarding the active event",
+			NeedsIGN:    true,
+			EventID:     optionalEventID,
+			Handler:     h.handleEventsPurgeParticipation,
+		},
+		{
+			Name:        "bail",
+			Description: "Quit the active event if ID unspecified, else quit the specified event",
+			NeedsIGN:    true,
+			EventID:     optionalEventID,
+			Handler:     h.handleEventsBail,
+		},
+		{
+			Name:        "my-submissions",
+			Description: "List your submissions to the active event if ID unspecified, with their status and your rank",
+			EventID:     optionalEventID,
+			Handler:     h.handleEventsMySubmissions,
+		},
+		{
+			Name:        "withdraw",
+			Description: "Take back one of your submissions that is not verified yet",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "submission-id",
+					Description: "The ID of the submission, see `/events my-submissions`",
+					Required:    true,
+				},
+			},
+			Handler: h.handleEventsWithdraw,
+		},
+		h.appealSubcommand(),
+		{
+			Name:        "activate",
+			Description: "Activate a specified event by ID",
+			RoleAction:  manageEventDialog,
+			EventID:     requiredEventID,
+			Handler:     h.handleEventsSetStatus(true),
+		},
+		{
+			Name:        "deactivate",
+			Description: "Deactivate a specified event by ID",
+			RoleAction:  manageEventDialog,
+			EventID:     requiredEventID,
+			Handler:     h.handleEventsSetStatus(false),
+		},
+		{
+			Name:        "finalize",
+			Description: "Close an event and make its current standings final",
+			RoleAction:  manageEventDialog,
+			EventID:     requiredEventID,
+			Handler:     h.handleEventsFinalize,
+		},
+		{
+			Name:        "archive",
+			Description: "Close an event for good, keeping its final leaderboard",
+			RoleAction:  manageEventDialog,
+			EventID:     requiredEventID,
+			Handler:     h.handleEventsArchive,
+		},
+		{
+			Name:        "delete",
+			Description: "Delete an event along with every participation and submission, asks to confirm first",
+			RoleAction:  manageEventDialog,
+			EventID:     requiredEventID,
+			Handler:     h.handleEventsDelete,
+		},
+		{
+			Name:        "list-participant",
+			Description: "List participants of an event",
+			EventID:     optionalEventID,
+			Handler:     h.handleEventsListParticipant,
+		},
+		{
+			Name:        "progress",
+			Description: "Print the progress of current events",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "format",
+					Description: "How the leaderboard is displayed, defaults to text",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: progressFormatText, Value: progressFormatText},
+						{Name: progressFormatImage, Value: progressFormatImage},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "category",
+					Description: "Show the standings of a single category instead of the combined ones",
+				},
+			},
+			EventID: optionalEventID,
+			Handler: h.handleEventsProgress,
+		},
+		{
+			Name:        "live-leaderboard",
+			Description: "Post a leaderboard message that is kept up to date as submissions are verified",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "remove",
+					Description: "Stop updating the live leaderboard of the event instead",
+				},
+			},
+			RoleAction: manageEventDialog,
+			EventID:    optionalEventID,
+			Handler:    h.handleEventsLiveLeaderboard,
+		},
+		{
+			Name:        "verify",
+			Description: "Trigger the submission verification work flow",
+			RoleAction:  manageEventDialog,
+			EventID:     optionalEventID,
+			Handler:     h.handleEventsVerify,
+		},
+		{
+			Name:        "update-score",
+			Description: "Update the score of a submission and verify the submission",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "submission-id",
+					Description: "The UUID of the submission",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "new-score",
+					Description: "New score before modifiers, the modifiers of the submission are applied to it",
+					Required:    true,
+				},
+			},
+			RoleAction: manageEventDialog,
+			Handler:    h.handleEventsUpdateScore,
+		},
+	}
+}
+
+func (h *EventHandler) handleEventsList(c *commandContext) {
+	var events []*meta.Event
+	var err error
+
+	archived := c.boolOption("archived", false)
+	if archived || c.boolOption("print-all", false) {
+		events, err = h.MetadataService.ListEventsForGuild(c.i.GuildID)
+	} else {
+		events, err = h.MetadataService.ListActiveEventsForGuild(c.i.GuildID)
+	}
+
+	if err != nil {
+		c.logger.Error("could not list all events", zap.Error(err))
+		c.r.errorOrLog("Could not list events." + internalError)
+		return
+	}
+
+	events = funk.Filter(events, func(e *meta.Event) bool {
+		return e.Archived == archived
+	}).([]*meta.Event)
+
+	if len(events) == 0 {
+		c.r.replyOrLog("There are currently no events!")
+		return
+	}
+
+	embeds := make([]*discordgo.MessageEmbed, len(events))
+
+	for i, v := range events {
+		embeds[i] = &discordgo.MessageEmbed{
+			Title: v.Name,
+			Fields: []*discordgo.MessageEmbedField{
+				{Name: "ID", Value: v.ID},
+				{Name: "Start Time", Value: dates.Display(v.Begin)},
+				{Name: "End Time", Value: dates.Display(v.End)},
+				{Name: "Status", Value: func() string {
+					if v.Active {
+						return "Active"
+					} else {
+						return "Inactive"
+					}
+				}()},
+				{Name: "Type", Value: v.EventType},
+			},
+		}
+	}
+
+	err = c.r.Embeds(embeds...)
+
+	if err != nil {
+		c.logger.Error("could not send response to intraction", zap.Error(err))
+		c.r.errorOrLog("Could not list events." + internalError)
+	}
+}
+
+func (h *EventHandler) handleEventsCreate(c *commandContext) {
+	name, nameOk := c.stringOption("name")
+	eType, typeOk := c.stringOption("type")
+	endDates, endDateOk := c.stringOption("end-date")
+
+	if !typeOk {
+		eType = h.guildSettings(c.i.GuildID).DefaultEventType
+		typeOk = eType != ""
+	}
+
+	if !(nameOk && typeOk && endDateOk) {
+		c.r.errorOrLog("Name, type, and end date must be provided, unless a default type is set with `/config set`")
+		return
+	}
+
+	if !funk.Contains(supportedEventTypes, eType) {
+		c.r.errorOrLog(fmt.Sprintf(
+			"Sorry, only the following event types are currently supported: %s",
+			strings.Join(supportedEventTypes, ", "),
+		))
+		return
+	}
+
+	now := time.Now()
+	loc := h.guildSettings(c.i.GuildID).Location()
+
+	endDate, err := dates.Parse(endDates, now, loc)
+	if err != nil {
+		c.r.errorOrLog("Invalid end date: " + err.Error())
+		return
+	}
+
+	startDate := now
+	if startDates, ok := c.stringOption("start-date"); ok {
+		startDate, err = dates.Parse(startDates, now, loc)
+		if err != nil {
+			c.r.errorOrLog("Invalid start date: " + err.Error())
+			return
+		}
+	}
+
+	if !endDate.After(startDate) {
+		c.r.errorOrLog(fmt.Sprintf(
+			"The event would end (%s) before it starts (%s)",
+			dates.Display(endDate),
+			dates.Display(startDate),
+		))
+		return
+	}
+
+	eid, err := h.MetadataService.CreateEvent(
+		name,
+		eType,
+		startDate,
+		endDate,
+		c.i.GuildID,
+		c.boolOption("active", true),
+	)
+	if err != nil {
+		c.logger.Error("could not create event", zap.Error(err))
+		c.r.errorOrLog("Could not create event." + internalError)
+		return
+	}
+
+	if tieBreak, ok := c.stringOption("tie-break"); ok && scores.TieBreak(tieBreak) != scores.TieBreakShared {
+		if err := h.MetadataService.SetEventTieBreak(eid, tieBreak); err != nil {
+			c.logger.Error("could not set tie break", zap.Error(err))
+			c.r.errorOrLog(fmt.Sprintf(
+				"Created event with ID '%s' but could not set its tie break, try again with `/events edit`."+
+					internalError,
+				eid,
+			))
+			h.dispatchEventWebhook(c.i.GuildID, webhook.EventCreated, eid, c.logger)
+			return
+		}
+	}
+
+	c.r.replyOrLog(fmt.Sprintf(
+		"Successfully created event with ID '%s', running from %s until %s",
+		eid,
+		dates.Timestamp(startDate, dates.ShortDateTime),
+		dates.Display(endDate),
+	))
+	h.dispatchEventWebhook(c.i.GuildID, webhook.EventCreated, eid, c.logger)
+}
+
+func eventTypeChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(supportedEventTypes))
+	for i, v := range supportedEventTypes {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{Name: v, Value: v}
+	}
+	return choices
+}
+
+func tieBreakChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(scores.TieBreaks))
+	for i, v := range scores.TieBreaks {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{Name: string(v), Value: string(v)}
+	}
+	return choices
+}
+
+// scoreEventTypes take the same kind of submissions and only differ in how they are counted, so
+// events can switch between them at any time
+var scoreEventTypes = []string{eventTypeScoreCampaign, eventTypeScoreLeaderboard}
+
+func (h *EventHandler) handleEventsEdit(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	updated := *event
+	changes := []string{}
+
+	if name, ok := c.stringOption("name"); ok && name != event.Name {
+		updated.Name = name
+		changes = append(changes, fmt.Sprintf("Name: `%s` -> `%s`", event.Name, name))
+	}
+
+	if eType, ok := c.stringOption("type"); ok && eType != event.EventType {
+		if !funk.ContainsString(supportedEventTypes, eType) {
+			c.r.errorOrLog(fmt.Sprintf(
+				"Sorry, only the following event types are currently supported: %s",
+				strings.Join(supportedEventTypes, ", "),
+			))
+			return
+		}
+
+		if !(funk.ContainsString(scoreEventTypes, event.EventType) && funk.ContainsString(scoreEventTypes, eType)) {
+			total, _, err := h.EventScoreService.VerificationStatus(c.eid)
+			if err != nil {
+				c.logger.Error("could not count submissions", zap.Error(err))
+				c.r.errorOrLog("Could not check the submissions of the event." + internalError)
+				return
+			}
+			if total > 0 {
+				c.r.errorOrLog(fmt.Sprintf(
+					"The event already has %d submissions, which can't be carried over from %s to %s",
+					total,
+					event.EventType,
+					eType,
+				))
+				return
+			}
+		}
+
+		updated.EventType = eType
+		changes = append(changes, fmt.Sprintf("Type: %s -> %s", event.EventType, eType))
+	}
+
+	var err error
+	now := time.Now()
+	loc := h.guildSettings(c.i.GuildID).Location()
+
+	if startDates, ok := c.stringOption("start-date"); ok {
+		updated.Begin, err = dates.Parse(startDates, now, loc)
+		if err != nil {
+			c.r.errorOrLog("Invalid start date: " + err.Error())
+			return
+		}
+		if !updated.Begin.Equal(event.Begin) {
+			changes = append(changes, fmt.Sprintf(
+				"Start: %s -> %s",
+				dates.Timestamp(event.Begin, dates.ShortDateTime),
+				dates.Timestamp(updated.Begin, dates.ShortDateTime),
+			))
+		}
+	}
+
+	if endDates, ok := c.stringOption("end-date"); ok {
+		updated.End, err = dates.Parse(endDates, now, loc)
+		if err != nil {
+			c.r.errorOrLog("Invalid end date: " + err.Error())
+			return
+		}
+		if !updated.End.Equal(event.End) {
+			changes = append(changes, fmt.Sprintf(
+				"End: %s -> %s",
+				dates.Timestamp(event.End, dates.ShortDateTime),
+				dates.Timestamp(updated.End, dates.ShortDateTime),
+			))
+		}
+	}
+
+	updated.Active = c.boolOption("active", event.Active)
+	if updated.Active != event.Active {
+		changes = append(changes, fmt.Sprintf("Active: %t -> %t", event.Active, updated.Active))
+	}
+
+	tieBreak := scores.TieBreak(event.TieBreak).OrDefault()
+	if tb, ok := c.stringOption("tie-break"); ok && scores.TieBreak(tb) != tieBreak {
+		updated.TieBreak = tb
+		changes = append(changes, fmt.Sprintf("Tie break: %s -> %s", tieBreak, tb))
+	}
+
+	if event.Finalized() &&
+		(updated.Active || updated.EventType != event.EventType || updated.TieBreak != event.TieBreak) {
+		c.r.errorOrLog("The results of this event are final, it can't be activated again or change how it's ranked")
+		return
+	}
+
+	if len(changes) == 0 {
+		c.r.errorOrLog("Nothing to change, give the fields to change as options")
+		return
+	}
+
+	if !updated.End.After(updated.Begin) {
+		c.r.errorOrLog(fmt.Sprintf(
+			"The event would end (%s) before it starts (%s)",
+			dates.Display(updated.End),
+			dates.Display(updated.Begin),
+		))
+		return
+	}
+
+	err = h.MetadataService.UpdateEvent(
+		c.eid,
+		updated.Name,
+		updated.EventType,
+		updated.Begin,
+		updated.End,
+		c.i.GuildID,
+		updated.Active,
+	)
+	if err != nil {
+		c.logger.Error("could not update event", zap.Error(err))
+		c.r.errorOrLog("Could not update the event." + internalError)
+		return
+	}
+
+	if updated.TieBreak != event.TieBreak {
+		if err := h.MetadataService.SetEventTieBreak(c.eid, updated.TieBreak); err != nil {
+			c.logger.Error("could not set tie break", zap.Error(err))
+			c.r.errorOrLog("Updated the event but could not change its tie break." + internalError)
+			return
+		}
+	}
+
+	c.r.replyOrLog(fmt.Sprintf("Successfully updated event '%s'\n%s", updated.Name, strings.Join(changes, "\n")))
+
+	h.ScheduleLiveLeaderboardUpdate(c.eid)
+	h.dispatchEventWebhook(c.i.GuildID, webhook.EventUpdated, c.eid, c.logger)
+	if updated.Active != event.Active {
+		if updated.Active {
+			h.dispatchEventWebhook(c.i.GuildID, webhook.EventActivated, c.eid, c.logger)
+			h.notifyParticipants(c.s, c.eid, webhook.EventActivated, c.logger)
+		} else {
+			h.dispatchEventWebhook(c.i.GuildID, webhook.EventClosed, c.eid, c.logger)
+			h.notifyParticipants(c.s, c.eid, webhook.EventClosed, c.logger)
+		}
+	}
+
+	if !updated.End.Equal(event.End) {
+		h.announceDeadlineChange(c, event, &updated)
+	}
+}
+
+// announceDeadlineChange lets the participants of the event know its end date moved
+func (h *EventHandler) announceDeadlineChange(c *commandContext, before, after *meta.Event) {
+	usersIn, _, err := h.MetadataService.ListUserForEvent(after.ID)
+	if err != nil {
+		c.logger.Error("could not list participants", zap.Error(err))
+		return
+	}
+
+	uids := make([]string, 0, len(usersIn))
+	for k := range usersIn {
+		uids = append(uids, k)
+	}
+
+	verb := "extended"
+	if after.End.Before(before.End) {
+		verb = "moved up"
+	}
+
+	h.announce(c.s, c.i.GuildID, c.i.ChannelID, fmt.Sprintf(
+		"The deadline of **%s** was %s from %s to %s",
+		after.Name,
+		verb,
+		dates.Timestamp(before.End, dates.LongDateTime),
+		dates.Display(after.End),
+	), uids, c.logger)
+}
+
+func (h *EventHandler) handleEventsJoin(c *commandContext) {
+	pid, in, err := h.MetadataService.GetParticipation(c.uid(), c.eid)
+	if err != nil {
+		if meta.AsErrNoRecord(err) {
+			_, err := h.MetadataService.AddParticipation(c.uid(), c.eid, true)
+			if err != nil {
+				c.logger.Error("could not add participant to event", zap.Error(err))
+				c.r.errorOrLog("Could not join the event." + internalError)
+				return
+			}
+			c.r.replyOrLog("Successfully joined the event")
+			h.dispatchParticipantWebhook(c, webhook.ParticipantJoined)
+			return
+		}
+
+		c.logger.Error("could not check if user is already in event", zap.Error(err))
+		c.r.errorOrLog("Could not join the event." + internalError)
+		return
+	}
+
+	if in {
+		c.r.errorOrLog("You are already in this event")
+		return
+	}
+
+	err = h.MetadataService.SetParticipation(pid, true)
+	if err != nil {
+		c.logger.Error("could not update participation", zap.Error(err))
+		c.r.errorOrLog("Something went wrong while trying to update participation status." + internalError)
+		return
+	}
+
+	h.ScheduleLiveLeaderboardUpdate(c.eid)
+	c.r.replyOrLog("Successfully updated your participation status")
+	h.dispatchParticipantWebhook(c, webhook.ParticipantJoined)
+}
+
+func (h *EventHandler) handleEventsBail(c *commandContext) {
+	pid, in, err := h.MetadataService.GetParticipation(c.uid(), c.eid)
+	if err != nil {
+		if meta.AsErrNoRecord(err) {
+			_, err := h.MetadataService.AddParticipation(c.uid(), c.eid, false)
+			if err != nil {
+				c.logger.Error("could not add participant status to event", zap.Error(err))
+				c.r.errorOrLog("Could not bail the event." + internalError)
+				return
+			}
+
+			c.r.replyOrLog("Successfully bailed the event")
+			h.dispatchParticipantWebhook(c, webhook.ParticipantBailed)
+			return
+		}
+
+		c.logger.Error("could not check if user is already in event", zap.Error(err))
+		c.r.errorOrLog("Could not bail the event." + internalError)
+		return
+	}
+
+	if !in {
+		c.r.errorOrLog("You have already bailed this event")
+		return
+	}
+
+	err = h.MetadataService.SetParticipation(pid, false)
+	if err != nil {
+		c.logger.Error("could not update participation", zap.Error(err))
+		c.r.errorOrLog("Something went wrong while trying to update participation status." + internalError)
+		return
+	}
+
+	h.ScheduleLiveLeaderboardUpdate(c.eid)
+	c.r.replyOrLog("Successfully updated your participation status")
+	h.dispatchParticipantWebhook(c, webhook.ParticipantBailed)
+}
+
+func (h *EventHandler) handleEventsPurgeParticipation(c *commandContext) {
+	pid, _, err := h.MetadataService.GetParticipation(c.uid(), c.eid)
+	if err != nil {
+		if meta.AsErrNoRecord(err) {
+			c.r.errorOrLog("You are not registered in the event, there's nothing to purge")
+			return
+		}
+		c.logger.Error("could not check if user is in event", zap.Error(err))
+		c.r.errorOrLog("Could not check if you are in the event." + internalError)
+		return
+	}
+
+	err = h.MetadataService.DeleteParticipation(pid)
+	if err != nil {
+		c.logger.Error("could not delete participation", zap.Error(err))
+		c.r.errorOrLog("Could not delete your participation of the event." + internalError)
+		return
+	}
+
+	h.ScheduleLiveLeaderboardUpdate(c.eid)
+	c.r.replyOrLog("Successfully deleted your participation record in the event")
+}
+
+func (h *EventHandler) handleEventsSetStatus(active bool) func(c *commandContext) {
+	return func(c *commandContext) {
+		if active {
+			event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+			if !ok {
+				return
+			}
+			if event.Finalized() {
+				c.r.errorOrLog("The results of this event are final, it can't be activated again")
+				return
+			}
+		}
+
+		err := h.MetadataService.SetEventStatus(c.eid, active)
+		if err != nil {
+			c.logger.Error("could not set event status", zap.Error(err))
+			c.r.errorOrLog("Could not update event status." + internalError)
+			return
+		}
+
+		if active {
+			c.r.replyOrLog("Successfully activated event")
+			h.dispatchEventWebhook(c.i.GuildID, webhook.EventActivated, c.eid, c.logger)
+			h.notifyParticipants(c.s, c.eid, webhook.EventActivated, c.logger)
+		} else {
+			c.r.replyOrLog("Successfully deactivated event")
+			h.dispatchEventWebhook(c.i.GuildID, webhook.EventClosed, c.eid, c.logger)
+			h.notifyParticipants(c.s, c.eid, webhook.EventClosed, c.logger)
+		}
+	}
+}
+
+func (h *EventHandler) handleEventsListParticipant(c *commandContext) {
+	// member lookups below can easily take longer than the interaction deadline
+	if err := c.r.Defer(false); err != nil {
+		c.logger.Error("could not defer interaction response", zap.Error(err))
+	}
+
+	usersIn, usersOut, err := h.MetadataService.ListUserForEvent(c.eid)
+	if err != nil {
+		c.logger.Error("could not list users is in event", zap.Error(err))
+		c.r.errorOrLog("Something went wrong." + internalError)
+		return
+	}
+
+	event, err := h.MetadataService.GetEvent(c.eid)
+	if err != nil {
+		c.logger.Error("could not get event by ID", zap.Error(err))
+		c.r.errorOrLog("Something went wrong." + internalError)
+		return
+	}
+
+	usersInDisp := make([]string, 0, len(usersIn))
+	usersOutDisp := make([]string, 0, len(usersOut))
+
+	namesIn := h.Members.DisplayNames(c.s, c.i.GuildID, usersIn)
+	for k, v := range usersIn {
+		usersInDisp = append(usersInDisp, fmt.Sprintf("%s (`%s`)", namesIn[k], v))
+	}
+
+	namesOut := h.Members.DisplayNames(c.s, c.i.GuildID, usersOut)
+	for k, v := range usersOut {
+		usersOutDisp = append(usersOutDisp, fmt.Sprintf("%s (`%s`)", namesOut[k], v))
+	}
+
+	embeds := []*discordgo.MessageEmbedField{}
+	if len(usersInDisp) > 0 {
+		embeds = append(
+			embeds,
+			&discordgo.MessageEmbedField{
+				Name:  "Joined",
+				Value: strings.Join(usersInDisp, "\n"),
+			},
+		)
+	}
+
+	if len(usersOutDisp) > 0 {
+		embeds = append(
+			embeds,
+			&discordgo.MessageEmbedField{
+				Name:  "Bailed",
+				Value: strings.Join(usersOutDisp, "\n"),
+			},
+		)
+	}
+
+	if len(embeds) == 0 {
+		embeds = append(
+			embeds,
+			&discordgo.MessageEmbedField{
+				Name:  "Nothing",
+				Value: "There's absolutely nothing in this event",
+			},
+		)
+	}
+
+	err = c.r.Embeds(&discordgo.MessageEmbed{
+		Title:  "Event: " + event.Name,
+		Fields: embeds,
+	})
+
+	if err != nil {
+		c.logger.Error("could not send embed", zap.Error(err))
+		c.r.errorOrLog("Something went wrong." + internalError)
+	}
+}
+
+func (h *EventHandler) handleEventsProgress(c *commandContext) {
+	// member lookups below can easily take longer than the interaction deadline
+	if err := c.r.Defer(false); err != nil {
+		c.logger.Error("could not defer interaction response", zap.Error(err))
+	}
+
+	event, err := h.MetadataService.GetEvent(c.eid)
+	if err != nil {
+		c.logger.Error("error fetching event information", zap.Error(err))
+		c.r.replyOrLog("Could not fetch event information." + internalError)
+		return
+	}
+
+	categories, err := h.EventScoreService.ListCategories(c.eid)
+	if err != nil {
+		c.logger.Error("could not list categories", zap.Error(err))
+		c.r.replyOrLog("Could not fetch event information." + internalError)
+		return
+	}
+
+	var leaderboard []scores.SummaryRecord
+	var mode string
+	// ranksKey is what rank changes on rendered leaderboards are tracked under
+	ranksKey := c.eid
+	// standings of every category, shown next to the combined ones
+	var highlights [][]scores.SummaryRecord
+
+	if name, ok := c.stringOption("category"); ok {
+		category, found := pickCategory(name, categories)
+		if !found {
+			if len(categories) == 0 {
+				c.r.replyOrLog(fmt.Sprintf("Event '%s' has no categories", event.Name))
+			} else {
+				c.r.replyOrLog(fmt.Sprintf("Unknown category '%s', %s", name, describeCategories(categories)))
+			}
+			return
+		}
+
+		leaderboard, err = h.EventScoreService.MakeReportCategory(
+			c.eid,
+			category,
+			scores.TieBreak(event.TieBreak).OrDefault(),
+		)
+		mode = fmt.Sprintf("%s - %s", category.Name, category.Description())
+		ranksKey += "/" + category.Name
+	} else {
+		leaderboard, mode, err = h.makeLeaderboard(event)
+		if err == nil && len(categories) > 0 {
+			highlights = make([][]scores.SummaryRecord, len(categories))
+			for i, v := range categories {
+				highlights[i], err = h.EventScoreService.MakeReportCategory(
+					c.eid,
+					v,
+					scores.TieBreak(event.TieBreak).OrDefault(),
+				)
+				if err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err != nil {
+		if errors.Is(err, errUnsupportedLeaderboard) {
+			c.r.replyOrLog("Ay mate progress display for " + event.EventType + " events ain't implemented yet")
+			return
+		}
+		c.logger.Error("could not make leaderboard", zap.Error(err))
+		c.r.replyOrLog("Could not fetch event information." + internalError)
+		return
+	}
+
+	if len(leaderboard) == 0 {
+		c.r.replyOrLog("There's no submissions in this event yet!")
+		return
+	}
+
+	if format, _ := c.stringOption("format"); format == progressFormatImage {
+		img, err := h.renderLeaderboardImage(
+			ranksKey,
+			event.Name,
+			fmt.Sprintf("%s - %d ranked", mode, len(leaderboard)),
+			leaderboard,
+			c.logger,
+		)
+		if err != nil {
+			c.logger.Error("could not render leaderboard", zap.Error(err))
+			c.r.replyOrLog("Could not render the leaderboard." + internalError)
+			return
+		}
+
+		_, err = c.r.Followup(leaderboardImageParams(event.Name, img))
+		if err != nil {
+			c.logger.Error("could not send leaderboard image", zap.Error(err))
+			c.r.replyOrLog("Error fetching event leadboard." + internalError)
+		}
+		return
+	}
+
+	igns := make(map[string]string, len(leaderboard))
+	for _, v := range leaderboard {
+		igns[v.UID] = v.IGN
+	}
+	names := h.Members.DisplayNames(c.s, event.GID, igns)
+
+	fields := make([]string, len(leaderboard))
+	for i, v := range leaderboard {
+		fields[i] = fmt.Sprintf(
+			"%s - %s (`%s`) - %v points",
+			rankLabel(leaderboard, i),
+			names[v.UID],
+			v.IGN,
+			v.Score,
+		)
+	}
+
+	embedFields := []*discordgo.MessageEmbedField{
+		{
+			Name:  "Leaderboard",
+			Value: strings.Join(fields, "\n"),
+		},
+	}
+	if highlights != nil {
+		embedFields = append(embedFields, categoryHighlights(categories, highlights)...)
+	}
+
+	err = c.r.Embeds(&discordgo.MessageEmbed{
+		Title:  fmt.Sprintf("%s (%s)", event.Name, mode),
+		Fields: embedFields,
+	})
+
+	if err != nil {
+		c.logger.Error("could not send embeds", zap.Error(err))
+		c.r.replyOrLog("Error fetching event leadboard." + internalError)
+	}
+}
+
+func (h *EventHandler) handleEventsVerify(c *commandContext) {
+	record, err := h.EventScoreService.GetOneUnverifiedForEvent(c.eid)
+	if err != nil {
+		if scores.AsErrNoRecord(err) {
+			c.r.errorOrLog(":tada: There are no pending submissions to be verified")
+			return
+		}
+		c.logger.Error("could not get unverified submission", zap.Error(err))
+		c.r.errorOrLog("Sorry, something's borked." + internalError)
+		return
+	}
+
+	event, err := h.MetadataService.GetEvent(c.eid)
+	if err != nil {
+		c.logger.Error("could not fetch event information", zap.Error(err))
+		c.r.errorOrLog("Something went wrong." + internalError)
+		return
+	}
+
+	dialog := &VerificationDialog{
+		UserDisplay: h.Members.DisplayName(c.s, c.i.GuildID, record.UID, record.IGN),
+		SID:         record.ID,
+		IGN:         "`" + record.IGN + "`",
+		Score:       fmt.Sprintf("%v", record.Score),
+		Breakdown:   record.Breakdown(),
+		Category:    record.Category,
+		URL:         record.Proof,
+		EID:         c.eid,
+		EventName:   event.Name,
+	}
+
+	err = c.r.Respond(&discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			dialog.ToEmbed(),
+		},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Verify",
+						Style:    discordgo.SuccessButton,
+						CustomID: scoreVerificationBotton,
+					},
+					discordgo.Button{
+						Label:    "Reject",
+						Style:    discordgo.DangerButton,
+						CustomID: scoreRejectButton,
+					},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		c.logger.Error("could not respond to interaction", zap.Error(err))
+	}
+}
+
+func (h *EventHandler) handleEventsUpdateScore(c *commandContext) {
+	sid, ok := c.stringOption("submission-id")
+	if !ok {
+		c.r.errorOrLog("`submission-id` must be supplied")
+		return
+	}
+	sid = strings.TrimSpace(sid)
+
+	logger := c.logger.With(WithSubmissionID(sid))
+
+	newScore, ok := c.intOption("new-score")
+	if !ok {
+		c.r.errorOrLog("`new-score` must be supplied")
+		return
+	}
+
+	// the score before goes in the history, it's what an appeal would restore
+	before, event, ok := h.guildSubmission(c, sid)
+	if !ok {
+		return
+	}
+
+	switch {
+	case before.Withdrawn:
+		c.r.errorOrLog("This submission was withdrawn by its submitter and can't be updated")
+		return
+	case before.Removed:
+		c.r.errorOrLog("This submission was removed, it only comes back if its submitter appeals and the appeal is granted with `/appeals resolve`")
+		return
+	}
+
+	err := h.EventScoreService.UpdateScoreAndVerify(sid, newScore, c.uid())
+	if scores.AsErrNoRecord(err) {
+		c.r.errorOrLog("This submission was just withdrawn and can't be updated")
+		return
+	}
+	if err != nil {
+		logger.Error("could not update score", zap.Error(err))
+		c.r.errorOrLog("Error updating score." + internalError)
+		return
+	}
+
+	metrics.Verifications.WithLabelValues(metrics.VerificationAmend).Inc()
+	h.recordHistory(scores.HistoryEntry{
+		SID:           sid,
+		Action:        scores.HistoryAmended,
+		Actor:         c.uid(),
+		PreviousScore: before.RawScore,
+		Score:         newScore,
+	}, logger)
+	h.ScheduleLiveLeaderboardUpdate(before.EID)
+
+	c.r.replyOrLog("Successfully updated and verified score")
+	h.dispatchSubmissionWebhook(c.i.GuildID, webhook.SubmissionAmended, sid, logger)
+	h.notifySubmitter(
+		c.s,
+		before.UID,
+		sid,
+		event.Name,
+		fmt.Sprintf(
+			"was amended by a moderator from %d to %d, contest it with `/events appeal` if you disagree",
+			before.RawScore,
+			newScore,
+		),
+		logger,
+	)
+}
@@ -0,0 +1,126 @@
+package discord
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// inflight keeps track of the handlers that are currently running, so shutting down can wait for
+// them to finish instead of cutting them off mid way through a DB write or an interaction response.
+// The zero value is ready to use.
+type inflight struct {
+	mu       sync.Mutex
+	draining bool
+	next     uint64
+	running  map[uint64]string
+	wg       sync.WaitGroup
+}
+
+// begin registers a handler, it returns false once draining started and the handler should not
+// run. The returned func must be called when the handler is done.
+func (f *inflight) begin(name string) (func(), bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.draining {
+		return nil, false
+	}
+	return f.add(name), true
+}
+
+// track registers a handler even while draining, for work started by the shutdown itself
+func (f *inflight) track(name string) func() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.add(name)
+}
+
+// add registers a handler, f.mu must be held
+func (f *inflight) add(name string) func() {
+	if f.running == nil {
+		f.running = map[uint64]string{}
+	}
+
+	id := f.next
+	f.next++
+	f.running[id] = name
+	f.wg.Add(1)
+
+	return func() {
+		f.mu.Lock()
+		delete(f.running, id)
+		f.mu.Unlock()
+		f.wg.Done()
+	}
+}
+
+// stop makes begin refuse any new handlers
+func (f *inflight) stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.draining = true
+}
+
+// wait waits for the running handlers, if ctx expires first the names of the handlers still
+// running are returned.
+func (f *inflight) wait(ctx context.Context) []string {
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	abandoned := make([]string, 0, len(f.running))
+	for _, v := range f.running {
+		abandoned = append(abandoned, v)
+	}
+	return abandoned
+}
+
+// Drain stops handling new messages and interactions and the background jobs, flushes pending
+// live leaderboard updates and waits for everything in flight to finish. Anything still running
+// when ctx expires is logged and abandoned, the session can be closed once this returns.
+func (h *EventHandler) Drain(ctx context.Context) error {
+	logger := h.Logger.With(WithComponent("drain"))
+
+	if h.stopScheduler != nil {
+		h.stopScheduler()
+	}
+
+	// pending updates would otherwise be lost along with their timers
+	if h.liveLeaderboards != nil {
+		for _, eid := range h.liveLeaderboards.stop() {
+			done := h.inflight.track("live-leaderboard")
+			go func(eid string) {
+				defer done()
+				h.liveLeaderboards.update(eid)
+			}(eid)
+		}
+	}
+
+	h.inflight.stop()
+	abandoned := h.inflight.wait(ctx)
+
+	if len(abandoned) == 0 {
+		logger.Info("drained in-flight handlers")
+		return nil
+	}
+
+	for _, v := range abandoned {
+		logger.Warn("abandoned in-flight handler", zap.String("handler", v))
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,96 @@
+package discord
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/2785/warframe-assistant/internal/cache"
+	"github.com/2785/warframe-assistant/internal/render"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+const (
+	progressFormatText  = "text"
+	progressFormatImage = "image"
+
+	// leaderboardImageRows caps how many rows go on a rendered leaderboard
+	leaderboardImageRows = 10
+	leaderboardImageName = "leaderboard.png"
+)
+
+// renderLeaderboardImage draws the top of the leaderboard, with rank changes against the ranks
+// recorded the last time a leaderboard with the same key was rendered, the event ID or the event ID
+// and category.
+func (h *EventHandler) renderLeaderboardImage(
+	key, title, subtitle string,
+	leaderboard []scores.SummaryRecord,
+	logger *zap.Logger,
+) (*bytes.Buffer, error) {
+	previous := map[string]int{}
+	err := h.Cache.Get(leaderboardRanksKey(key), &previous)
+	if err != nil && !cache.AsErrNoRecord(err) {
+		logger.Warn("could not fetch previous leaderboard ranks", zap.Error(err))
+	}
+
+	current := make(map[string]int, len(leaderboard))
+	rows := make([]render.LeaderboardRow, 0, leaderboardImageRows)
+	for _, v := range leaderboard {
+		rank := v.Rank
+		current[v.UID] = rank
+
+		if len(rows) == leaderboardImageRows {
+			continue
+		}
+
+		row := render.LeaderboardRow{Rank: rank, Name: v.IGN, Score: v.Score}
+		if prev, ok := previous[v.UID]; ok {
+			row.Delta = prev - rank
+		} else if len(previous) > 0 {
+			row.New = true
+		}
+		rows = append(rows, row)
+	}
+
+	buf := &bytes.Buffer{}
+	err = render.RenderLeaderboard(buf, &render.Leaderboard{
+		Title:    title,
+		Subtitle: subtitle,
+		Rows:     rows,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.Cache.Set(leaderboardRanksKey(key), current)
+	if err != nil {
+		logger.Warn("could not record leaderboard ranks", zap.Error(err))
+	}
+
+	return buf, nil
+}
+
+func leaderboardRanksKey(key string) string {
+	return "leaderboard-ranks:" + key
+}
+
+func leaderboardImageParams(title string, img *bytes.Buffer) *discordgo.WebhookParams {
+	return &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title: title,
+				Image: &discordgo.MessageEmbedImage{
+					URL: fmt.Sprintf("attachment://%s", leaderboardImageName),
+				},
+			},
+		},
+		Files: []*discordgo.File{
+			{
+				Name:        leaderboardImageName,
+				ContentType: "image/png",
+				Reader:      img,
+			},
+		},
+	}
+}
@@ -0,0 +1,304 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+const (
+	// liveLeaderboardDebounce is how long a live leaderboard waits for further changes before
+	// it gets edited, so going through a verification backlog doesn't edit it on every click
+	liveLeaderboardDebounce = 10 * time.Second
+	// liveLeaderboardRows caps how many rows go on a live leaderboard
+	liveLeaderboardRows = 25
+	// LiveLeaderboardTopic is the coordinator topic live leaderboard updates are published on when
+	// another replica is the leader, the payload is the event ID
+	LiveLeaderboardTopic = "live-leaderboard"
+)
+
+var errUnsupportedLeaderboard = errors.New("leaderboard not supported for event type")
+
+// makeLeaderboard builds the leaderboard of the event with standings, along with a short
+// description of how the scores are counted. Finalized events use the snapshot taken when they were
+// finalized.
+func (h *EventHandler) makeLeaderboard(event *meta.Event) ([]scores.SummaryRecord, string, error) {
+	if event.Finalized() {
+		_, leaderboard, err := h.EventScoreService.GetResults(event.ID)
+		if scores.AsErrNoRecord(err) {
+			// nobody was ranked when the event was finalized
+			return []scores.SummaryRecord{}, "Final results", nil
+		}
+		return leaderboard, "Final results", err
+	}
+
+	leaderboard, _, description, err := h.standings(event)
+	return leaderboard, description, err
+}
+
+// standings ranks the event as it stands now according to its type, or combines the standings of
+// its categories if it has any, returning the mode along with its description
+func (h *EventHandler) standings(
+	event *meta.Event,
+) ([]scores.SummaryRecord, scores.LeaderboardMode, string, error) {
+	tieBreak := scores.TieBreak(event.TieBreak).OrDefault()
+
+	categories, err := h.EventScoreService.ListCategories(event.ID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if len(categories) > 0 {
+		leaderboard, err := h.EventScoreService.MakeReportCombined(event.ID, tieBreak)
+		return leaderboard, scores.LeaderboardCombined, fmt.Sprintf("Combined over %d categories", len(categories)), err
+	}
+
+	switch event.EventType {
+	case eventTypeScoreCampaign:
+		leaderboard, err := h.EventScoreService.MakeReportScoreSum(event.ID, tieBreak)
+		return leaderboard, scores.LeaderboardSum, "Accumulative", err
+	case eventTypeScoreLeaderboard:
+		leaderboard, err := h.EventScoreService.MakeReportScoreTop(event.ID, tieBreak)
+		return leaderboard, scores.LeaderboardTop, "Only best score counts", err
+	default:
+		return nil, "", "", fmt.Errorf("%w: %s", errUnsupportedLeaderboard, event.EventType)
+	}
+}
+
+// rankLabel is the rank of the i-th user on the leaderboard, marked when others share it
+func rankLabel(leaderboard []scores.SummaryRecord, i int) string {
+	rank := leaderboard[i].Rank
+	// the leaderboard is in rank order, anyone sharing the rank is right next to the user
+	if (i > 0 && leaderboard[i-1].Rank == rank) || (i+1 < len(leaderboard) && leaderboard[i+1].Rank == rank) {
+		return fmt.Sprintf("#%d (tie)", rank)
+	}
+	return fmt.Sprintf("#%d", rank)
+}
+
+// liveLeaderboardEmbed only uses IGNs so it can be rebuilt without any member lookups
+func liveLeaderboardEmbed(event *meta.Event, mode string, leaderboard []scores.SummaryRecord) *discordgo.MessageEmbed {
+	lines := make([]string, 0, liveLeaderboardRows)
+	for i, v := range leaderboard {
+		if i == liveLeaderboardRows {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(leaderboard)-liveLeaderboardRows))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s - `%s` - %v points", rankLabel(leaderboard, i), v.IGN, v.Score))
+	}
+
+	description := strings.Join(lines, "\n")
+	if len(leaderboard) == 0 {
+		description = "There's no verified submissions in this event yet!"
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s (%s)", event.Name, mode),
+		Description: description,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Live leaderboard, updated as submissions are verified",
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
+// liveLeaderboardUpdater debounces live leaderboard updates per event, an update only goes out
+// once nothing else has been scheduled for the same event within the delay.
+type liveLeaderboardUpdater struct {
+	delay  time.Duration
+	update func(eid string)
+	// begin is called before an update runs, updates are skipped when it returns false
+	begin func() (func(), bool)
+
+	mu      sync.Mutex
+	timers  map[string]*time.Timer
+	stopped bool
+}
+
+func newLiveLeaderboardUpdater(
+	delay time.Duration,
+	begin func() (func(), bool),
+	update func(eid string),
+) *liveLeaderboardUpdater {
+	return &liveLeaderboardUpdater{
+		delay:  delay,
+		update: update,
+		begin:  begin,
+		timers: map[string]*time.Timer{},
+	}
+}
+
+func (u *liveLeaderboardUpdater) schedule(eid string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.stopped {
+		return
+	}
+
+	if t, ok := u.timers[eid]; ok && t.Stop() {
+		t.Reset(u.delay)
+		return
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(u.delay, func() {
+		u.mu.Lock()
+		// a newer timer might have taken this one's place if it fired while being rescheduled
+		if u.timers[eid] == t {
+			delete(u.timers, eid)
+		}
+		// once stopped, whoever stopped the updater is responsible for the pending updates
+		if u.stopped {
+			u.mu.Unlock()
+			return
+		}
+		done, ok := u.begin()
+		u.mu.Unlock()
+
+		if !ok {
+			return
+		}
+		defer done()
+		u.update(eid)
+	})
+	u.timers[eid] = t
+}
+
+// stop cancels every scheduled update and returns the events they were for, nothing gets scheduled
+// afterwards.
+func (u *liveLeaderboardUpdater) stop() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.stopped = true
+	pending := make([]string, 0, len(u.timers))
+	for eid, t := range u.timers {
+		t.Stop()
+		pending = append(pending, eid)
+	}
+	u.timers = map[string]*time.Timer{}
+
+	return pending
+}
+
+// ScheduleLiveLeaderboardUpdate marks the live leaderboard of the event as outdated, it is a no-op
+// for events without one. Updates only run on the leader, other replicas pass them on.
+func (h *EventHandler) ScheduleLiveLeaderboardUpdate(eid string) {
+	if h.liveLeaderboards == nil || eid == "" {
+		return
+	}
+
+	if h.Coordinator != nil && !h.Coordinator.IsLeader() {
+		if err := h.Coordinator.Publish(LiveLeaderboardTopic, eid); err != nil {
+			h.Logger.Error(
+				"could not hand live leaderboard update to the leader",
+				zap.Error(err),
+				WithComponent("live-leaderboard"),
+				WithEventID(eid),
+			)
+		}
+		return
+	}
+
+	h.liveLeaderboards.schedule(eid)
+}
+
+func (h *EventHandler) updateLiveLeaderboard(s *discordgo.Session, eid string) {
+	logger := h.Logger.With(WithComponent("live-leaderboard"), WithEventID(eid))
+
+	ll, err := h.MetadataService.GetLiveLeaderboard(eid)
+	if err != nil {
+		if !meta.AsErrNoRecord(err) {
+			logger.Error("could not fetch live leaderboard", zap.Error(err))
+		}
+		return
+	}
+
+	logger = logger.With(WithChannelID(ll.ChannelID), WithMessageID(ll.MessageID))
+
+	event, err := h.MetadataService.GetEvent(eid)
+	if err != nil {
+		logger.Error("could not fetch event information", zap.Error(err))
+		return
+	}
+
+	leaderboard, mode, err := h.makeLeaderboard(event)
+	if err != nil {
+		logger.Error("could not make leaderboard", zap.Error(err))
+		return
+	}
+
+	_, err = s.ChannelMessageEditEmbed(ll.ChannelID, ll.MessageID, liveLeaderboardEmbed(event, mode, leaderboard))
+	if err != nil {
+		restErr := &discordgo.RESTError{}
+		if errors.As(err, &restErr) && restErr.Response != nil &&
+			restErr.Response.StatusCode == http.StatusNotFound {
+			// the message is gone, no point in trying to keep it up to date
+			logger.Info("live leaderboard message was deleted, forgetting it")
+			if err := h.MetadataService.DeleteLiveLeaderboard(eid); err != nil {
+				logger.Error("could not delete live leaderboard", zap.Error(err))
+			}
+			return
+		}
+		logger.Error("could not edit live leaderboard", zap.Error(err))
+	}
+}
+
+func (h *EventHandler) handleEventsLiveLeaderboard(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	if c.boolOption("remove", false) {
+		err := h.MetadataService.DeleteLiveLeaderboard(c.eid)
+		if err != nil {
+			c.logger.Error("could not delete live leaderboard", zap.Error(err))
+			c.r.errorOrLog("Could not remove the live leaderboard." + internalError)
+			return
+		}
+		c.r.errorOrLog(fmt.Sprintf("The live leaderboard of '%s' will no longer be updated", event.Name))
+		return
+	}
+
+	leaderboard, mode, err := h.makeLeaderboard(event)
+	if err != nil {
+		if errors.Is(err, errUnsupportedLeaderboard) {
+			c.r.errorOrLog("Ay mate live leaderboards for this event type ain't implemented yet")
+			return
+		}
+		c.logger.Error("could not make leaderboard", zap.Error(err))
+		c.r.errorOrLog("Could not fetch event information." + internalError)
+		return
+	}
+
+	msg, err := c.s.ChannelMessageSendEmbed(c.i.ChannelID, liveLeaderboardEmbed(event, mode, leaderboard))
+	if err != nil {
+		c.logger.Error("could not send live leaderboard", zap.Error(err))
+		c.r.errorOrLog("Could not post the live leaderboard, make sure I can post in this channel." + internalError)
+		return
+	}
+
+	err = h.MetadataService.SetLiveLeaderboard(c.eid, msg.ChannelID, msg.ID)
+	if err != nil {
+		c.logger.Error("could not save live leaderboard", zap.Error(err))
+		c.r.errorOrLog("Could not save the live leaderboard." + internalError)
+		return
+	}
+
+	if err := c.s.ChannelMessagePin(msg.ChannelID, msg.ID); err != nil {
+		c.logger.Warn("could not pin live leaderboard", zap.Error(err), WithMessageID(msg.ID))
+	}
+
+	c.r.errorOrLog(
+		"Live leaderboard posted, it will be kept up to date as submissions are verified. " +
+			"Any previous live leaderboard of this event will no longer be updated.",
+	)
+}
@@ -0,0 +1,211 @@
+package discord
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/2785/warframe-assistant/internal/cache"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+const (
+	// memberLookupConcurrency caps how many single member lookups run against the discord API at
+	// once
+	memberLookupConcurrency = 8
+	// memberBatchThreshold is how many members have to be missing before they are looked for in
+	// pages of the member list instead of one at a time
+	memberBatchThreshold = 10
+	// memberPageSize is the most members discord lists per request
+	memberPageSize = 1000
+	// maxMemberPages bounds the pages read for a batch in large guilds, members not found by then
+	// are looked up one at a time
+	maxMemberPages = 10
+)
+
+// MemberService resolves user IDs to guild display names for anything that lists members, so
+// leaderboards and participant lists don't hit the discord API once per row every time. Members
+// who left the guild, or who couldn't be looked up at all, are shown with their IGN instead.
+type MemberService struct {
+	c      cache.Cache
+	logger *zap.Logger
+}
+
+func NewMemberService(c cache.Cache, logger *zap.Logger) *MemberService {
+	return &MemberService{c, logger}
+}
+
+// DisplayName resolves a single user, ign is used if the user is no longer in the guild.
+func (m *MemberService) DisplayName(s *discordgo.Session, gid, uid, ign string) string {
+	return m.DisplayNames(s, gid, map[string]string{uid: ign})[uid]
+}
+
+// DisplayNames resolves every user in igns, a mapping of user ID to IGN, and returns a mapping of
+// user ID to display name. Users are looked up in the session state and the cache first. When
+// enough are left they are looked for in pages of the guild's member list, which takes one request
+// per thousand members of the guild, and whatever is still missing is fetched one by one
+// concurrently.
+func (m *MemberService) DisplayNames(s *discordgo.Session, gid string, igns map[string]string) map[string]string {
+	names := make(map[string]string, len(igns))
+	missing := make([]string, 0, len(igns))
+
+	for uid, ign := range igns {
+		if member, err := s.State.Member(gid, uid); err == nil {
+			names[uid] = formatMember(member)
+			continue
+		}
+
+		name := ""
+		err := m.c.Get(memberCacheKey(gid, uid), &name)
+		if err == nil {
+			names[uid] = fallbackName(name, ign)
+			continue
+		}
+		if !cache.AsErrNoRecord(err) {
+			m.logger.Warn("could not get member from cache", zap.Error(err), WithUserID(uid))
+		}
+
+		missing = append(missing, uid)
+	}
+
+	if len(missing) >= memberBatchThreshold {
+		missing = m.fetchDisplayNamesPaged(s, gid, missing, names, igns)
+	}
+
+	if len(missing) == 0 {
+		return names
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, memberLookupConcurrency)
+
+	for _, uid := range missing {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(uid string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			name := m.fetchDisplayName(s, gid, uid)
+
+			mu.Lock()
+			names[uid] = fallbackName(name, igns[uid])
+			mu.Unlock()
+		}(uid)
+	}
+
+	wg.Wait()
+	return names
+}
+
+// fetchDisplayNamesPaged pages through the member list of the guild until every missing user is
+// found, filling in names and returning the users that still have to be looked up one by one.
+// Listing members needs the server members intent, without it every user is left to be looked up.
+func (m *MemberService) fetchDisplayNamesPaged(
+	s *discordgo.Session,
+	gid string,
+	missing []string,
+	names, igns map[string]string,
+) []string {
+	logger := m.logger.With(WithGuildID(gid))
+
+	wanted := make(map[string]bool, len(missing))
+	for _, uid := range missing {
+		wanted[uid] = true
+	}
+
+	after := ""
+	for page := 0; page < maxMemberPages && len(wanted) > 0; page++ {
+		members, err := s.GuildMembers(gid, after, memberPageSize)
+		if err != nil {
+			logger.Warn("could not list members, looking them up one by one", zap.Error(err))
+			return keys(wanted)
+		}
+
+		for _, member := range members {
+			if !wanted[member.User.ID] {
+				continue
+			}
+			delete(wanted, member.User.ID)
+			names[member.User.ID] = m.cacheDisplayName(gid, member.User.ID, formatMember(member))
+		}
+
+		if len(members) < memberPageSize {
+			// the whole guild was listed, whoever is left isn't in it anymore
+			for uid := range wanted {
+				names[uid] = fallbackName(m.cacheDisplayName(gid, uid, ""), igns[uid])
+			}
+			return nil
+		}
+		after = members[len(members)-1].User.ID
+	}
+
+	return keys(wanted)
+}
+
+func keys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	return out
+}
+
+// fetchDisplayName returns an empty string for users that are not in the guild, that is cached
+// as well so departed members don't get looked up over and over.
+func (m *MemberService) fetchDisplayName(s *discordgo.Session, gid, uid string) string {
+	logger := m.logger.With(WithGuildID(gid), WithUserID(uid))
+
+	member, err := s.GuildMember(gid, uid)
+	if err != nil {
+		if !isUnknownMember(err) {
+			// could be anything, fall back for now but don't remember it
+			logger.Warn("could not fetch member", zap.Error(err))
+			return ""
+		}
+		logger.Debug("member not in guild, falling back to IGN")
+	}
+
+	name := ""
+	if member != nil {
+		name = formatMember(member)
+	}
+
+	return m.cacheDisplayName(gid, uid, name)
+}
+
+// cacheDisplayName remembers the name of the member, empty for users that are not in the guild
+func (m *MemberService) cacheDisplayName(gid, uid, name string) string {
+	if err := m.c.Set(memberCacheKey(gid, uid), name); err != nil {
+		m.logger.Warn("could not cache member display name", zap.Error(err), WithGuildID(gid), WithUserID(uid))
+	}
+
+	return name
+}
+
+func isUnknownMember(err error) bool {
+	restErr := &discordgo.RESTError{}
+	if !errors.As(err, &restErr) {
+		return false
+	}
+	if restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownMember {
+		return true
+	}
+	return restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound
+}
+
+func fallbackName(name, ign string) string {
+	if name == "" {
+		return ign
+	}
+	return name
+}
+
+func memberCacheKey(gid, uid string) string {
+	return gid + ":" + uid
+}
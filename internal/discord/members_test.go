@@ -0,0 +1,47 @@
+package discord_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/cache"
+	"github.com/2785/warframe-assistant/internal/discord"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMemberDisplayNames(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	s := &discordgo.Session{State: discordgo.NewState()}
+	require.NoError(s.State.GuildAdd(&discordgo.Guild{ID: "guild-1"}))
+	require.NoError(s.State.MemberAdd(&discordgo.Member{
+		GuildID: "guild-1",
+		Nick:    "nick-in-state",
+		User:    &discordgo.User{ID: "user-1", Username: "user-1"},
+	}))
+
+	c := cache.Named("members", cache.NewMemory(time.Minute))
+	require.NoError(c.Set("guild-1:user-2", "cached-name"))
+	// departed members are cached with an empty name
+	require.NoError(c.Set("guild-1:user-3", ""))
+
+	m := discord.NewMemberService(c, zap.NewNop())
+
+	names := m.DisplayNames(s, "guild-1", map[string]string{
+		"user-1": "ign-1",
+		"user-2": "ign-2",
+		"user-3": "ign-3",
+	})
+
+	assert.Equal(map[string]string{
+		"user-1": "nick-in-state",
+		"user-2": "cached-name",
+		"user-3": "ign-3",
+	}, names)
+
+	assert.Equal("cached-name", m.DisplayName(s, "guild-1", "user-2", "ign-2"))
+}
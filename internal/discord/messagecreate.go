@@ -1,12 +1,18 @@
 package discord
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/2785/warframe-assistant/internal/dates"
+	"github.com/2785/warframe-assistant/internal/metrics"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/settings"
+	"github.com/2785/warframe-assistant/internal/webhook"
 	"github.com/bwmarrin/discordgo"
 	"go.uber.org/zap"
 )
@@ -20,21 +26,29 @@ func (h *EventHandler) HandleMessageCreate(s *discordgo.Session, m *discordgo.Me
 		return
 	}
 
-	if !strings.HasPrefix(m.Content, h.Prefix) {
+	prefix := h.guildPrefix(m.GuildID)
+	if !strings.HasPrefix(m.Content, prefix) {
 		return
 	}
 
-	msg := strings.TrimSpace(strings.TrimPrefix(m.Content, h.Prefix))
+	msg := strings.TrimSpace(strings.TrimPrefix(m.Content, prefix))
 
 	cmd := strings.Split(msg, " ")[0]
 
 	msg = strings.TrimPrefix(msg, cmd)
 
+	done, ok := h.inflight.begin("message:" + strings.ToLower(cmd))
+	if !ok {
+		// commands sent while restarting are dropped, they can simply be sent again once the bot is back
+		return
+	}
+	defer done()
+
 	switch strings.ToLower(cmd) {
 	case "ping":
 		h.handlePing(s, m)
 	case "submit":
-		h.handleSubmitScore(s, m, msg)
+		h.handleSubmitScore(s, m, prefix, msg)
 	default:
 		h.handleUnknownCmd(s, m, cmd)
 	}
@@ -58,11 +72,14 @@ func (h *EventHandler) handleUnknownCmd(
 	}
 }
 
-var submitScoreRe = regexp.MustCompile(`(?i)\s*(?P<score>\d+)(\s*event:\s*(?P<event>\S+))?`)
+var submitScoreRe = regexp.MustCompile(
+	`(?i)\s*(?P<score>\d+)(\s*event:\s*(?P<event>\S+))?(\s*category:\s*(?P<category>\S+))?(\s*mods:\s*(?P<mods>.+))?`,
+)
 
 func (h *EventHandler) handleSubmitScore(
 	s *discordgo.Session,
 	m *discordgo.MessageCreate,
+	prefix string,
 	text string,
 ) {
 	logger := h.Logger.With(
@@ -72,12 +89,29 @@ func (h *EventHandler) handleSubmitScore(
 		WithUserID(m.Author.ID),
 		WithHandler("handle-submit-score"),
 	)
+
+	// anything that returns early without setting this is a submission we turned down
+	result := metrics.SubmissionRejected
+	defer func(start time.Time) {
+		metrics.Submissions.WithLabelValues(result).Inc()
+		metrics.SubmissionDuration.Observe(time.Since(start).Seconds())
+	}(time.Now())
+
 	replier := messageReplier(s, m.GuildID, m.ChannelID, m.ID)
 	fixInputMsg := fmt.Sprintf(
 		"Could not understand the input, please make your submission in the format `%ssubmit ign: <your-ign> score: <your score>`, without the angle brackets",
-		h.Prefix,
+		prefix,
 	)
 
+	cfg := h.guildSettings(m.GuildID)
+	if !cfg.AcceptsSubmissionsIn(m.ChannelID) {
+		replyWithErrorLogging(replier, fmt.Sprintf(
+			"Submissions are only taken in %s, please submit your score there",
+			channelMentions(cfg.SubmissionChannelIDs()),
+		), logger)
+		return
+	}
+
 	// first we'll see if we can get the event ID
 	match := submitScoreRe.FindStringSubmatch(text)
 
@@ -104,12 +138,18 @@ func (h *EventHandler) handleSubmitScore(
 
 	event, err := h.MetadataService.GetEvent(eid)
 	if err != nil {
+		result = metrics.SubmissionError
 		replyWithErrorLogging(replier, "Error fetching event information."+internalError, logger)
 		return
 	}
 
+	if event.Finalized() {
+		replyWithErrorLogging(replier, "Event is over, its results are final", logger)
+		return
+	}
+
 	if event.Begin.After(time.Now()) {
-		replyWithErrorLogging(replier, "Event is not open yet", logger)
+		replyWithErrorLogging(replier, "Event is not open yet, it opens "+dates.Timestamp(event.Begin, dates.Relative), logger)
 		return
 	}
 
@@ -132,6 +172,57 @@ func (h *EventHandler) handleSubmitScore(
 		return
 	}
 
+	categories, err := h.EventScoreService.ListCategories(eid)
+	if err != nil {
+		logger.Error("could not list categories", zap.Error(err))
+		result = metrics.SubmissionError
+		replyWithErrorLogging(replier, "Error fetching the categories of the event."+internalError, logger)
+		return
+	}
+
+	category := ""
+	categoryName := strings.TrimSpace(inputMap["category"])
+	switch {
+	case len(categories) == 0 && categoryName != "":
+		replyWithErrorLogging(replier, "This event has no categories, submit without `category:`", logger)
+		return
+	case len(categories) > 0 && categoryName == "":
+		replyWithErrorLogging(replier, fmt.Sprintf(
+			"Pick the category of your submission with `%ssubmit <score> category: <category>`, %s",
+			prefix,
+			describeCategories(categories),
+		), logger)
+		return
+	case len(categories) > 0:
+		picked, ok := pickCategory(categoryName, categories)
+		if !ok {
+			replyWithErrorLogging(replier, fmt.Sprintf(
+				"Unknown category '%s', %s",
+				categoryName,
+				describeCategories(categories),
+			), logger)
+			return
+		}
+		category = picked.Name
+	}
+
+	modifiers := []scores.Modifier{}
+	if mods := strings.TrimSpace(inputMap["mods"]); mods != "" {
+		defined, err := h.EventScoreService.ListModifiers(eid)
+		if err != nil {
+			logger.Error("could not list modifiers", zap.Error(err))
+			result = metrics.SubmissionError
+			replyWithErrorLogging(replier, "Error fetching the modifiers of the event."+internalError, logger)
+			return
+		}
+
+		modifiers, err = scores.PickModifiers(mods, defined)
+		if err != nil {
+			replyWithErrorLogging(replier, fmt.Sprintf("%s, %s", err, describeModifiers(defined)), logger)
+			return
+		}
+	}
+
 	if len(m.Attachments) != 1 {
 		replyWithErrorLogging(
 			replier,
@@ -142,20 +233,118 @@ func (h *EventHandler) handleSubmitScore(
 	}
 
 	proof := m.Attachments[0].URL
+	// proofMessage is the message the proof is attached to, threads are started on it
+	proofMessage := m.Message
+
+	// the submission message is only deleted once the bot has a copy of its proof, else the proof
+	// would be gone along with it
+	var archived *discordgo.Message
+	if cfg.DeletesSubmissions() {
+		archived, err = h.archiveProof(s, m)
+		if err != nil {
+			if errors.Is(err, errProofTooLarge) {
+				replyWithErrorLogging(replier, fmt.Sprintf(
+					"The screenshot is too large, please keep it under %d MB",
+					maxProofSize>>20,
+				), logger)
+				return
+			}
+			logger.Error("could not archive proof", zap.Error(err))
+			result = metrics.SubmissionError
+			replyWithErrorLogging(replier, "Error saving the screenshot."+internalError, logger)
+			return
+		}
+		proof = archived.Attachments[0].URL
+		proofMessage = archived
+	}
 
-	sid, err := h.EventScoreService.ClaimScore(pid, score, proof)
+	sid, err := h.EventScoreService.ClaimScore(pid, category, score, modifiers, proof)
 
 	if err != nil {
 		logger.Error("could not upload score", zap.Error(err))
+		result = metrics.SubmissionError
+		if archived != nil {
+			if err := s.ChannelMessageDelete(archived.ChannelID, archived.ID); err != nil {
+				logger.Warn("could not delete archived proof", zap.Error(err))
+			}
+		}
 		replyWithErrorLogging(replier, "Error uploading score."+internalError, logger)
 		return
 	}
 
-	replyWithErrorLogging(
-		replier,
-		fmt.Sprintf("Successfully uploaded score (%v) - submission ID is %s", score, sid),
-		logger,
-	)
+	result = metrics.SubmissionAccepted
+	h.Webhooks.Dispatch(m.GuildID, webhook.SubmissionCreated, webhook.Submission{
+		ID:        sid,
+		EventID:   eid,
+		Category:  category,
+		UserID:    m.Author.ID,
+		Score:     scores.Apply(score, modifiers),
+		RawScore:  score,
+		Modifiers: scores.AppliedModifiers(modifiers).Names(),
+		Proof:     proof,
+	})
+
+	msg := fmt.Sprintf("Successfully uploaded score (%s) - submission ID is %s", scores.Breakdown(score, modifiers), sid)
+	if category != "" {
+		msg = fmt.Sprintf(
+			"Successfully uploaded score (%s) for category `%s` - submission ID is %s",
+			scores.Breakdown(score, modifiers),
+			category,
+			sid,
+		)
+	}
+	h.confirmSubmission(s, m, cfg, proofMessage, sid, msg, logger.With(WithSubmissionID(sid)))
+}
+
+// confirmSubmission tells the user their submission went through. The confirmation goes in the
+// thread of the submission if threads are on, and the submission message is cleaned up last so
+// the user is told either way.
+func (h *EventHandler) confirmSubmission(
+	s *discordgo.Session,
+	m *discordgo.MessageCreate,
+	cfg *settings.Settings,
+	proofMessage *discordgo.Message,
+	sid, msg string,
+	logger *zap.Logger,
+) {
+	deleting := cfg.DeletesSubmissions()
+	confirmed := false
+
+	if cfg.ThreadsSubmissions() {
+		thread, err := startThread(
+			s,
+			proofMessage.ChannelID,
+			proofMessage.ID,
+			fmt.Sprintf("%s - %s", m.Author.Username, sid),
+		)
+		if err != nil {
+			logger.Warn("could not start submission thread", zap.Error(err))
+		} else {
+			// mentioning the user adds them to the thread
+			if err := sendMentioning(s, thread.ID, m.Author.ID, msg); err != nil {
+				logger.Warn("could not confirm submission in its thread", zap.Error(err))
+			} else {
+				confirmed = true
+			}
+		}
+	}
+
+	if !confirmed {
+		if deleting {
+			// there is nothing left to reply to once the submission message is gone
+			if err := sendMentioning(s, m.ChannelID, m.Author.ID, msg); err != nil {
+				logger.Error("could not confirm submission", zap.Error(err))
+			}
+		} else {
+			replyWithErrorLogging(messageReplier(s, m.GuildID, m.ChannelID, m.ID), msg, logger)
+		}
+	}
+
+	if deleting {
+		if err := s.ChannelMessageDelete(m.ChannelID, m.ID); err != nil {
+			logger.Warn("could not delete submission message", zap.Error(err))
+		}
+	}
 }
 
 func messageReplier(s *discordgo.Session, gid, cid, mid string) MessageReplier {
@@ -103,6 +103,18 @@ func (h *EventHandler) mustHaveIGNRegistered(uid string, reply MessageReplier) (
 	return ign, true
 }
 
+// roleRequirement returns the role the action requires in the guild, or the role of the first of
+// its roleFallbacks that has one, empty if there's no requirement
+func (h *EventHandler) roleRequirement(action dialogType, gid string) (string, error) {
+	for _, v := range append([]dialogType{action}, roleFallbacks[action]...) {
+		rid, err := h.MetadataService.GetRoleRequirementForGuild(string(v), gid)
+		if err != nil || rid != "" {
+			return rid, err
+		}
+	}
+	return "", nil
+}
+
 func (h *EventHandler) mustHaveRoleWithID(
 	uid, rid, gid string,
 	reply MessageReplier,
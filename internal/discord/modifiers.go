@@ -0,0 +1,179 @@
+package discord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+func (h *EventHandler) modifierSubcommands() []*subcommand {
+	kindOption := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "kind",
+		Description: "Whether the modifier multiplies the score or adds a bonus to it",
+		Required:    true,
+	}
+	for _, v := range scores.ModifierKinds {
+		kindOption.Choices = append(kindOption.Choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  string(v),
+			Value: string(v),
+		})
+	}
+
+	nameOption := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "name",
+		Description: "Name submitters pick the modifier by, e.g. `steel-path`",
+		Required:    true,
+	}
+
+	return []*subcommand{
+		{
+			Name:        "list",
+			Description: "List the scoring modifiers submitters can pick for an event",
+			EventID:     optionalEventID,
+			Handler:     h.handleModifiersList,
+		},
+		{
+			Name:        "set",
+			Description: "Add a scoring modifier to an event, or change the one with the same name",
+			Options: []*discordgo.ApplicationCommandOption{
+				nameOption,
+				kindOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "value",
+					Description: "Multiplier like `1.5`, or points added like `100`",
+					Required:    true,
+				},
+			},
+			RoleAction: manageEventDialog,
+			EventID:    requiredEventID,
+			Handler:    h.handleModifiersSet,
+		},
+		{
+			Name:        "remove",
+			Description: "Remove a scoring modifier from an event, past submissions keep it",
+			Options:     []*discordgo.ApplicationCommandOption{nameOption},
+			RoleAction:  manageEventDialog,
+			EventID:     requiredEventID,
+			Handler:     h.handleModifiersRemove,
+		},
+	}
+}
+
+// describeModifiers lists the modifiers an event defines for submitters to pick from
+func describeModifiers(modifiers []scores.Modifier) string {
+	if len(modifiers) == 0 {
+		return "this event has no modifiers"
+	}
+
+	names := make([]string, len(modifiers))
+	for i, v := range modifiers {
+		names[i] = "`" + v.String() + "`"
+	}
+	return "this event has the modifiers " + strings.Join(names, ", ")
+}
+
+func (h *EventHandler) handleModifiersList(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	modifiers, err := h.EventScoreService.ListModifiers(c.eid)
+	if err != nil {
+		c.logger.Error("could not list modifiers", zap.Error(err))
+		c.r.errorOrLog("Could not list the modifiers of the event." + internalError)
+		return
+	}
+
+	if len(modifiers) == 0 {
+		c.r.replyOrLog(fmt.Sprintf("Event '%s' has no scoring modifiers", event.Name))
+		return
+	}
+
+	lines := make([]string, len(modifiers))
+	for i, v := range modifiers {
+		lines[i] = "`" + v.String() + "`"
+	}
+
+	c.r.replyOrLog(fmt.Sprintf(
+		"Scoring modifiers of '%s', multipliers apply before bonuses:\n%s\nPick them when submitting with "+
+			"`%ssubmit <score> mods: %s`",
+		event.Name,
+		strings.Join(lines, "\n"),
+		h.guildPrefix(c.i.GuildID),
+		modifiers[0].Name,
+	))
+}
+
+func (h *EventHandler) handleModifiersSet(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	name, _ := c.stringOption("name")
+	kind, _ := c.stringOption("kind")
+	values, _ := c.stringOption("value")
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(values), 64)
+	if err != nil {
+		c.r.errorOrLog(fmt.Sprintf("Invalid value '%s', it must be a number", values))
+		return
+	}
+
+	modifier := scores.Modifier{
+		Name:  strings.ToLower(strings.TrimSpace(name)),
+		Kind:  scores.ModifierKind(kind),
+		Value: value,
+	}
+	if err := modifier.Validate(); err != nil {
+		c.r.errorOrLog("Invalid modifier: " + err.Error())
+		return
+	}
+
+	if err := h.EventScoreService.SetModifier(c.eid, modifier); err != nil {
+		c.logger.Error("could not set modifier", zap.Error(err))
+		c.r.errorOrLog("Could not save the modifier." + internalError)
+		return
+	}
+
+	c.r.replyOrLog(fmt.Sprintf(
+		"Submissions to '%s' can now pick `%s`, submissions already made are not affected",
+		event.Name,
+		modifier.String(),
+	))
+}
+
+func (h *EventHandler) handleModifiersRemove(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	name, _ := c.stringOption("name")
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	err := h.EventScoreService.DeleteModifier(c.eid, name)
+	if err != nil {
+		if scores.AsErrNoRecord(err) {
+			c.r.errorOrLog(fmt.Sprintf("Event '%s' has no modifier named '%s'", event.Name, name))
+			return
+		}
+		c.logger.Error("could not delete modifier", zap.Error(err))
+		c.r.errorOrLog("Could not remove the modifier." + internalError)
+		return
+	}
+
+	c.r.replyOrLog(fmt.Sprintf(
+		"Removed modifier `%s` from '%s', submissions already made with it keep it",
+		name,
+		event.Name,
+	))
+}
@@ -0,0 +1,274 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/dates"
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/webhook"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+// reminderLead is how long before an event closes its participants are reminded of it
+const reminderLead = 24 * time.Hour
+
+// eventNotifications are what participants are sent as an event goes through its lifecycle, keyed
+// by the webhook event type of the transition
+var eventNotifications = map[string]func(e *meta.Event) string{
+	webhook.EventActivated: func(e *meta.Event) string {
+		return fmt.Sprintf("'%s' is now open, submit your scores before it closes %s", e.Name, dates.Display(e.End))
+	},
+	webhook.EventClosed: func(e *meta.Event) string {
+		return fmt.Sprintf("'%s' has closed and no longer takes submissions", e.Name)
+	},
+	webhook.EventFinalized: func(e *meta.Event) string {
+		return fmt.Sprintf(
+			"The results of '%s' are final, see where you placed with `/events progress event-id: %s`",
+			e.Name,
+			e.ID,
+		)
+	},
+}
+
+var notificationDescriptions = map[meta.NotificationKind]string{
+	meta.NotifySubmissions: "when a moderator verifies, rejects or removes one of your submissions",
+	meta.NotifyEvents:      "when an event you joined opens, closes or has its results finalized",
+	meta.NotifyReminders:   "a day before an event you joined closes",
+}
+
+func (h *EventHandler) notifySubcommands() []*subcommand {
+	options := make([]*discordgo.ApplicationCommandOption, len(meta.NotificationKinds))
+	for i, v := range meta.NotificationKinds {
+		options[i] = &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        string(v),
+			Description: "Get a DM " + notificationDescriptions[v],
+		}
+	}
+
+	return []*subcommand{
+		{
+			Name:        "view",
+			Description: "Show what the bot sends you DMs about",
+			Handler:     h.handleNotifyView,
+		},
+		{
+			Name:        "set",
+			Description: "Choose what the bot sends you DMs about, leave out what you don't want to change",
+			Options:     options,
+			Handler:     h.handleNotifySet,
+		},
+	}
+}
+
+func (h *EventHandler) handleNotifyView(c *commandContext) {
+	p, err := h.MetadataService.GetNotificationPreferences(c.uid())
+	if err != nil {
+		c.logger.Error("could not fetch notification preferences", zap.Error(err))
+		c.r.errorOrLog("Could not fetch your notification preferences." + internalError)
+		return
+	}
+
+	c.r.errorOrLog(describeNotifications(p))
+}
+
+func (h *EventHandler) handleNotifySet(c *commandContext) {
+	p, err := h.MetadataService.GetNotificationPreferences(c.uid())
+	if err != nil {
+		c.logger.Error("could not fetch notification preferences", zap.Error(err))
+		c.r.errorOrLog("Could not fetch your notification preferences." + internalError)
+		return
+	}
+
+	p.Submissions = c.boolOption(string(meta.NotifySubmissions), p.Submissions)
+	p.Events = c.boolOption(string(meta.NotifyEvents), p.Events)
+	p.Reminders = c.boolOption(string(meta.NotifyReminders), p.Reminders)
+
+	if err := h.MetadataService.SetNotificationPreferences(p); err != nil {
+		c.logger.Error("could not save notification preferences", zap.Error(err))
+		c.r.errorOrLog("Could not save your notification preferences." + internalError)
+		return
+	}
+
+	// saving lifts the hold on DMs, describe the preferences as they are now
+	p.DMsClosed = false
+	c.r.errorOrLog(describeNotifications(p))
+}
+
+func describeNotifications(p *meta.NotificationPreferences) string {
+	lines := []string{}
+	for _, v := range meta.NotificationKinds {
+		state := "off"
+		if p.Enabled(v) {
+			state = "on"
+		}
+		lines = append(lines, fmt.Sprintf("`%s` - **%s**, %s", v, state, notificationDescriptions[v]))
+	}
+
+	msg := "DM notifications:\n" + strings.Join(lines, "\n")
+	if p.DMsClosed {
+		msg += "\n\nThe last DM could not be delivered, so none are sent for now. Allow DMs from members of " +
+			"this server and run `/notify set` to get them again."
+	}
+	return msg
+}
+
+// notify sends msg as a DM to the users that opted in to the kind of notification. DMs go out in
+// the background so handlers don't wait on them being sent one by one.
+func (h *EventHandler) notify(
+	s *discordgo.Session,
+	kind meta.NotificationKind,
+	uids []string,
+	msg string,
+	l *zap.Logger,
+) {
+	if len(uids) == 0 {
+		return
+	}
+
+	done, ok := h.inflight.begin("notifications")
+	if !ok {
+		return
+	}
+
+	go func() {
+		defer done()
+
+		recipients, err := h.MetadataService.ListUsersToNotify(uids, kind)
+		if err != nil {
+			l.Error("could not look up who to notify", zap.Error(err), zap.String("kind", string(kind)))
+			return
+		}
+
+		for _, uid := range recipients {
+			h.sendDM(s, uid, msg, l.With(WithUserID(uid)))
+		}
+	}()
+}
+
+// sendDM holds off any further DMs to users that don't accept them, instead of failing on every
+// notification they would get
+func (h *EventHandler) sendDM(s *discordgo.Session, uid, msg string, l *zap.Logger) {
+	channel, err := s.UserChannelCreate(uid)
+	if err == nil {
+		_, err = s.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+			Content:         msg,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		})
+	}
+	if err == nil {
+		return
+	}
+
+	restErr := &discordgo.RESTError{}
+	if errors.As(err, &restErr) && restErr.Message != nil &&
+		restErr.Message.Code == discordgo.ErrCodeCannotSendMessagesToThisUser {
+		l.Info("user does not accept DMs, holding off notifications")
+		if err := h.MetadataService.SetDMsClosed(uid); err != nil {
+			l.Error("could not hold off notifications", zap.Error(err))
+		}
+		return
+	}
+
+	l.Error("could not send notification", zap.Error(err))
+}
+
+// submitterOf looks up who made the submission, for removals this has to happen before the
+// submission is gone
+func (h *EventHandler) submitterOf(sid string, l *zap.Logger) string {
+	record, err := h.EventScoreService.GetSubmission(sid)
+	if err != nil {
+		l.Warn("could not fetch submission for notifications", zap.Error(err))
+		return ""
+	}
+	return record.UID
+}
+
+// NotifySubmitter tells the user who made the submission what a moderator did with it, for
+// moderation done outside of discord
+func (h *EventHandler) NotifySubmitter(s *discordgo.Session, uid, sid, eventName, outcome string) {
+	h.notifySubmitter(s, uid, sid, eventName, outcome, h.Logger)
+}
+
+// notifySubmitter tells the user who made the submission what a moderator did with it
+func (h *EventHandler) notifySubmitter(
+	s *discordgo.Session,
+	uid, sid, eventName, outcome string,
+	l *zap.Logger,
+) {
+	if uid == "" {
+		return
+	}
+
+	h.notify(
+		s,
+		meta.NotifySubmissions,
+		[]string{uid},
+		fmt.Sprintf("Your submission `%s` to '%s' %s", sid, eventName, outcome),
+		l.With(WithSubmissionID(sid)),
+	)
+}
+
+// notifyParticipants tells the participants of the event it went through a lifecycle transition,
+// eventType is the webhook event type of the transition
+func (h *EventHandler) notifyParticipants(s *discordgo.Session, eid, eventType string, l *zap.Logger) {
+	message, ok := eventNotifications[eventType]
+	if !ok {
+		return
+	}
+
+	event, err := h.MetadataService.GetEvent(eid)
+	if err != nil {
+		l.Warn("could not fetch event for notifications", zap.Error(err))
+		return
+	}
+
+	h.notify(s, meta.NotifyEvents, h.participantIDs(eid, l), message(event), l)
+}
+
+func (h *EventHandler) participantIDs(eid string, l *zap.Logger) []string {
+	participants, _, err := h.MetadataService.ListUserForEvent(eid)
+	if err != nil {
+		l.Error("could not list participants for notifications", zap.Error(err))
+		return nil
+	}
+
+	uids := make([]string, 0, len(participants))
+	for uid := range participants {
+		uids = append(uids, uid)
+	}
+	return uids
+}
+
+// runEventReminders reminds the participants of events closing within reminderLead, once for
+// every end date an event has
+func (h *EventHandler) runEventReminders(s *discordgo.Session, now time.Time) {
+	logger := h.Logger.With(WithComponent("event-reminders"))
+
+	events, err := h.MetadataService.ListEventsToRemind(now.Add(reminderLead))
+	if err != nil {
+		logger.Error("could not list events to remind of", zap.Error(err))
+		return
+	}
+
+	for _, v := range events {
+		l := logger.With(WithGuildID(v.GID), WithEventID(v.ID))
+
+		if err := h.MetadataService.ClaimEventReminder(v.ID, v.End); err != nil {
+			if !meta.AsErrNoRecord(err) {
+				l.Error("could not claim event reminder", zap.Error(err))
+			}
+			continue
+		}
+
+		h.notify(s, meta.NotifyReminders, h.participantIDs(v.ID, l), fmt.Sprintf(
+			"'%s' closes %s, make sure your submissions are in before then",
+			v.Name,
+			dates.Display(v.End),
+		), l)
+	}
+}
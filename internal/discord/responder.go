@@ -0,0 +1,238 @@
+package discord
+
+import (
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+// ephemeralFlag marks an interaction response as only visible to the invoking user
+const ephemeralFlag uint64 = 1 << 6
+
+// privatelyAnsweredNote fills in a public deferred response that gets an ephemeral answer, the
+// answer itself follows up where only the invoking user can see it
+const privatelyAnsweredNote = "The response was sent privately."
+
+// Responder tracks the response state of a single interaction so handlers can reply, defer,
+// follow up and edit without having to know which discord endpoint applies at that point.
+// The first message goes out as the interaction response, a deferred response gets filled in
+// by editing the original, and anything after that is sent as a followup message.
+type Responder struct {
+	s      *discordgo.Session
+	i      *discordgo.Interaction
+	logger *zap.Logger
+
+	mu        sync.Mutex
+	responded bool
+	deferred  bool
+	// ephemeral is whether the deferred response only shows to the invoking user
+	ephemeral bool
+	filled    bool
+}
+
+// NewResponder starts tracking the response to the interaction, nothing is sent until one of its
+// methods is called.
+func NewResponder(s *discordgo.Session, i *discordgo.Interaction, logger *zap.Logger) *Responder {
+	return &Responder{s: s, i: i, logger: logger}
+}
+
+// Defer acknowledges the interaction and shows a loading state, the response is expected to
+// be supplied later through any of the reply methods. Use this before anything that might
+// take longer than the 3 seconds discord gives us.
+func (r *Responder) Defer(ephemeral bool) error {
+	return r.deferWith(discordgo.InteractionResponseDeferredChannelMessageWithSource, ephemeral)
+}
+
+// DeferUpdate acknowledges a component interaction, the message the component is attached to
+// is expected to be updated later with Update.
+func (r *Responder) DeferUpdate() error {
+	return r.deferWith(discordgo.InteractionResponseDeferredMessageUpdate, false)
+}
+
+func (r *Responder) deferWith(t discordgo.InteractionResponseType, ephemeral bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.responded {
+		return nil
+	}
+
+	data := &discordgo.InteractionResponseData{}
+	if ephemeral {
+		data.Flags = ephemeralFlag
+	}
+
+	err := r.s.InteractionRespond(r.i, &discordgo.InteractionResponse{Type: t, Data: data})
+	if err != nil {
+		return err
+	}
+
+	r.responded, r.deferred, r.ephemeral = true, true, ephemeral
+	return nil
+}
+
+// Reply sends a public plain text message, it satisfies MessageReplier.
+func (r *Responder) Reply(msg string) error {
+	return r.Respond(&discordgo.InteractionResponseData{Content: msg})
+}
+
+// ReplyEphemeral sends a plain text message only the invoking user can see, it satisfies
+// MessageReplier and is what error and permission messages should go through. After a public
+// Defer the message follows up privately, see Respond.
+func (r *Responder) ReplyEphemeral(msg string) error {
+	return r.Respond(&discordgo.InteractionResponseData{Content: msg, Flags: ephemeralFlag})
+}
+
+// Embeds sends a public message made of the given embeds.
+func (r *Responder) Embeds(embeds ...*discordgo.MessageEmbed) error {
+	return r.Respond(&discordgo.InteractionResponseData{Embeds: embeds})
+}
+
+// Respond sends data as the interaction response, as the content of a deferred response, or as
+// a followup message depending on what has already been sent for the interaction. Whether a
+// deferred response is ephemeral is decided when deferring and can't be changed, ephemeral data
+// for a public deferred response is sent as a followup after filling in the response with a note
+// instead, so nothing private ends up in the channel.
+func (r *Responder) Respond(data *discordgo.InteractionResponseData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case !r.responded:
+		err := r.s.InteractionRespond(r.i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: data,
+		})
+		if err != nil {
+			return err
+		}
+		r.responded, r.filled = true, true
+		return nil
+
+	case r.deferred && !r.filled && data.Flags&ephemeralFlag != 0 && !r.ephemeral:
+		// the first followup would take the place of the public loading message, ignoring the
+		// flags, so the loading message is filled in first
+		err := r.editOriginal(&discordgo.InteractionResponseData{Content: privatelyAnsweredNote})
+		if err != nil {
+			return err
+		}
+		_, err = r.followup(data)
+		return err
+
+	case r.deferred && !r.filled:
+		return r.editOriginal(data)
+
+	default:
+		_, err := r.followup(data)
+		return err
+	}
+}
+
+// Update replaces the message a component is attached to, either directly or by filling in the
+// response previously deferred with DeferUpdate.
+func (r *Responder) Update(data *discordgo.InteractionResponseData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.deferred && !r.filled {
+		return r.editOriginal(data)
+	}
+
+	err := r.s.InteractionRespond(r.i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: data,
+	})
+	if err != nil {
+		return err
+	}
+	r.responded, r.filled = true, true
+	return nil
+}
+
+// Edit changes the original response of the interaction after it has been sent.
+func (r *Responder) Edit(edit *discordgo.WebhookEdit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.s.InteractionResponseEdit(r.s.State.User.ID, r.i, edit)
+	if err != nil {
+		return err
+	}
+	r.filled = true
+	return nil
+}
+
+// Followup sends an additional message for the interaction, this is also the only way to send
+// files since the initial interaction response can't carry attachments. The first followup after
+// a deferred response takes the place of the loading message.
+func (r *Responder) Followup(params *discordgo.WebhookParams) (*discordgo.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, err := r.s.FollowupMessageCreate(r.s.State.User.ID, r.i, true, params)
+	if err != nil {
+		return nil, err
+	}
+	r.filled = true
+	return m, nil
+}
+
+// editOriginal fills in the deferred response with data, the lock must be held
+func (r *Responder) editOriginal(data *discordgo.InteractionResponseData) error {
+	err := r.s.InteractionResponseEdit(r.s.State.User.ID, r.i, &discordgo.WebhookEdit{
+		Content:    data.Content,
+		Components: data.Components,
+		Embeds:     data.Embeds,
+	})
+	if err != nil {
+		return err
+	}
+	r.filled = true
+	return nil
+}
+
+// followupParams is WebhookParams with the message flags the discordgo struct doesn't expose yet
+type followupParams struct {
+	discordgo.WebhookParams
+	Flags uint64 `json:"flags,omitempty"`
+}
+
+func (r *Responder) followup(data *discordgo.InteractionResponseData) (*discordgo.Message, error) {
+	params := discordgo.WebhookParams{
+		Content:         data.Content,
+		Components:      data.Components,
+		Embeds:          data.Embeds,
+		AllowedMentions: data.AllowedMentions,
+	}
+
+	if data.Flags == 0 {
+		return r.s.FollowupMessageCreate(r.s.State.User.ID, r.i, true, &params)
+	}
+
+	endpoint := discordgo.EndpointWebhookToken(r.s.State.User.ID, r.i.Token)
+	_, err := r.s.RequestWithBucketID(
+		"POST",
+		endpoint+"?wait=true",
+		followupParams{params, data.Flags},
+		endpoint,
+	)
+	return nil, err
+}
+
+// replyOrLog replies with msg and logs if that fails, for handlers that have nothing else to do
+// with the error.
+func (r *Responder) replyOrLog(msg string) {
+	replyWithErrorLogging(r.Reply, msg, r.logger)
+}
+
+// privateReplyOrLog privately replies with msg and logs if that fails, for results only the
+// invoking user should see such as secrets and login links.
+func (r *Responder) privateReplyOrLog(msg string) {
+	replyWithErrorLogging(r.ReplyEphemeral, msg, r.logger)
+}
+
+// errorOrLog privately replies with msg and logs if that fails.
+func (r *Responder) errorOrLog(msg string) {
+	replyWithErrorLogging(r.ReplyEphemeral, msg, r.logger)
+}
@@ -0,0 +1,212 @@
+package discord_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/2785/warframe-assistant/internal/discord"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// discordCall is a request the responder made, with the content and flags of the message it
+// carried
+type discordCall struct {
+	Method  string
+	Path    string
+	Type    discordgo.InteractionResponseType
+	Content string
+	Flags   uint64
+}
+
+const (
+	respondPath  = "/interactions/interaction-1/token-1/callback"
+	originalPath = "/webhooks/app-1/token-1/messages/@original"
+	followupPath = "/webhooks/app-1/token-1"
+	ephemeral    = 1 << 6
+)
+
+// fakeDiscord points discordgo at a test server for the duration of the test and records every
+// request sent to it
+func fakeDiscord(t *testing.T) (*discordgo.Session, *[]discordCall) {
+	calls := &[]discordCall{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Type    discordgo.InteractionResponseType `json:"type"`
+			Content string                            `json:"content"`
+			Flags   uint64                            `json:"flags"`
+			Data    struct {
+				Content string `json:"content"`
+				Flags   uint64 `json:"flags"`
+			} `json:"data"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		call := discordCall{
+			Method:  r.Method,
+			Path:    strings.TrimPrefix(r.URL.Path, "/api"),
+			Type:    body.Type,
+			Content: body.Content,
+			Flags:   body.Flags,
+		}
+		if body.Type != 0 {
+			call.Content, call.Flags = body.Data.Content, body.Data.Flags
+		}
+		*calls = append(*calls, call)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "message-1"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	api, webhooks := discordgo.EndpointAPI, discordgo.EndpointWebhooks
+	discordgo.EndpointAPI = srv.URL + "/api/"
+	discordgo.EndpointWebhooks = discordgo.EndpointAPI + "webhooks/"
+	t.Cleanup(func() {
+		discordgo.EndpointAPI, discordgo.EndpointWebhooks = api, webhooks
+	})
+
+	s, err := discordgo.New("Bot token")
+	require.NoError(t, err)
+	s.Client = srv.Client()
+	s.State.User = &discordgo.User{ID: "app-1"}
+
+	return s, calls
+}
+
+func TestResponder(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		steps func(r *discord.Responder) error
+		want  []discordCall
+	}{
+		{
+			name:  "the first reply responds",
+			steps: func(r *discord.Responder) error { return r.Reply("hello") },
+			want: []discordCall{
+				{Method: "POST", Path: respondPath, Type: discordgo.InteractionResponseChannelMessageWithSource, Content: "hello"},
+			},
+		},
+		{
+			name: "replies after the response follow up",
+			steps: func(r *discord.Responder) error {
+				if err := r.Reply("hello"); err != nil {
+					return err
+				}
+				return r.ReplyEphemeral("only you")
+			},
+			want: []discordCall{
+				{Method: "POST", Path: respondPath, Type: discordgo.InteractionResponseChannelMessageWithSource, Content: "hello"},
+				{Method: "POST", Path: followupPath, Content: "only you", Flags: ephemeral},
+			},
+		},
+		{
+			name: "a deferred response is filled in and followed up",
+			steps: func(r *discord.Responder) error {
+				if err := r.Defer(false); err != nil {
+					return err
+				}
+				if err := r.Reply("done"); err != nil {
+					return err
+				}
+				return r.Reply("one more thing")
+			},
+			want: []discordCall{
+				{Method: "POST", Path: respondPath, Type: discordgo.InteractionResponseDeferredChannelMessageWithSource},
+				{Method: "PATCH", Path: originalPath, Content: "done"},
+				{Method: "POST", Path: followupPath, Content: "one more thing"},
+			},
+		},
+		{
+			name: "an ephemeral deferred response takes ephemeral replies",
+			steps: func(r *discord.Responder) error {
+				if err := r.Defer(true); err != nil {
+					return err
+				}
+				return r.ReplyEphemeral("only you")
+			},
+			want: []discordCall{
+				{Method: "POST", Path: respondPath, Type: discordgo.InteractionResponseDeferredChannelMessageWithSource, Flags: ephemeral},
+				{Method: "PATCH", Path: originalPath, Content: "only you"},
+			},
+		},
+		{
+			name: "ephemeral replies to a public deferred response stay private",
+			steps: func(r *discord.Responder) error {
+				if err := r.Defer(false); err != nil {
+					return err
+				}
+				return r.ReplyEphemeral("only you")
+			},
+			want: []discordCall{
+				{Method: "POST", Path: respondPath, Type: discordgo.InteractionResponseDeferredChannelMessageWithSource},
+				{Method: "PATCH", Path: originalPath, Content: "The response was sent privately."},
+				{Method: "POST", Path: followupPath, Content: "only you", Flags: ephemeral},
+			},
+		},
+		{
+			name: "deferring twice does nothing",
+			steps: func(r *discord.Responder) error {
+				if err := r.Reply("hello"); err != nil {
+					return err
+				}
+				return r.Defer(true)
+			},
+			want: []discordCall{
+				{Method: "POST", Path: respondPath, Type: discordgo.InteractionResponseChannelMessageWithSource, Content: "hello"},
+			},
+		},
+		{
+			name: "updates fill in a deferred update",
+			steps: func(r *discord.Responder) error {
+				if err := r.DeferUpdate(); err != nil {
+					return err
+				}
+				return r.Update(&discordgo.InteractionResponseData{Content: "updated"})
+			},
+			want: []discordCall{
+				{Method: "POST", Path: respondPath, Type: discordgo.InteractionResponseDeferredMessageUpdate},
+				{Method: "PATCH", Path: originalPath, Content: "updated"},
+			},
+		},
+		{
+			name: "updates without deferring respond",
+			steps: func(r *discord.Responder) error {
+				return r.Update(&discordgo.InteractionResponseData{Content: "updated"})
+			},
+			want: []discordCall{
+				{Method: "POST", Path: respondPath, Type: discordgo.InteractionResponseUpdateMessage, Content: "updated"},
+			},
+		},
+		{
+			name: "a followup fills in the deferred response",
+			steps: func(r *discord.Responder) error {
+				if err := r.Defer(false); err != nil {
+					return err
+				}
+				if _, err := r.Followup(&discordgo.WebhookParams{Content: "file"}); err != nil {
+					return err
+				}
+				return r.Reply("after")
+			},
+			want: []discordCall{
+				{Method: "POST", Path: respondPath, Type: discordgo.InteractionResponseDeferredChannelMessageWithSource},
+				{Method: "POST", Path: followupPath, Content: "file"},
+				{Method: "POST", Path: followupPath, Content: "after"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, calls := fakeDiscord(t)
+			r := discord.NewResponder(s, &discordgo.Interaction{ID: "interaction-1", Token: "token-1"}, zap.NewNop())
+
+			require.NoError(t, tc.steps(r))
+			assert.Equal(t, tc.want, *calls)
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package discord
+
+import (
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/metrics"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+// eventIDMode describes how a subcommand gets hold of the event it operates on
+type eventIDMode int
+
+const (
+	// noEventID means the subcommand is not about a specific event
+	noEventID eventIDMode = iota
+	// optionalEventID adds an optional event-id option, falling back to the only active event of
+	// the guild when it's omitted
+	optionalEventID
+	// requiredEventID adds an event-id option that must be supplied
+	requiredEventID
+)
+
+const eventIDOption = "event-id"
+
+// command is the declarative definition of a top level slash command. Commands either run a
+// single Root handler or dispatch to one of their Subcommands or the subcommands of their Groups.
+type command struct {
+	Name        string
+	Description string
+	Root        *subcommand
+	Subcommands []*subcommand
+	Groups      []*subcommandGroup
+}
+
+// subcommandGroup nests subcommands one level deeper, e.g. `/events template create`
+type subcommandGroup struct {
+	Name        string
+	Description string
+	Subcommands []*subcommand
+}
+
+// subcommand declares the options a handler takes and the checks that must pass before the
+// handler is called. The router takes care of option binding, role requirements, IGN
+// registration and event ID resolution so handlers only deal with their own logic.
+type subcommand struct {
+	Name        string
+	Description string
+	Options     []*discordgo.ApplicationCommandOption
+	// RoleAction is looked up in the guild's role requirements, empty means anyone can run it
+	RoleAction dialogType
+	// NeedsIGN requires the user to have an IGN registered with the bot
+	NeedsIGN bool
+	EventID  eventIDMode
+	Handler  func(c *commandContext)
+}
+
+// commandContext is what a subcommand handler gets to work with
+type commandContext struct {
+	s      *discordgo.Session
+	i      *discordgo.Interaction
+	r      *Responder
+	logger *zap.Logger
+	opts   map[string]interface{}
+
+	// eid is the resolved event ID for subcommands that declare an EventID mode
+	eid string
+	// ign is the user's registered IGN for subcommands that declare NeedsIGN
+	ign string
+}
+
+func (c *commandContext) uid() string {
+	return c.i.Member.User.ID
+}
+
+func (c *commandContext) stringOption(name string) (string, bool) {
+	v, ok := c.opts[name].(string)
+	return v, ok
+}
+
+func (c *commandContext) intOption(name string) (int, bool) {
+	v, ok := c.opts[name].(float64)
+	return int(v), ok
+}
+
+func (c *commandContext) boolOption(name string, def bool) bool {
+	v, ok := c.opts[name].(bool)
+	if !ok {
+		return def
+	}
+	return v
+}
+
+// commandRouter holds the command definitions, both for dispatching interactions and for
+// generating what gets registered with discord.
+type commandRouter struct {
+	commands []*command
+	byName   map[string]*command
+}
+
+func newCommandRouter(commands ...*command) *commandRouter {
+	rt := &commandRouter{byName: make(map[string]*command, len(commands))}
+	for _, c := range commands {
+		rt.commands = append(rt.commands, c)
+		rt.byName[c.Name] = c
+	}
+	return rt
+}
+
+// applicationCommands renders the definitions into what discord expects
+func (rt *commandRouter) applicationCommands() []*discordgo.ApplicationCommand {
+	out := make([]*discordgo.ApplicationCommand, 0, len(rt.commands))
+	for _, c := range rt.commands {
+		appCmd := &discordgo.ApplicationCommand{Name: c.Name, Description: c.Description}
+
+		if c.Root != nil {
+			appCmd.Options = c.Root.options()
+		}
+
+		appCmd.Options = append(appCmd.Options, subcommandOptions(c.Subcommands)...)
+
+		for _, g := range c.Groups {
+			appCmd.Options = append(appCmd.Options, &discordgo.ApplicationCommandOption{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        g.Name,
+				Description: g.Description,
+				Options:     subcommandOptions(g.Subcommands),
+			})
+		}
+
+		out = append(out, appCmd)
+	}
+	return out
+}
+
+func subcommandOptions(subs []*subcommand) []*discordgo.ApplicationCommandOption {
+	out := make([]*discordgo.ApplicationCommandOption, 0, len(subs))
+	for _, sub := range subs {
+		out = append(out, &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        sub.Name,
+			Description: sub.Description,
+			Options:     sub.options(),
+		})
+	}
+	return out
+}
+
+func (sub *subcommand) options() []*discordgo.ApplicationCommandOption {
+	switch sub.EventID {
+	case optionalEventID:
+		return append(append([]*discordgo.ApplicationCommandOption{}, sub.Options...),
+			&discordgo.ApplicationCommandOption{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        eventIDOption,
+				Description: "The UUID of the event, defaults to the only active event",
+			})
+	case requiredEventID:
+		// discord wants required options before optional ones
+		return append([]*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        eventIDOption,
+				Description: "The UUID of the event",
+				Required:    true,
+			},
+		}, sub.Options...)
+	default:
+		return sub.Options
+	}
+}
+
+func (h *EventHandler) routeCommand(
+	s *discordgo.Session,
+	i *discordgo.Interaction,
+	r *Responder,
+) {
+	data := i.ApplicationCommandData()
+	cmd, ok := h.router.byName[data.Name]
+	if !ok {
+		h.Logger.Warn("unknown slash command", WithCommand(data.Name))
+		r.errorOrLog("The input command is current not handled")
+		return
+	}
+
+	name := cmd.Name
+	sub, opts := cmd.Root, data.Options
+	if sub == nil {
+		if len(data.Options) < 1 {
+			r.errorOrLog("No subcommand found")
+			return
+		}
+
+		subs, chosen := cmd.Subcommands, data.Options[0]
+		// the interaction doesn't say whether the option is a group, but discord makes sure groups
+		// and subcommands don't share names
+		for _, g := range cmd.Groups {
+			if g.Name != chosen.Name {
+				continue
+			}
+			if len(chosen.Options) < 1 {
+				r.errorOrLog("No subcommand found")
+				return
+			}
+			subs = g.Subcommands
+			name += " " + chosen.Name
+			chosen = chosen.Options[0]
+			break
+		}
+
+		for _, v := range subs {
+			if v.Name == chosen.Name {
+				sub = v
+			}
+		}
+		if sub == nil {
+			r.errorOrLog("Unknown subcommand")
+			return
+		}
+		opts = chosen.Options
+	}
+
+	if sub.Name != "" {
+		name += " " + sub.Name
+	}
+	defer metrics.ObserveCommand(name, time.Now())
+
+	c := &commandContext{
+		s: s,
+		i: i,
+		r: r,
+		logger: h.Logger.With(
+			WithCommand(name),
+			WithGuildID(i.GuildID),
+			WithChannelID(i.ChannelID),
+			WithUserID(i.Member.User.ID),
+		),
+		opts: bindOptions(opts),
+	}
+
+	if sub.RoleAction != "" {
+		rid, err := h.roleRequirement(sub.RoleAction, i.GuildID)
+		if err != nil {
+			c.logger.Error(
+				"could not fetch role requirements for elevated permission",
+				zap.Error(err),
+			)
+			r.errorOrLog("Something went wrong." + internalError)
+			return
+		}
+
+		if !h.mustHaveRoleWithID(c.uid(), rid, i.GuildID, r.ReplyEphemeral, s) {
+			return
+		}
+	}
+
+	if sub.NeedsIGN {
+		c.ign, ok = h.mustHaveIGNRegistered(c.uid(), r.ReplyEphemeral)
+		if !ok {
+			return
+		}
+	}
+
+	if sub.EventID != noEventID {
+		c.eid, _ = c.stringOption(eventIDOption)
+		if c.eid == "" {
+			if sub.EventID == requiredEventID {
+				r.errorOrLog("You must supply an event ID")
+				return
+			}
+
+			c.eid, ok = h.mustGetOneActiveEventIDForGuild(i.GuildID, r.ReplyEphemeral)
+			if !ok {
+				return
+			}
+		}
+		c.logger = c.logger.With(WithEventID(c.eid))
+	}
+
+	sub.Handler(c)
+}
+
+func bindOptions(
+	options []*discordgo.ApplicationCommandInteractionDataOption,
+) map[string]interface{} {
+	out := make(map[string]interface{}, len(options))
+	for _, v := range options {
+		out[v.Name] = v.Value
+	}
+
+	return out
+}
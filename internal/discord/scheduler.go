@@ -0,0 +1,42 @@
+package discord
+
+import (
+	"context"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// schedulerInterval is how often the background jobs check for work that is due
+const schedulerInterval = time.Minute
+
+// StartScheduler runs the background jobs that are due at a given time, creating the events of
+// templates and reminding participants of events closing, until Drain is called. With multiple
+// replicas only the leader runs them.
+func (h *EventHandler) StartScheduler(s *discordgo.Session) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.stopScheduler = cancel
+
+	go func() {
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if h.Coordinator != nil && !h.Coordinator.IsLeader() {
+					continue
+				}
+				done, ok := h.inflight.begin("scheduler")
+				if !ok {
+					return
+				}
+				h.runDueTemplates(s, now)
+				h.runEventReminders(s, now)
+				done()
+			}
+		}
+	}()
+}
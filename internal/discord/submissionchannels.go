@@ -0,0 +1,107 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxProofSize is the largest upload a bot can make to a server without boosts
+	maxProofSize = 8 << 20
+	// maxThreadNameLength is the longest name discord takes for a thread
+	maxThreadNameLength = 100
+	// submissionThreadArchiveMinutes is how long a submission thread stays open without activity
+	submissionThreadArchiveMinutes = 1440
+)
+
+var errProofTooLarge = errors.New("proof is too large to archive")
+
+// archiveProof posts a copy of the proof attached to the submission message, so the proof outlives
+// the submission message being deleted. The copy is posted in the same channel, without the
+// submission being processed yet it doesn't ping anyone.
+func (h *EventHandler) archiveProof(s *discordgo.Session, m *discordgo.MessageCreate) (*discordgo.Message, error) {
+	attachment := m.Attachments[0]
+	if attachment.Size > maxProofSize {
+		return nil, errProofTooLarge
+	}
+
+	res, err := s.Client.Get(attachment.URL)
+	if err != nil {
+		return nil, fmt.Errorf("could not download proof: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download proof: status %d", res.StatusCode)
+	}
+
+	name := attachment.Filename
+	if name == "" {
+		name = "proof" + path.Ext(attachment.URL)
+	}
+
+	archived, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("Proof submitted by <@%s>", m.Author.ID),
+		Files: []*discordgo.File{
+			{
+				Name:        name,
+				ContentType: res.Header.Get("Content-Type"),
+				Reader:      io.LimitReader(res.Body, maxProofSize),
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not upload proof: %w", err)
+	}
+
+	if len(archived.Attachments) == 0 {
+		return nil, errors.New("uploaded proof has no attachment")
+	}
+
+	return archived, nil
+}
+
+// startThread starts a public thread on the message. The version of discordgo in use predates
+// threads, so the endpoint is called directly.
+func startThread(s *discordgo.Session, cid, mid, name string) (*discordgo.Channel, error) {
+	if len(name) > maxThreadNameLength {
+		name = name[:maxThreadNameLength]
+	}
+
+	body, err := s.RequestWithBucketID(
+		http.MethodPost,
+		discordgo.EndpointChannelMessage(cid, mid)+"/threads",
+		map[string]interface{}{
+			"name":                  name,
+			"auto_archive_duration": submissionThreadArchiveMinutes,
+		},
+		discordgo.EndpointChannelMessage(cid, ""),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := &discordgo.Channel{}
+	if err := json.Unmarshal(body, thread); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// sendMentioning posts msg in the channel, pinging only the given user
+func sendMentioning(s *discordgo.Session, cid, uid, msg string) error {
+	_, err := s.ChannelMessageSendComplex(cid, &discordgo.MessageSend{
+		Content: fmt.Sprintf("<@%s> %s", uid, msg),
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Users: []string{uid},
+		},
+	})
+	return err
+}
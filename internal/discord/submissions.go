@@ -0,0 +1,197 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/2785/warframe-assistant/internal/dates"
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/webhook"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxListedSubmissions keeps `/events my-submissions` within a single message, the most recent
+// submissions are the ones listed
+const maxListedSubmissions = 12
+
+func (h *EventHandler) handleEventsMySubmissions(c *commandContext) {
+	event, ok := h.guildEvent(c.eid, c.i.GuildID, c.r.ReplyEphemeral, c.logger)
+	if !ok {
+		return
+	}
+
+	pid, _, err := h.MetadataService.GetParticipation(c.uid(), c.eid)
+	if err != nil {
+		if meta.AsErrNoRecord(err) {
+			c.r.errorOrLog(fmt.Sprintf("You haven't joined '%s', so you have no submissions in it", event.Name))
+			return
+		}
+		c.logger.Error("could not check if user is in event", zap.Error(err))
+		c.r.errorOrLog("Could not check if you are in the event." + internalError)
+		return
+	}
+
+	records, err := h.EventScoreService.ListForParticipation(pid)
+	if err != nil {
+		c.logger.Error("could not list submissions of user", zap.Error(err))
+		c.r.errorOrLog("Could not fetch your submissions." + internalError)
+		return
+	}
+
+	if len(records) == 0 {
+		c.r.errorOrLog(fmt.Sprintf("You have no submissions in '%s' yet", event.Name))
+		return
+	}
+
+	verified, pending := 0, 0
+	for _, v := range records {
+		switch {
+		case v.Removed:
+		case v.Verified:
+			verified++
+		default:
+			pending++
+		}
+	}
+
+	msg := fmt.Sprintf(
+		"Your submissions to '%s', %d verified and %d pending\n%s",
+		event.Name,
+		verified,
+		pending,
+		h.describeRank(event, c.uid(), c.logger),
+	)
+
+	listed := records
+	if len(listed) > maxListedSubmissions {
+		listed = listed[len(listed)-maxListedSubmissions:]
+		msg += fmt.Sprintf("\nShowing the latest %d of %d submissions", maxListedSubmissions, len(records))
+	}
+
+	lines := make([]string, len(listed))
+	for i, v := range listed {
+		lines[i] = describeSubmission(v)
+	}
+
+	c.r.errorOrLog(msg + "\n\n" + strings.Join(lines, "\n"))
+}
+
+// describeRank tells the user where they stand on the leaderboard of the event, which only counts
+// verified submissions
+func (h *EventHandler) describeRank(event *meta.Event, uid string, l *zap.Logger) string {
+	leaderboard, _, err := h.makeLeaderboard(event)
+	if err != nil {
+		if !errors.Is(err, errUnsupportedLeaderboard) {
+			l.Warn("could not make leaderboard for rank", zap.Error(err))
+		}
+		return "Your rank is not available right now"
+	}
+
+	for _, v := range leaderboard {
+		if v.UID == uid {
+			return fmt.Sprintf("You are ranked **#%d** of %d with a score of %d", v.Rank, len(leaderboard), v.Score)
+		}
+	}
+	return "You are not ranked yet, only verified submissions count towards the leaderboard"
+}
+
+func describeSubmission(r scores.ScoreRecord) string {
+	score := fmt.Sprint(r.Score)
+	if breakdown := r.Breakdown(); breakdown != "" {
+		score += " (" + breakdown + ")"
+	}
+	if r.Category != "" {
+		score += fmt.Sprintf(" in `%s`", r.Category)
+	}
+
+	status := "pending"
+	switch {
+	case r.Removed:
+		status = "removed by a moderator"
+	case r.Verified:
+		status = "verified"
+	}
+
+	return fmt.Sprintf(
+		"`%s` - **%s** - %s, submitted %s",
+		r.ID,
+		score,
+		status,
+		dates.Timestamp(r.CreatedAt, dates.Relative),
+	)
+}
+
+func (h *EventHandler) handleEventsWithdraw(c *commandContext) {
+	sid, _ := c.stringOption("submission-id")
+	sid = strings.TrimSpace(sid)
+	logger := c.logger.With(WithSubmissionID(sid))
+
+	notFound := fmt.Sprintf("You have no submission with the ID `%s`", sid)
+	if _, err := uuid.Parse(sid); err != nil {
+		c.r.errorOrLog(notFound)
+		return
+	}
+
+	record, err := h.EventScoreService.GetSubmission(sid)
+	if err != nil && !scores.AsErrNoRecord(err) {
+		logger.Error("could not fetch submission", zap.Error(err))
+		c.r.errorOrLog("Could not fetch the submission." + internalError)
+		return
+	}
+	// submissions of other users are reported missing the same as ones that don't exist
+	if err != nil || record.UID != c.uid() {
+		c.r.errorOrLog(notFound)
+		return
+	}
+
+	if record.Withdrawn {
+		c.r.errorOrLog("You withdrew this submission already")
+		return
+	}
+
+	if record.Removed {
+		c.r.errorOrLog("This submission was removed by a moderator, contest it with `/events appeal` if you disagree")
+		return
+	}
+
+	if record.Verified {
+		c.r.errorOrLog("This submission is verified already and can't be withdrawn, ask a moderator to remove it")
+		return
+	}
+
+	event, err := h.MetadataService.GetEvent(record.EID)
+	if err != nil {
+		logger.Error("could not fetch event", zap.Error(err))
+		c.r.errorOrLog("Could not fetch event information." + internalError)
+		return
+	}
+
+	if event.Finalized() {
+		c.r.errorOrLog("The results of this event are final, its submissions can't be withdrawn anymore")
+		return
+	}
+
+	err = h.EventScoreService.WithdrawUnverified(sid, record.PID)
+	if err != nil {
+		if scores.AsErrNoRecord(err) {
+			c.r.errorOrLog("This submission was just verified or removed and can't be withdrawn anymore")
+			return
+		}
+		logger.Error("could not withdraw submission", zap.Error(err))
+		c.r.errorOrLog("Could not withdraw the submission." + internalError)
+		return
+	}
+
+	h.recordHistory(scores.HistoryEntry{
+		SID:           sid,
+		Action:        scores.HistoryWithdrawn,
+		Actor:         c.uid(),
+		PreviousScore: record.RawScore,
+		Score:         record.RawScore,
+	}, logger)
+	h.Webhooks.Dispatch(c.i.GuildID, webhook.SubmissionWithdrawn, submissionPayload(record))
+	c.r.errorOrLog(fmt.Sprintf("Withdrew your submission `%s` of %d to '%s'", sid, record.Score, event.Name))
+}
@@ -0,0 +1,307 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/dates"
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/webhook"
+	"github.com/bwmarrin/discordgo"
+	"github.com/thoas/go-funk"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxRulesLength keeps the announcement of a new event within a single message
+	maxRulesLength = 1500
+	// maxNamePatternLength leaves room for the placeholders to be filled in
+	maxNamePatternLength = 80
+)
+
+var templateNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+func (h *EventHandler) templateSubcommands() []*subcommand {
+	nameOption := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "name",
+		Description: "Name of the template, e.g. `weekly-disruption`",
+		Required:    true,
+	}
+
+	return []*subcommand{
+		{
+			Name:        "create",
+			Description: "Create events on a schedule, e.g. a new weekly event every Sunday",
+			Options: []*discordgo.ApplicationCommandOption{
+				nameOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "event-name",
+					Description: "Name of each event, `{n}` is replaced with the run number and `{date}` with the start date",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "schedule",
+					Description: "When events start, e.g. `weekly sunday 18:00`, `monthly 1 8pm` or `cron 0 18 * * 0`",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "duration",
+					Description: "How long each event runs for, e.g. `2d` or `1w 12h`",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "type",
+					Description: "Type of the events, defaults to the default type of this server",
+					Choices:     eventTypeChoices(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "rules",
+					Description: "Rules posted along with the announcement of each event",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionChannel,
+					Name:        "channel",
+					Description: "Where new events are announced, defaults to the announcement channel of this server",
+				},
+			},
+			RoleAction: manageEventDialog,
+			Handler:    h.handleTemplateCreate,
+		},
+		{
+			Name:        "list",
+			Description: "List the templates of this server and when they create their next event",
+			Handler:     h.handleTemplateList,
+		},
+		{
+			Name:        "delete",
+			Description: "Stop creating events from a template, events already created are kept",
+			Options:     []*discordgo.ApplicationCommandOption{nameOption},
+			RoleAction:  manageEventDialog,
+			Handler:     h.handleTemplateDelete,
+		},
+	}
+}
+
+func (h *EventHandler) handleTemplateCreate(c *commandContext) {
+	name, _ := c.stringOption("name")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !templateNameRe.MatchString(name) {
+		c.r.errorOrLog(fmt.Sprintf(
+			"Invalid template name '%s', use up to 32 lowercase letters, digits and dashes",
+			name,
+		))
+		return
+	}
+
+	pattern, _ := c.stringOption("event-name")
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || len(pattern) > maxNamePatternLength {
+		c.r.errorOrLog(fmt.Sprintf("The event name must be between 1 and %d characters", maxNamePatternLength))
+		return
+	}
+
+	scheduleInput, _ := c.stringOption("schedule")
+	schedule, err := dates.ParseSchedule(scheduleInput)
+	if err != nil {
+		c.r.errorOrLog("Invalid schedule: " + err.Error())
+		return
+	}
+
+	durationInput, _ := c.stringOption("duration")
+	duration, err := dates.ParseDuration(durationInput)
+	if err != nil {
+		c.r.errorOrLog("Invalid duration: " + err.Error())
+		return
+	}
+
+	eType, ok := c.stringOption("type")
+	if !ok {
+		eType = h.guildSettings(c.i.GuildID).DefaultEventType
+	}
+	if !funk.Contains(supportedEventTypes, eType) {
+		c.r.errorOrLog("The type of the events must be given, unless a default type is set with `/config set`")
+		return
+	}
+
+	rules, _ := c.stringOption("rules")
+	rules = strings.TrimSpace(rules)
+	if len(rules) > maxRulesLength {
+		c.r.errorOrLog(fmt.Sprintf("The rules can be at most %d characters long", maxRulesLength))
+		return
+	}
+
+	channelID, _ := c.stringOption("channel")
+
+	loc := h.guildSettings(c.i.GuildID).Location()
+	template := &meta.EventTemplate{
+		GID:         c.i.GuildID,
+		Name:        name,
+		NamePattern: pattern,
+		EventType:   eType,
+		Duration:    duration,
+		Rules:       rules,
+		ChannelID:   channelID,
+		Schedule:    schedule.String(),
+		NextRun:     schedule.Next(time.Now(), loc),
+	}
+
+	if _, err := h.MetadataService.CreateEventTemplate(template); err != nil {
+		dup := &meta.ErrDuplicateEntry{}
+		if errors.As(err, &dup) {
+			c.r.errorOrLog(fmt.Sprintf("A template named '%s' already exists, delete it first to replace it", name))
+			return
+		}
+		c.logger.Error("could not create event template", zap.Error(err))
+		c.r.errorOrLog("Could not create the template." + internalError)
+		return
+	}
+
+	c.r.replyOrLog(fmt.Sprintf(
+		"Created template `%s`, the first event '%s' starts %s and runs until %s",
+		name,
+		template.EventName(template.NextRun.In(loc), 1),
+		dates.Display(template.NextRun),
+		dates.Timestamp(template.NextRun.Add(duration), dates.ShortDateTime),
+	))
+}
+
+func (h *EventHandler) handleTemplateList(c *commandContext) {
+	templates, err := h.MetadataService.ListEventTemplatesForGuild(c.i.GuildID)
+	if err != nil {
+		c.logger.Error("could not list event templates", zap.Error(err))
+		c.r.errorOrLog("Could not list the templates of this server." + internalError)
+		return
+	}
+
+	if len(templates) == 0 {
+		c.r.replyOrLog("This server has no event templates, create one with `/events template create`")
+		return
+	}
+
+	loc := h.guildSettings(c.i.GuildID).Location()
+	lines := make([]string, len(templates))
+	for i, v := range templates {
+		channel := "the announcement channel"
+		if v.ChannelID != "" {
+			channel = "<#" + v.ChannelID + ">"
+		}
+		lines[i] = fmt.Sprintf(
+			"`%s` - '%s' (%s) `%s` for %s, next %s, announced in %s",
+			v.Name,
+			v.EventName(v.NextRun.In(loc), v.Runs+1),
+			v.EventType,
+			v.Schedule,
+			dates.FormatDuration(v.Duration),
+			dates.Display(v.NextRun),
+			channel,
+		)
+	}
+
+	c.r.replyOrLog("Event templates of this server:\n" + strings.Join(lines, "\n"))
+}
+
+func (h *EventHandler) handleTemplateDelete(c *commandContext) {
+	name, _ := c.stringOption("name")
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if err := h.MetadataService.DeleteEventTemplate(c.i.GuildID, name); err != nil {
+		if meta.AsErrNoRecord(err) {
+			c.r.errorOrLog(fmt.Sprintf("This server has no template named '%s'", name))
+			return
+		}
+		c.logger.Error("could not delete event template", zap.Error(err))
+		c.r.errorOrLog("Could not delete the template." + internalError)
+		return
+	}
+
+	c.r.replyOrLog(fmt.Sprintf("Deleted template `%s`, events already created from it are kept", name))
+}
+
+func (h *EventHandler) runDueTemplates(s *discordgo.Session, now time.Time) {
+	logger := h.Logger.With(WithComponent("event-templates"))
+
+	templates, err := h.MetadataService.ListDueEventTemplates(now)
+	if err != nil {
+		logger.Error("could not list due event templates", zap.Error(err))
+		return
+	}
+
+	for _, v := range templates {
+		h.runTemplate(s, v, now, logger.With(WithGuildID(v.GID), zap.String("template", v.Name)))
+	}
+}
+
+// runTemplate creates the event of the due run of the template and moves it on to the next run.
+// Runs missed while the bot was down are skipped instead of being created all at once.
+func (h *EventHandler) runTemplate(s *discordgo.Session, t *meta.EventTemplate, now time.Time, logger *zap.Logger) {
+	schedule, err := dates.ParseSchedule(t.Schedule)
+	if err != nil {
+		logger.Error("could not read template schedule", zap.Error(err))
+		return
+	}
+
+	loc := h.guildSettings(t.GID).Location()
+	start, end := t.NextRun, t.NextRun.Add(t.Duration)
+
+	// the template is moved on before the event is created, a failure loses a run rather than
+	// creating the same event twice
+	err = h.MetadataService.AdvanceEventTemplate(t.ID, t.NextRun, schedule.Next(now, loc))
+	if err != nil {
+		if !meta.AsErrNoRecord(err) {
+			logger.Error("could not advance event template", zap.Error(err))
+		}
+		return
+	}
+
+	if !end.After(now) {
+		logger.Warn("skipped run of event template that would have ended already", zap.Time("start", start))
+		return
+	}
+
+	name := t.EventName(start.In(loc), t.Runs+1)
+	eid, err := h.MetadataService.CreateEvent(name, t.EventType, start, end, t.GID, true)
+	if err != nil {
+		logger.Error("could not create event from template", zap.Error(err))
+		return
+	}
+
+	logger = logger.With(WithEventID(eid))
+	logger.Info("created event from template")
+	h.dispatchEventWebhook(t.GID, webhook.EventCreated, eid, logger)
+
+	cid := t.ChannelID
+	if cid == "" {
+		cid = h.announcementChannel(t.GID, "")
+	}
+	if cid == "" {
+		return
+	}
+
+	msg := fmt.Sprintf(
+		"**%s** has started and runs until %s\nEvent ID: `%s`, join with `/events join`",
+		name,
+		dates.Display(end),
+		eid,
+	)
+	if t.Rules != "" {
+		msg += "\n\n**Rules**\n" + t.Rules
+	}
+
+	_, err = s.ChannelMessageSendComplex(cid, &discordgo.MessageSend{
+		Content: msg,
+		// the rules are whatever a moderator typed in, they shouldn't ping anyone
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		logger.Error("could not announce event created from template", zap.Error(err), WithChannelID(cid))
+	}
+}
@@ -0,0 +1,233 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/2785/warframe-assistant/internal/webhook"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+// webhookPingTimeout bounds `/webhooks test` so a slow receiver can't outlive the interaction token
+const webhookPingTimeout = 30 * time.Second
+
+func (h *EventHandler) webhookSubcommands() []*subcommand {
+	return []*subcommand{
+		{
+			Name:        "add",
+			Description: "Send event and submission updates of this server to a URL",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "url",
+					Description: "The https URL to POST the updates to, it must be reachable from the internet",
+					Required:    true,
+				},
+			},
+			RoleAction: manageEventDialog,
+			Handler:    h.handleWebhooksAdd,
+		},
+		{
+			Name:        "list",
+			Description: "List the webhooks of this server",
+			RoleAction:  manageEventDialog,
+			Handler:     h.handleWebhooksList,
+		},
+		{
+			Name:        "remove",
+			Description: "Stop sending updates to a webhook",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "webhook-id",
+					Description: "The ID of the webhook, see `/webhooks list`",
+					Required:    true,
+				},
+			},
+			RoleAction: manageEventDialog,
+			Handler:    h.handleWebhooksRemove,
+		},
+		{
+			Name:        "test",
+			Description: "Send a ping to every webhook of this server",
+			RoleAction:  manageEventDialog,
+			Handler:     h.handleWebhooksTest,
+		},
+	}
+}
+
+func (h *EventHandler) handleWebhooksAdd(c *commandContext) {
+	raw, ok := c.stringOption("url")
+	if !ok {
+		c.r.errorOrLog("`url` must be supplied")
+		return
+	}
+
+	u, err := webhook.ValidateURL(strings.TrimSpace(raw))
+	if err != nil {
+		c.r.errorOrLog(fmt.Sprintf("Invalid webhook URL, %s", err))
+		return
+	}
+
+	hook, err := h.MetadataService.CreateWebhook(c.i.GuildID, u.String())
+	if err != nil {
+		c.logger.Error("could not create webhook", zap.Error(err))
+		c.r.errorOrLog("Could not add the webhook." + internalError)
+		return
+	}
+
+	c.r.privateReplyOrLog(fmt.Sprintf(
+		"Added webhook `%s`. Every delivery carries a `%s` header with the HMAC-SHA256 of the body "+
+			"signed with this secret, keep it somewhere safe:\n```\n%s\n```",
+		hook.ID,
+		webhook.SignatureHeader,
+		hook.Secret,
+	))
+}
+
+func (h *EventHandler) handleWebhooksList(c *commandContext) {
+	hooks, err := h.MetadataService.ListWebhooksForGuild(c.i.GuildID)
+	if err != nil {
+		c.logger.Error("could not list webhooks", zap.Error(err))
+		c.r.errorOrLog("Could not list webhooks." + internalError)
+		return
+	}
+
+	if len(hooks) == 0 {
+		c.r.errorOrLog("There are no webhooks set up in this server, add one with `/webhooks add`")
+		return
+	}
+
+	lines := make([]string, len(hooks))
+	for i, v := range hooks {
+		lines[i] = fmt.Sprintf("`%s` - %s", v.ID, v.URL)
+	}
+
+	c.r.privateReplyOrLog(strings.Join(lines, "\n"))
+}
+
+func (h *EventHandler) handleWebhooksRemove(c *commandContext) {
+	id, ok := c.stringOption("webhook-id")
+	if !ok {
+		c.r.errorOrLog("`webhook-id` must be supplied")
+		return
+	}
+
+	err := h.MetadataService.DeleteWebhook(id, c.i.GuildID)
+	if err != nil {
+		if meta.AsErrNoRecord(err) {
+			c.r.errorOrLog("There's no webhook with that ID in this server")
+			return
+		}
+		c.logger.Error("could not delete webhook", zap.Error(err), zap.String("webhook-id", id))
+		c.r.errorOrLog("Could not remove the webhook." + internalError)
+		return
+	}
+
+	c.r.privateReplyOrLog("Webhook removed")
+}
+
+func (h *EventHandler) handleWebhooksTest(c *commandContext) {
+	if h.Webhooks == nil {
+		c.r.errorOrLog("Webhooks are not enabled on this bot")
+		return
+	}
+
+	if err := c.r.Defer(true); err != nil {
+		c.logger.Error("could not defer interaction response", zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookPingTimeout)
+	defer cancel()
+
+	hooks, results, err := h.Webhooks.Ping(ctx, c.i.GuildID)
+	if err != nil {
+		c.logger.Error("could not ping webhooks", zap.Error(err))
+		c.r.errorOrLog("Could not ping the webhooks." + internalError)
+		return
+	}
+
+	if len(hooks) == 0 {
+		c.r.errorOrLog("There are no webhooks set up in this server, add one with `/webhooks add`")
+		return
+	}
+
+	// only whether it worked is shown, what went wrong could tell about the network of the bot
+	lines := make([]string, len(hooks))
+	for i, v := range hooks {
+		status := ":white_check_mark:"
+		if err := results[v.ID]; err != nil {
+			c.logger.Info("webhook ping failed", zap.Error(err), zap.String("webhook-id", v.ID))
+			status = ":x: failed, check that the URL is right and the receiver answers with a 2xx status"
+		}
+		lines[i] = fmt.Sprintf("`%s` - %s - %s", v.ID, v.URL, status)
+	}
+
+	c.r.privateReplyOrLog(strings.Join(lines, "\n"))
+}
+
+// dispatchEventWebhook sends the current state of the event to the webhooks of the guild
+func (h *EventHandler) dispatchEventWebhook(gid, eventType, eid string, l *zap.Logger) {
+	if h.Webhooks == nil {
+		return
+	}
+
+	event, err := h.MetadataService.GetEvent(eid)
+	if err != nil {
+		l.Warn("could not fetch event for webhooks", zap.Error(err))
+		return
+	}
+
+	h.Webhooks.Dispatch(gid, eventType, webhook.ToEvent(event))
+}
+
+// submissionWebhook looks up what webhooks get to know about a submission, for deletions this has
+// to happen before the submission is gone.
+func (h *EventHandler) submissionWebhook(sid string, l *zap.Logger) (webhook.Submission, bool) {
+	if h.Webhooks == nil {
+		return webhook.Submission{}, false
+	}
+
+	record, err := h.EventScoreService.GetSubmission(sid)
+	if err != nil {
+		l.Warn("could not fetch submission for webhooks", zap.Error(err))
+		return webhook.Submission{}, false
+	}
+
+	return submissionPayload(record), true
+}
+
+func submissionPayload(record *scores.ScoreRecord) webhook.Submission {
+	return webhook.Submission{
+		ID:        record.ID,
+		EventID:   record.EID,
+		Category:  record.Category,
+		UserID:    record.UID,
+		IGN:       record.IGN,
+		Score:     record.Score,
+		RawScore:  record.RawScore,
+		Modifiers: record.Modifiers.Names(),
+		Proof:     record.Proof,
+	}
+}
+
+// dispatchSubmissionWebhook sends the current state of the submission to the webhooks of the guild
+func (h *EventHandler) dispatchSubmissionWebhook(gid, eventType, sid string, l *zap.Logger) {
+	if data, ok := h.submissionWebhook(sid, l); ok {
+		h.Webhooks.Dispatch(gid, eventType, data)
+	}
+}
+
+func (h *EventHandler) dispatchParticipantWebhook(c *commandContext, eventType string) {
+	h.Webhooks.Dispatch(c.i.GuildID, eventType, webhook.Participant{
+		EventID: c.eid,
+		UserID:  c.uid(),
+		IGN:     c.ign,
+	})
+}
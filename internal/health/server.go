@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// checkTimeout bounds how long a single readiness check may take
+const checkTimeout = 2 * time.Second
+
+// Check reports whether a dependency is usable, a nil error means it is
+type Check func(ctx context.Context) error
+
+// Server serves liveness, readiness and metrics endpoints for probes and scrapers.
+type Server struct {
+	// Checks are run on every readiness probe, keyed by the name they are reported under
+	Checks map[string]Check
+	Logger *zap.Logger
+}
+
+type checkResult struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Handler routes `/healthz`, `/readyz` and `/metrics`.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
+
+// handleHealthz only tells that the process is up and serving
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	results := make([]checkResult, 0, len(s.Checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, check := range s.Checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			res := checkResult{Name: name, OK: true}
+			if err := check(ctx); err != nil {
+				res.OK, res.Error = false, err.Error()
+				s.Logger.Warn("readiness check failed", zap.String("check", name), zap.Error(err))
+			}
+
+			mu.Lock()
+			results = append(results, res)
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	status := http.StatusOK
+	for _, v := range results {
+		if !v.OK {
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		s.Logger.Error("could not write readiness response", zap.Error(err))
+	}
+}
@@ -0,0 +1,58 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/2785/warframe-assistant/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReadyz(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ok := func(ctx context.Context) error { return nil }
+	broken := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	s := &health.Server{
+		Checks: map[string]health.Check{"postgres": ok, "cache": ok},
+		Logger: zap.NewNop(),
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(http.StatusOK, rec.Code)
+
+	s.Checks["gateway"] = broken
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(http.StatusServiceUnavailable, rec.Code)
+
+	results := []struct {
+		Name  string `json:"name"`
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}{}
+	require.NoError(json.Unmarshal(rec.Body.Bytes(), &results))
+	require.Len(results, 3)
+	assert.Equal("cache", results[0].Name)
+	assert.Equal("gateway", results[1].Name)
+	assert.False(results[1].OK)
+	assert.Equal("connection refused", results[1].Error)
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(http.StatusOK, rec.Code)
+}
@@ -74,6 +74,30 @@ func (s *CacheService) SetEventStatus(id string, status bool) error {
 	return s.Service.SetEventStatus(id, status)
 }
 
+func (s *CacheService) SetEventTieBreak(id, tieBreak string) error {
+	err := s.c.Drop("event:" + id)
+	if err != nil {
+		s.l.Error("could not delete entry from cache", zap.Error(err), zap.String("eid", id))
+	}
+	return s.Service.SetEventTieBreak(id, tieBreak)
+}
+
+func (s *CacheService) FinalizeEvent(id string) error {
+	err := s.c.Drop("event:" + id)
+	if err != nil {
+		s.l.Error("could not delete entry from cache", zap.Error(err), zap.String("eid", id))
+	}
+	return s.Service.FinalizeEvent(id)
+}
+
+func (s *CacheService) ArchiveEvent(id string) error {
+	err := s.c.Drop("event:" + id)
+	if err != nil {
+		s.l.Error("could not delete entry from cache", zap.Error(err), zap.String("eid", id))
+	}
+	return s.Service.ArchiveEvent(id)
+}
+
 func (s *CacheService) SetEventEndDate(id string, end time.Time) error {
 	err := s.c.Drop("event:" + id)
 	if err != nil {
@@ -82,6 +106,14 @@ func (s *CacheService) SetEventEndDate(id string, end time.Time) error {
 	return s.Service.SetEventEndDate(id, end)
 }
 
+func (s *CacheService) DeleteEvent(id string) error {
+	err := s.c.Drop("event:" + id)
+	if err != nil {
+		s.l.Error("could not delete entry from cache", zap.Error(err), zap.String("eid", id))
+	}
+	return s.Service.DeleteEvent(id)
+}
+
 func (s *CacheService) GetEvent(id string) (*Event, error) {
 	event := &Event{}
 	err := s.c.Once("event:"+id, event, func() (interface{}, error) {
@@ -1,7 +1,10 @@
 package meta
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -17,16 +20,26 @@ import (
 var _ Service = &PostgresService{}
 
 type PostgresService struct {
-	DB                 *sqlx.DB
-	ActionRoleTable    string
-	Logger             *zap.Logger
-	IGNTable           string
-	EventsTable        string
-	ParticipationTable string
+	DB                   *sqlx.DB
+	ActionRoleTable      string
+	Logger               *zap.Logger
+	IGNTable             string
+	EventsTable          string
+	ParticipationTable   string
+	LiveLeaderboardTable string
+	APIKeyTable          string
+	WebhookTable         string
+	EventTemplateTable   string
+	NotificationTable    string
 }
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+var eventColumns = []string{
+	"id", "guild_id", "name", "start_date", "end_date", "active", "event_type", "archived", "finalized_at",
+	"tie_break",
+}
+
 const pgErrUniqueConstraintViolation string = "23505"
 
 // GetRoleRequirementForGuild returns empty string if there's no requirement
@@ -161,6 +174,52 @@ func (ps *PostgresService) SetEventStatus(id string, status bool) error {
 	return err
 }
 
+func (ps *PostgresService) SetEventTieBreak(id, tieBreak string) error {
+	q := psql.Update(ps.EventsTable).Set("tie_break", tieBreak).Where(sq.Eq{"id": id})
+	_, err := q.RunWith(ps.DB).Exec()
+	return err
+}
+
+func (ps *PostgresService) FinalizeEvent(id string) error {
+	q := psql.Update(ps.EventsTable).
+		SetMap(map[string]interface{}{"finalized_at": sq.Expr("current_timestamp"), "active": false}).
+		Where(sq.Eq{"id": id, "finalized_at": nil})
+	res, err := q.RunWith(ps.DB).Exec()
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &ErrNoRecord{}
+	}
+	return nil
+}
+
+func (ps *PostgresService) ArchiveEvent(id string) error {
+	q := psql.Update(ps.EventsTable).
+		SetMap(map[string]interface{}{
+			"archived":     true,
+			"active":       false,
+			"finalized_at": sq.Expr("coalesce(finalized_at, current_timestamp)"),
+		}).
+		Where(sq.Eq{"id": id})
+	res, err := q.RunWith(ps.DB).Exec()
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &ErrNoRecord{}
+	}
+	return nil
+}
+
 func (ps *PostgresService) SetEventEndDate(id string, end time.Time) error {
 	q := psql.Update(ps.EventsTable).Set("end_date", end).Where(sq.Eq{"id": id})
 	_, err := q.RunWith(ps.DB).Exec()
@@ -168,7 +227,7 @@ func (ps *PostgresService) SetEventEndDate(id string, end time.Time) error {
 }
 
 func (ps *PostgresService) GetEvent(id string) (*Event, error) {
-	q := psql.Select("id", "guild_id", "name", "start_date", "end_date", "active", "event_type").
+	q := psql.Select(eventColumns...).
 		From(ps.EventsTable).
 		Where(sq.Eq{"id": id})
 	query, args, err := q.ToSql()
@@ -181,6 +240,9 @@ func (ps *PostgresService) GetEvent(id string) (*Event, error) {
 	err = ps.DB.Get(event, query, args...)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &ErrNoRecord{}
+		}
 		return nil, err
 	}
 
@@ -188,7 +250,7 @@ func (ps *PostgresService) GetEvent(id string) (*Event, error) {
 }
 
 func (ps *PostgresService) ListAllEvent() ([]*Event, error) {
-	q := psql.Select("id", "guild_id", "name", "start_date", "end_date", "active", "event_type").
+	q := psql.Select(eventColumns...).
 		From(ps.EventsTable)
 	query, args, err := q.ToSql()
 	if err != nil {
@@ -206,7 +268,7 @@ func (ps *PostgresService) ListAllEvent() ([]*Event, error) {
 }
 
 func (ps *PostgresService) ListEventsForGuild(gid string) ([]*Event, error) {
-	q := psql.Select("id", "guild_id", "name", "start_date", "end_date", "active", "event_type").
+	q := psql.Select(eventColumns...).
 		From(ps.EventsTable).
 		Where(sq.Eq{"guild_id": gid})
 	query, args, err := q.ToSql()
@@ -225,7 +287,7 @@ func (ps *PostgresService) ListEventsForGuild(gid string) ([]*Event, error) {
 }
 
 func (ps *PostgresService) ListActiveEventsForGuild(gid string) ([]*Event, error) {
-	q := psql.Select("id", "guild_id", "name", "start_date", "end_date", "active", "event_type").
+	q := psql.Select(eventColumns...).
 		From(ps.EventsTable).
 		Where(sq.Eq{"guild_id": gid, "active": true})
 	query, args, err := q.ToSql()
@@ -249,6 +311,52 @@ func (ps *PostgresService) DeleteEvent(id string) error {
 	return err
 }
 
+func (ps *PostgresService) ListEventsToRemind(endBy time.Time) ([]*Event, error) {
+	q := psql.Select(eventColumns...).
+		From(ps.EventsTable).
+		Where(sq.Eq{"active": true}).
+		Where("start_date <= current_timestamp AND end_date > current_timestamp").
+		Where(sq.LtOrEq{"end_date": endBy}).
+		Where("reminded_for IS DISTINCT FROM end_date")
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*Event{}
+
+	err = ps.DB.Select(&events, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ClaimEventReminder only claims the reminder of the end date the event still has, a reminder for
+// an end date that was changed meanwhile is left for the new one
+func (ps *PostgresService) ClaimEventReminder(id string, end time.Time) error {
+	res, err := psql.Update(ps.EventsTable).
+		Set("reminded_for", end).
+		Where(sq.Eq{"id": id, "end_date": end}).
+		Where("reminded_for IS DISTINCT FROM end_date").
+		RunWith(ps.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &ErrNoRecord{}
+	}
+
+	return nil
+}
+
 // Participation Crud
 
 func (ps *PostgresService) AddParticipation(
@@ -472,3 +580,330 @@ func (ps *PostgresService) UserBailedEvent(uid, eid string) (bool, error) {
 
 	return true, nil
 }
+
+// Live leaderboard CRUD
+
+// SetLiveLeaderboard records the live leaderboard message of an event, replacing the previous one
+// if there is any.
+func (ps *PostgresService) SetLiveLeaderboard(eid, channelID, messageID string) error {
+	q := psql.Insert(ps.LiveLeaderboardTable).
+		Columns("event_id", "channel_id", "message_id").
+		Values(eid, channelID, messageID).
+		Suffix("ON CONFLICT (event_id) DO UPDATE SET channel_id = excluded.channel_id, message_id = excluded.message_id")
+	_, err := q.RunWith(ps.DB).Exec()
+	return err
+}
+
+func (ps *PostgresService) GetLiveLeaderboard(eid string) (*LiveLeaderboard, error) {
+	q := psql.Select("event_id", "channel_id", "message_id").
+		From(ps.LiveLeaderboardTable).
+		Where(sq.Eq{"event_id": eid})
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	ll := &LiveLeaderboard{}
+	err = ps.DB.Get(ll, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &ErrNoRecord{}
+		}
+		return nil, err
+	}
+
+	return ll, nil
+}
+
+func (ps *PostgresService) DeleteLiveLeaderboard(eid string) error {
+	q := psql.Delete(ps.LiveLeaderboardTable).Where(sq.Eq{"event_id": eid})
+	_, err := q.RunWith(ps.DB).Exec()
+	return err
+}
+
+// API key CRUD
+
+const apiKeyPrefix = "wfa_"
+
+func (ps *PostgresService) CreateAPIKey(gid string) (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(b)
+
+	q := psql.Insert(ps.APIKeyTable).
+		Columns("guild_id", "key_hash").
+		Values(gid, hashAPIKey(key)).
+		Suffix("ON CONFLICT (guild_id) DO UPDATE SET key_hash = excluded.key_hash, created_at = current_timestamp")
+	_, err := q.RunWith(ps.DB).Exec()
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+func (ps *PostgresService) GetGuildForAPIKey(key string) (string, error) {
+	q := psql.Select("guild_id").From(ps.APIKeyTable).Where(sq.Eq{"key_hash": hashAPIKey(key)})
+	gid := ""
+	err := q.RunWith(ps.DB).Scan(&gid)
+	if err == sql.ErrNoRows {
+		return "", &ErrNoRecord{}
+	}
+
+	return gid, err
+}
+
+func (ps *PostgresService) DeleteAPIKey(gid string) error {
+	q := psql.Delete(ps.APIKeyTable).Where(sq.Eq{"guild_id": gid})
+	_, err := q.RunWith(ps.DB).Exec()
+	return err
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Webhook CRUD
+
+const webhookSecretPrefix = "whsec_"
+
+func (ps *PostgresService) CreateWebhook(gid, url string) (*Webhook, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	hook := &Webhook{GID: gid, URL: url, Secret: webhookSecretPrefix + hex.EncodeToString(b)}
+
+	q := psql.Insert(ps.WebhookTable).
+		Columns("guild_id", "url", "secret").
+		Values(hook.GID, hook.URL, hook.Secret).
+		Suffix("RETURNING id")
+	err := q.RunWith(ps.DB).QueryRow().Scan(&hook.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return hook, nil
+}
+
+func (ps *PostgresService) ListWebhooksForGuild(gid string) ([]*Webhook, error) {
+	q := psql.Select("id", "guild_id", "url", "secret").
+		From(ps.WebhookTable).
+		Where(sq.Eq{"guild_id": gid}).
+		OrderBy("created_at")
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := []*Webhook{}
+	err = ps.DB.Select(&hooks, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+// DeleteWebhook only deletes the webhook if it belongs to the guild
+func (ps *PostgresService) DeleteWebhook(id, gid string) error {
+	res, err := psql.Delete(ps.WebhookTable).
+		Where(sq.Eq{"id": id, "guild_id": gid}).
+		RunWith(ps.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &ErrNoRecord{}
+	}
+
+	return nil
+}
+
+// Event template CRUD
+
+var eventTemplateColumns = []string{
+	"id", "guild_id", "name", "name_pattern", "event_type", "duration", "rules", "channel_id", "schedule",
+	"next_run", "runs",
+}
+
+func (ps *PostgresService) CreateEventTemplate(t *EventTemplate) (string, error) {
+	q := psql.Insert(ps.EventTemplateTable).
+		SetMap(map[string]interface{}{
+			"guild_id":     t.GID,
+			"name":         t.Name,
+			"name_pattern": t.NamePattern,
+			"event_type":   t.EventType,
+			"duration":     t.Duration,
+			"rules":        t.Rules,
+			"channel_id":   t.ChannelID,
+			"schedule":     t.Schedule,
+			"next_run":     t.NextRun,
+		}).
+		Suffix("RETURNING id")
+
+	id := ""
+	err := q.RunWith(ps.DB).QueryRow().Scan(&id)
+	if err != nil {
+		pqErr := &pq.Error{}
+		if errors.As(err, &pqErr) && string(pqErr.Code) == pgErrUniqueConstraintViolation {
+			return "", &ErrDuplicateEntry{fmt.Sprintf("template with name '%s' already exists", t.Name)}
+		}
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (ps *PostgresService) ListEventTemplatesForGuild(gid string) ([]*EventTemplate, error) {
+	return ps.selectEventTemplates(sq.Eq{"guild_id": gid})
+}
+
+func (ps *PostgresService) ListDueEventTemplates(now time.Time) ([]*EventTemplate, error) {
+	return ps.selectEventTemplates(sq.LtOrEq{"next_run": now})
+}
+
+func (ps *PostgresService) selectEventTemplates(where sq.Sqlizer) ([]*EventTemplate, error) {
+	q := psql.Select(eventTemplateColumns...).
+		From(ps.EventTemplateTable).
+		Where(where).
+		OrderBy("next_run", "name")
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []*EventTemplate{}
+	err = ps.DB.Select(&templates, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (ps *PostgresService) DeleteEventTemplate(gid, name string) error {
+	res, err := psql.Delete(ps.EventTemplateTable).
+		Where(sq.Eq{"guild_id": gid, "name": name}).
+		RunWith(ps.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &ErrNoRecord{}
+	}
+
+	return nil
+}
+
+func (ps *PostgresService) AdvanceEventTemplate(id string, prev, next time.Time) error {
+	res, err := psql.Update(ps.EventTemplateTable).
+		SetMap(map[string]interface{}{"next_run": next, "runs": sq.Expr("runs + 1")}).
+		Where(sq.Eq{"id": id, "next_run": prev}).
+		RunWith(ps.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &ErrNoRecord{}
+	}
+
+	return nil
+}
+
+// Notification preferences CRUD
+
+func (ps *PostgresService) GetNotificationPreferences(uid string) (*NotificationPreferences, error) {
+	q := psql.Select("user_id", "submissions", "events", "reminders", "dms_closed").
+		From(ps.NotificationTable).
+		Where(sq.Eq{"user_id": uid})
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &NotificationPreferences{}
+	err = ps.DB.Get(p, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &NotificationPreferences{UID: uid}, nil
+		}
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (ps *PostgresService) SetNotificationPreferences(p *NotificationPreferences) error {
+	_, err := psql.Insert(ps.NotificationTable).
+		Columns("user_id", "submissions", "events", "reminders", "dms_closed").
+		Values(p.UID, p.Submissions, p.Events, p.Reminders, false).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE SET
+			submissions = excluded.submissions,
+			events = excluded.events,
+			reminders = excluded.reminders,
+			dms_closed = false`).
+		RunWith(ps.DB).
+		Exec()
+	return err
+}
+
+func (ps *PostgresService) SetDMsClosed(uid string) error {
+	q := psql.Update(ps.NotificationTable).Set("dms_closed", true).Where(sq.Eq{"user_id": uid})
+	_, err := q.RunWith(ps.DB).Exec()
+	return err
+}
+
+func (ps *PostgresService) ListUsersToNotify(uids []string, kind NotificationKind) ([]string, error) {
+	// the kind ends up as a column name, so it can't be anything but one of the known ones
+	known := false
+	for _, v := range NotificationKinds {
+		known = known || v == kind
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown notification kind '%s'", kind)
+	}
+
+	out := []string{}
+	if len(uids) == 0 {
+		return out, nil
+	}
+
+	q := psql.Select("user_id").
+		From(ps.NotificationTable).
+		Where(sq.Eq{"user_id": uids, string(kind): true, "dms_closed": false}).
+		OrderBy("user_id")
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = ps.DB.Select(&out, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/ory/dockertest/v3"
 	"github.com/stretchr/testify/assert"
@@ -116,7 +117,11 @@ func TestEventCrud(t *testing.T) {
 		start_date timestamptz DEFAULT current_timestamp,
 		end_date timestamptz NOT NULL,
 		active boolean,
-		event_type text
+		event_type text,
+		archived boolean NOT NULL DEFAULT FALSE,
+		finalized_at timestamptz,
+		tie_break text NOT NULL DEFAULT '',
+		reminded_for timestamptz
 	);
 	`)
 
@@ -209,6 +214,46 @@ func TestEventCrud(t *testing.T) {
 	event, err = s.GetEvent(eid)
 	assert.NoError(err)
 	assert.Equal("New Event Name", event.Name)
+	assert.False(event.Archived)
+	assert.False(event.Finalized())
+	assert.Empty(event.TieBreak)
+
+	err = s.SetEventTieBreak(eid, "fewest-submissions")
+	assert.NoError(err)
+	event, err = s.GetEvent(eid)
+	assert.NoError(err)
+	assert.Equal("fewest-submissions", event.TieBreak)
+
+	// finalizing deactivates the event, once
+	err = s.SetEventStatus(eid, true)
+	assert.NoError(err)
+	err = s.FinalizeEvent(eid)
+	assert.NoError(err)
+
+	event, err = s.GetEvent(eid)
+	assert.NoError(err)
+	assert.True(event.Finalized())
+	assert.False(event.Active)
+	finalizedAt := *event.FinalizedAt
+
+	err = s.FinalizeEvent(eid)
+	assert.True(meta.AsErrNoRecord(err))
+
+	// archiving keeps the event final, it can still be looked up
+	err = s.ArchiveEvent(eid)
+	assert.NoError(err)
+
+	event, err = s.GetEvent(eid)
+	assert.NoError(err)
+	assert.True(event.Archived)
+	assert.False(event.Active)
+	assert.True(finalizedAt.Equal(*event.FinalizedAt))
+
+	err = s.ArchiveEvent(uuid.NewString())
+	assert.True(meta.AsErrNoRecord(err))
+
+	_, err = s.GetEvent(uuid.NewString())
+	assert.True(meta.AsErrNoRecord(err))
 
 	// We delete an event
 	err = s.DeleteEvent(eid)
@@ -229,7 +274,10 @@ func TestParticipation(t *testing.T) {
 		start_date timestamptz DEFAULT current_timestamp,
 		end_date timestamptz NOT NULL,
 		active boolean,
-		event_type text
+		event_type text,
+		archived boolean NOT NULL DEFAULT FALSE,
+		finalized_at timestamptz,
+		tie_break text NOT NULL DEFAULT ''
 	);
 
 	CREATE TABLE users_test_par (
@@ -372,3 +420,374 @@ func TestParticipation(t *testing.T) {
 	assert.NoError(err)
 	assert.True(in)
 }
+
+func TestLiveLeaderboard(t *testing.T) {
+	db.MustExec(`
+	CREATE TABLE events_test_ll (
+		id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+		guild_id text NOT NULL,
+		name text NOT NULL,
+		start_date timestamptz DEFAULT current_timestamp,
+		end_date timestamptz NOT NULL,
+		active boolean,
+		event_type text,
+		archived boolean NOT NULL DEFAULT FALSE,
+		finalized_at timestamptz,
+		tie_break text NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE live_leaderboards_test (
+		event_id uuid PRIMARY KEY,
+		channel_id text NOT NULL,
+		message_id text NOT NULL,
+		FOREIGN KEY (event_id) REFERENCES events_test_ll(id) ON DELETE CASCADE
+	);
+	`)
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := &meta.PostgresService{
+		DB:                   db,
+		Logger:               zap.NewNop(),
+		EventsTable:          "events_test_ll",
+		LiveLeaderboardTable: "live_leaderboards_test",
+	}
+
+	eid, err := s.CreateEvent(
+		"Test Event",
+		"scoreboard-campaign",
+		time.Now(),
+		time.Now().Add(10*time.Minute),
+		"guild-id",
+		true,
+	)
+	require.NoError(err)
+
+	// no live leaderboard yet
+	_, err = s.GetLiveLeaderboard(eid)
+	assert.True(meta.AsErrNoRecord(err))
+
+	err = s.SetLiveLeaderboard(eid, "channel-1", "message-1")
+	assert.NoError(err)
+
+	ll, err := s.GetLiveLeaderboard(eid)
+	assert.NoError(err)
+	assert.Equal(&meta.LiveLeaderboard{EID: eid, ChannelID: "channel-1", MessageID: "message-1"}, ll)
+
+	// posting a new one replaces the old one
+	err = s.SetLiveLeaderboard(eid, "channel-2", "message-2")
+	assert.NoError(err)
+
+	ll, err = s.GetLiveLeaderboard(eid)
+	assert.NoError(err)
+	assert.Equal("channel-2", ll.ChannelID)
+	assert.Equal("message-2", ll.MessageID)
+
+	err = s.DeleteLiveLeaderboard(eid)
+	assert.NoError(err)
+
+	_, err = s.GetLiveLeaderboard(eid)
+	assert.True(meta.AsErrNoRecord(err))
+
+	// deleting the event takes the live leaderboard with it
+	err = s.SetLiveLeaderboard(eid, "channel-1", "message-1")
+	assert.NoError(err)
+	err = s.DeleteEvent(eid)
+	assert.NoError(err)
+
+	_, err = s.GetLiveLeaderboard(eid)
+	assert.True(meta.AsErrNoRecord(err))
+}
+
+func TestAPIKeys(t *testing.T) {
+	db.MustExec(`
+	CREATE TABLE api_keys_test (
+		guild_id text PRIMARY KEY,
+		key_hash text NOT NULL UNIQUE,
+		created_at timestamptz DEFAULT current_timestamp
+	);
+	`)
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := &meta.PostgresService{DB: db, Logger: zap.NewNop(), APIKeyTable: "api_keys_test"}
+
+	key1, err := s.CreateAPIKey("guild-1")
+	require.NoError(err)
+	assert.NotEmpty(key1)
+
+	key2, err := s.CreateAPIKey("guild-2")
+	require.NoError(err)
+	assert.NotEqual(key1, key2)
+
+	gid, err := s.GetGuildForAPIKey(key1)
+	assert.NoError(err)
+	assert.Equal("guild-1", gid)
+
+	_, err = s.GetGuildForAPIKey("not-a-key")
+	assert.True(meta.AsErrNoRecord(err))
+
+	// rotating the key invalidates the old one
+	rotated, err := s.CreateAPIKey("guild-1")
+	require.NoError(err)
+
+	_, err = s.GetGuildForAPIKey(key1)
+	assert.True(meta.AsErrNoRecord(err))
+
+	gid, err = s.GetGuildForAPIKey(rotated)
+	assert.NoError(err)
+	assert.Equal("guild-1", gid)
+
+	err = s.DeleteAPIKey("guild-1")
+	assert.NoError(err)
+
+	_, err = s.GetGuildForAPIKey(rotated)
+	assert.True(meta.AsErrNoRecord(err))
+
+	// other guilds are unaffected
+	gid, err = s.GetGuildForAPIKey(key2)
+	assert.NoError(err)
+	assert.Equal("guild-2", gid)
+}
+
+func TestWebhooks(t *testing.T) {
+	db.MustExec(`
+	CREATE TABLE webhooks_test (
+		id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+		guild_id text NOT NULL,
+		url text NOT NULL,
+		secret text NOT NULL,
+		created_at timestamptz DEFAULT current_timestamp
+	);
+	`)
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := &meta.PostgresService{DB: db, Logger: zap.NewNop(), WebhookTable: "webhooks_test"}
+
+	hook1, err := s.CreateWebhook("guild-1", "https://example.com/hook-1")
+	require.NoError(err)
+	assert.NotEmpty(hook1.ID)
+	assert.NotEmpty(hook1.Secret)
+
+	hook2, err := s.CreateWebhook("guild-1", "https://example.com/hook-2")
+	require.NoError(err)
+	assert.NotEqual(hook1.Secret, hook2.Secret)
+
+	_, err = s.CreateWebhook("guild-2", "https://example.com/hook-3")
+	require.NoError(err)
+
+	hooks, err := s.ListWebhooksForGuild("guild-1")
+	assert.NoError(err)
+	assert.Equal([]*meta.Webhook{hook1, hook2}, hooks)
+
+	// webhooks of other guilds can't be deleted
+	err = s.DeleteWebhook(hook1.ID, "guild-2")
+	assert.True(meta.AsErrNoRecord(err))
+
+	err = s.DeleteWebhook(hook1.ID, "guild-1")
+	assert.NoError(err)
+
+	hooks, err = s.ListWebhooksForGuild("guild-1")
+	assert.NoError(err)
+	assert.Equal([]*meta.Webhook{hook2}, hooks)
+}
+
+func TestEventTemplates(t *testing.T) {
+	db.MustExec(`
+	CREATE TABLE event_templates_test (
+		id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+		guild_id text NOT NULL,
+		name text NOT NULL,
+		name_pattern text NOT NULL,
+		event_type text NOT NULL,
+		duration bigint NOT NULL,
+		rules text NOT NULL DEFAULT '',
+		channel_id text NOT NULL DEFAULT '',
+		schedule text NOT NULL,
+		next_run timestamptz NOT NULL,
+		runs int NOT NULL DEFAULT 0,
+		UNIQUE (guild_id, name)
+	);
+	`)
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := &meta.PostgresService{DB: db, Logger: zap.NewNop(), EventTemplateTable: "event_templates_test"}
+
+	sunday := time.Date(2022, time.July, 3, 18, 0, 0, 0, time.UTC)
+	weekly := &meta.EventTemplate{
+		GID:         "guild-1",
+		Name:        "weekly",
+		NamePattern: "Weekly Disruption #{n} ({date})",
+		EventType:   meta.EventTypeScoreLeaderboard,
+		Duration:    48 * time.Hour,
+		Rules:       "Solo only",
+		Schedule:    "weekly sunday 18:00",
+		NextRun:     sunday,
+	}
+
+	id, err := s.CreateEventTemplate(weekly)
+	require.NoError(err)
+	weekly.ID = id
+
+	_, err = s.CreateEventTemplate(weekly)
+	dup := &meta.ErrDuplicateEntry{}
+	assert.ErrorAs(err, &dup)
+
+	// the same name is fine in another guild
+	_, err = s.CreateEventTemplate(&meta.EventTemplate{
+		GID:         "guild-2",
+		Name:        "weekly",
+		NamePattern: "Weekly",
+		EventType:   meta.EventTypeScoreCampaign,
+		Duration:    time.Hour,
+		Schedule:    "weekly monday 18:00",
+		NextRun:     sunday.Add(24 * time.Hour),
+	})
+	require.NoError(err)
+
+	templates, err := s.ListEventTemplatesForGuild("guild-1")
+	require.NoError(err)
+	require.Len(templates, 1)
+	assert.Equal(weekly.Duration, templates[0].Duration)
+	assert.True(sunday.Equal(templates[0].NextRun))
+	assert.Equal("Weekly Disruption #1 (2022-07-03)", templates[0].EventName(sunday, 1))
+
+	due, err := s.ListDueEventTemplates(sunday.Add(-time.Minute))
+	require.NoError(err)
+	assert.Empty(due)
+
+	due, err = s.ListDueEventTemplates(sunday)
+	require.NoError(err)
+	require.Len(due, 1)
+	assert.Equal(id, due[0].ID)
+
+	next := sunday.Add(7 * 24 * time.Hour)
+	require.NoError(s.AdvanceEventTemplate(id, due[0].NextRun, next))
+	// a second replica picking up the same run loses the race
+	assert.True(meta.AsErrNoRecord(s.AdvanceEventTemplate(id, due[0].NextRun, next)))
+
+	templates, err = s.ListEventTemplatesForGuild("guild-1")
+	require.NoError(err)
+	assert.Equal(1, templates[0].Runs)
+	assert.True(next.Equal(templates[0].NextRun))
+
+	assert.True(meta.AsErrNoRecord(s.DeleteEventTemplate("guild-2", "daily")))
+	require.NoError(s.DeleteEventTemplate("guild-1", "weekly"))
+
+	templates, err = s.ListEventTemplatesForGuild("guild-1")
+	require.NoError(err)
+	assert.Empty(templates)
+}
+
+func TestEventReminders(t *testing.T) {
+	db.MustExec(`
+	CREATE TABLE events_test_reminders (
+		id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+		guild_id text NOT NULL,
+		name text NOT NULL,
+		start_date timestamptz DEFAULT current_timestamp,
+		end_date timestamptz NOT NULL,
+		active boolean,
+		event_type text,
+		archived boolean NOT NULL DEFAULT FALSE,
+		finalized_at timestamptz,
+		tie_break text NOT NULL DEFAULT '',
+		reminded_for timestamptz
+	);
+	`)
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := &meta.PostgresService{DB: db, Logger: zap.NewNop(), EventsTable: "events_test_reminders"}
+
+	now := time.Now()
+	closing, err := s.CreateEvent("closing", meta.EventTypeScoreCampaign, now.Add(-time.Hour), now.Add(time.Hour), "guild-1", true)
+	require.NoError(err)
+	_, err = s.CreateEvent("later", meta.EventTypeScoreCampaign, now.Add(-time.Hour), now.Add(72*time.Hour), "guild-1", true)
+	require.NoError(err)
+	_, err = s.CreateEvent("inactive", meta.EventTypeScoreCampaign, now.Add(-time.Hour), now.Add(time.Hour), "guild-1", false)
+	require.NoError(err)
+
+	events, err := s.ListEventsToRemind(now.Add(24 * time.Hour))
+	require.NoError(err)
+	require.Len(events, 1)
+	assert.Equal(closing, events[0].ID)
+
+	require.NoError(s.ClaimEventReminder(closing, events[0].End))
+	// whoever comes second doesn't get to send the reminders again
+	assert.True(meta.AsErrNoRecord(s.ClaimEventReminder(closing, events[0].End)))
+
+	events, err = s.ListEventsToRemind(now.Add(24 * time.Hour))
+	require.NoError(err)
+	assert.Empty(events)
+
+	// moving the deadline means another reminder for the new one
+	require.NoError(s.SetEventEndDate(closing, now.Add(2*time.Hour)))
+	events, err = s.ListEventsToRemind(now.Add(24 * time.Hour))
+	require.NoError(err)
+	require.Len(events, 1)
+	assert.Equal(closing, events[0].ID)
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	db.MustExec(`
+	CREATE TABLE notification_preferences_test (
+		user_id text PRIMARY KEY,
+		submissions boolean NOT NULL DEFAULT FALSE,
+		events boolean NOT NULL DEFAULT FALSE,
+		reminders boolean NOT NULL DEFAULT FALSE,
+		dms_closed boolean NOT NULL DEFAULT FALSE
+	);
+	`)
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := &meta.PostgresService{DB: db, Logger: zap.NewNop(), NotificationTable: "notification_preferences_test"}
+
+	// nothing is sent to users who never opted in
+	p, err := s.GetNotificationPreferences("user-1")
+	require.NoError(err)
+	assert.Equal(&meta.NotificationPreferences{UID: "user-1"}, p)
+
+	require.NoError(s.SetNotificationPreferences(&meta.NotificationPreferences{UID: "user-1", Submissions: true}))
+	require.NoError(s.SetNotificationPreferences(&meta.NotificationPreferences{
+		UID:         "user-2",
+		Submissions: true,
+		Reminders:   true,
+	}))
+
+	uids, err := s.ListUsersToNotify([]string{"user-1", "user-2", "user-3"}, meta.NotifySubmissions)
+	require.NoError(err)
+	assert.Equal([]string{"user-1", "user-2"}, uids)
+
+	uids, err = s.ListUsersToNotify([]string{"user-1", "user-2", "user-3"}, meta.NotifyReminders)
+	require.NoError(err)
+	assert.Equal([]string{"user-2"}, uids)
+
+	_, err = s.ListUsersToNotify([]string{"user-1"}, "submissions; DROP TABLE users")
+	assert.Error(err)
+
+	require.NoError(s.SetDMsClosed("user-2"))
+	uids, err = s.ListUsersToNotify([]string{"user-1", "user-2"}, meta.NotifySubmissions)
+	require.NoError(err)
+	assert.Equal([]string{"user-1"}, uids)
+
+	// changing the preferences lifts the hold
+	p, err = s.GetNotificationPreferences("user-2")
+	require.NoError(err)
+	assert.True(p.DMsClosed)
+	p.Reminders = false
+	require.NoError(s.SetNotificationPreferences(p))
+
+	p, err = s.GetNotificationPreferences("user-2")
+	require.NoError(err)
+	assert.Equal(&meta.NotificationPreferences{UID: "user-2", Submissions: true}, p)
+}
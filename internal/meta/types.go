@@ -2,6 +2,8 @@ package meta
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,6 +12,11 @@ type Service interface {
 	IGNService
 	EventService
 	ParticipationService
+	LiveLeaderboardService
+	APIKeyService
+	WebhookService
+	EventTemplateService
+	NotificationService
 }
 
 type IGNService interface {
@@ -30,11 +37,26 @@ type EventService interface {
 	UpdateEvent(id, name, eventType string, start, end time.Time, gid string, active bool) error
 	SetEventStatus(id string, status bool) error
 	SetEventEndDate(id string, end time.Time) error
+	// SetEventTieBreak sets how ties on the leaderboard of the event are broken, empty means the
+	// default of sharing the rank
+	SetEventTieBreak(id, tieBreak string) error
+	// FinalizeEvent deactivates the event for good, its standings are final from then on.
+	// Returns ErrNoRecord if the event doesn't exist or is already final.
+	FinalizeEvent(id string) error
+	// ArchiveEvent finalizes the event if it isn't already and hides it from the event lists, it
+	// can still be looked up by ID
+	ArchiveEvent(id string) error
 	GetEvent(id string) (*Event, error)
 	ListAllEvent() ([]*Event, error)
 	ListEventsForGuild(gid string) ([]*Event, error)
 	ListActiveEventsForGuild(gid string) ([]*Event, error)
 	DeleteEvent(id string) error
+	// ListEventsToRemind lists the active events that started and end by the given time, leaving
+	// out those whose participants were already reminded of their current end date
+	ListEventsToRemind(endBy time.Time) ([]*Event, error)
+	// ClaimEventReminder marks the participants of the event as reminded of the given end date.
+	// Returns ErrNoRecord if they already were, so only one caller sends the reminders.
+	ClaimEventReminder(id string, end time.Time) error
 }
 
 type ParticipationService interface {
@@ -48,6 +70,147 @@ type ParticipationService interface {
 	GetParticipation(uid, eid string) (string, bool, error)
 }
 
+// LiveLeaderboardService keeps track of the message for each event that the bot keeps up to date
+// with the current leaderboard.
+type LiveLeaderboardService interface {
+	SetLiveLeaderboard(eid, channelID, messageID string) error
+	GetLiveLeaderboard(eid string) (*LiveLeaderboard, error)
+	DeleteLiveLeaderboard(eid string) error
+}
+
+// APIKeyService manages the keys guilds use to read their data through the HTTP API, there is at
+// most one key per guild and only a hash of it is stored.
+type APIKeyService interface {
+	// CreateAPIKey generates a new key for the guild, replacing the previous one
+	CreateAPIKey(gid string) (string, error)
+	GetGuildForAPIKey(key string) (string, error)
+	DeleteAPIKey(gid string) error
+}
+
+// WebhookService manages the outgoing webhooks of each guild.
+type WebhookService interface {
+	// CreateWebhook registers a new webhook for the guild with a freshly generated signing secret
+	CreateWebhook(gid, url string) (*Webhook, error)
+	ListWebhooksForGuild(gid string) ([]*Webhook, error)
+	DeleteWebhook(id, gid string) error
+}
+
+// EventTemplateService manages the templates recurring events are created from, templates are
+// looked up by their name within the guild.
+type EventTemplateService interface {
+	CreateEventTemplate(t *EventTemplate) (string, error)
+	ListEventTemplatesForGuild(gid string) ([]*EventTemplate, error)
+	DeleteEventTemplate(gid, name string) error
+	// ListDueEventTemplates lists the templates of every guild whose next event is due by now
+	ListDueEventTemplates(now time.Time) ([]*EventTemplate, error)
+	// AdvanceEventTemplate moves the next run of the template from prev on to next and counts the
+	// run. Returns ErrNoRecord if the template is gone or was already advanced past prev, so only
+	// one caller gets to create the event of a run.
+	AdvanceEventTemplate(id string, prev, next time.Time) error
+}
+
+// NotificationService keeps what each user wants to be sent DMs about, everything is off until the
+// user opts in.
+type NotificationService interface {
+	// GetNotificationPreferences returns the preferences of the user, all off if they never set any
+	GetNotificationPreferences(uid string) (*NotificationPreferences, error)
+	// SetNotificationPreferences saves the preferences of the user and lifts any hold on their DMs
+	SetNotificationPreferences(p *NotificationPreferences) error
+	// SetDMsClosed holds off any DMs to the user until they change their preferences again
+	SetDMsClosed(uid string) error
+	// ListUsersToNotify filters the users down to those who want the kind of notification and
+	// whose DMs aren't held off
+	ListUsersToNotify(uids []string, kind NotificationKind) ([]string, error)
+}
+
+type Webhook struct {
+	ID     string `db:"id"`
+	GID    string `db:"guild_id"`
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// EventTemplate is what recurring events are created from, a new event is created every time
+// the schedule comes around
+type EventTemplate struct {
+	ID   string `db:"id"`
+	GID  string `db:"guild_id"`
+	Name string `db:"name"`
+	// NamePattern is what the events are named, see EventName for the placeholders
+	NamePattern string `db:"name_pattern"`
+	EventType   string `db:"event_type"`
+	// Duration is how long each event runs for, stored in nanoseconds
+	Duration time.Duration `db:"duration"`
+	Rules    string        `db:"rules"`
+	// ChannelID is where new events are announced, empty for the announcement channel of the guild
+	ChannelID string `db:"channel_id"`
+	// Schedule is the recurrence as the user gave it, see dates.ParseSchedule
+	Schedule string    `db:"schedule"`
+	NextRun  time.Time `db:"next_run"`
+	// Runs counts the events created from the template so far
+	Runs int `db:"runs"`
+}
+
+// EventName names the event of a run starting at start, `{date}` in the pattern is replaced with
+// the start date and `{n}` with the number of the run, counting from 1
+func (t *EventTemplate) EventName(start time.Time, run int) string {
+	return strings.NewReplacer(
+		"{date}", start.Format("2006-01-02"),
+		"{n}", strconv.Itoa(run),
+	).Replace(t.NamePattern)
+}
+
+// NotificationKind is a group of DMs users opt in to together
+type NotificationKind string
+
+const (
+	// NotifySubmissions is sent when a moderator verifies, rejects or removes a submission
+	NotifySubmissions NotificationKind = "submissions"
+	// NotifyEvents is sent when an event the user joined opens, closes or has its results finalized
+	NotifyEvents NotificationKind = "events"
+	// NotifyReminders is sent ahead of an event the user joined closing
+	NotifyReminders NotificationKind = "reminders"
+)
+
+// NotificationKinds lists every kind of notification
+var NotificationKinds = []NotificationKind{NotifySubmissions, NotifyEvents, NotifyReminders}
+
+type NotificationPreferences struct {
+	UID         string `db:"user_id"`
+	Submissions bool   `db:"submissions"`
+	Events      bool   `db:"events"`
+	Reminders   bool   `db:"reminders"`
+	// DMsClosed is set when a DM to the user could not be delivered, no more are sent until the
+	// user changes their preferences
+	DMsClosed bool `db:"dms_closed"`
+}
+
+// Enabled reports whether the user opted in to the kind of notification
+func (p *NotificationPreferences) Enabled(kind NotificationKind) bool {
+	switch kind {
+	case NotifySubmissions:
+		return p.Submissions
+	case NotifyEvents:
+		return p.Events
+	case NotifyReminders:
+		return p.Reminders
+	default:
+		return false
+	}
+}
+
+type LiveLeaderboard struct {
+	EID       string `db:"event_id"`
+	ChannelID string `db:"channel_id"`
+	MessageID string `db:"message_id"`
+}
+
+const (
+	EventTypeTournament       string = "tournament"
+	EventTypeScoreCampaign    string = "scoreboard-campaign"
+	EventTypeScoreLeaderboard string = "scoreboard-leaderboard"
+)
+
 type Event struct {
 	ID        string    `db:"id"`
 	GID       string    `db:"guild_id"`
@@ -56,6 +219,16 @@ type Event struct {
 	End       time.Time `db:"end_date"`
 	Active    bool      `db:"active"`
 	EventType string    `db:"event_type"`
+	Archived  bool      `db:"archived"`
+	// FinalizedAt is when the standings of the event were made final, nil while they can change
+	FinalizedAt *time.Time `db:"finalized_at"`
+	// TieBreak is how users with the same score are ranked, empty for the default
+	TieBreak string `db:"tie_break"`
+}
+
+// Finalized reports whether the standings of the event are final
+func (e *Event) Finalized() bool {
+	return e.FinalizedAt != nil
 }
 
 var _ error = &ErrNoRecord{}
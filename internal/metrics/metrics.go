@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+const namespace = "warframe_assistant"
+
+var (
+	Commands = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "commands_total",
+		Help:      "Slash commands handled, by command",
+	}, []string{"command"})
+
+	CommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "command_duration_seconds",
+		Help:      "Time taken to handle slash commands, by command",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command"})
+
+	Buttons = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "buttons_total",
+		Help:      "Button interactions handled, by button",
+	}, []string{"button"})
+
+	ButtonDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "button_duration_seconds",
+		Help:      "Time taken to handle button interactions, by button",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"button"})
+
+	Submissions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "submissions_total",
+		Help:      "Score submissions received, by result",
+	}, []string{"result"})
+
+	SubmissionDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "submission_duration_seconds",
+		Help:      "Time taken to handle score submissions",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	Verifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "verifications_total",
+		Help:      "Submissions processed by moderators, by action",
+	}, []string{"action"})
+)
+
+// Verification actions
+const (
+	VerificationVerify = "verify"
+	VerificationReject = "reject"
+	VerificationRemove = "remove"
+	VerificationAmend  = "amend"
+)
+
+// Submission results
+const (
+	SubmissionAccepted = "accepted"
+	SubmissionRejected = "rejected"
+	SubmissionError    = "error"
+)
+
+// ObserveCommand counts a handled command and how long it took since start, meant to be deferred.
+func ObserveCommand(command string, start time.Time) {
+	Commands.WithLabelValues(command).Inc()
+	CommandDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+}
+
+// ObserveButton counts a handled button interaction and how long it took since start, meant to
+// be deferred.
+func ObserveButton(button string, start time.Time) {
+	Buttons.WithLabelValues(button).Inc()
+	ButtonDuration.WithLabelValues(button).Observe(time.Since(start).Seconds())
+}
+
+var _ prometheus.Collector = &BacklogCollector{}
+
+// BacklogCollector reports the number of submissions waiting on verification for every active
+// event, the numbers are queried on scrape. Every replica sees the same numbers, so only the one
+// that leads reports them.
+type BacklogCollector struct {
+	events meta.EventService
+	scores scores.ScoresService
+	leader func() bool
+	logger *zap.Logger
+
+	pending *prometheus.Desc
+}
+
+// NewBacklogCollector reports the backlog whenever leader returns true
+func NewBacklogCollector(
+	events meta.EventService,
+	scores scores.ScoresService,
+	leader func() bool,
+	logger *zap.Logger,
+) *BacklogCollector {
+	return &BacklogCollector{
+		events: events,
+		scores: scores,
+		leader: leader,
+		logger: logger,
+		pending: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "pending_verifications"),
+			"Submissions waiting on verification, by active event",
+			[]string{"guild_id", "event_id"},
+			nil,
+		),
+	}
+}
+
+func (c *BacklogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.pending
+}
+
+func (c *BacklogCollector) Collect(ch chan<- prometheus.Metric) {
+	if !c.leader() {
+		return
+	}
+
+	events, err := c.events.ListAllEvent()
+	if err != nil {
+		c.logger.Error("could not list events", zap.Error(err))
+		return
+	}
+
+	pending, err := c.scores.PendingVerifications()
+	if err != nil {
+		c.logger.Error("could not count pending verifications", zap.Error(err))
+		return
+	}
+
+	for _, v := range events {
+		if !v.Active {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.pending,
+			prometheus.GaugeValue,
+			float64(pending[v.ID]),
+			v.GID,
+			v.ID,
+		)
+	}
+}
@@ -0,0 +1,238 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// LeaderboardRow is a single line of a rendered leaderboard
+type LeaderboardRow struct {
+	Rank  int
+	Name  string
+	Score int
+	// Delta is how many places the row moved up since the last render, negative for down
+	Delta int
+	// New marks rows that were not on the previous render at all
+	New bool
+}
+
+// Leaderboard is everything that ends up on the image
+type Leaderboard struct {
+	Title    string
+	Subtitle string
+	Rows     []LeaderboardRow
+}
+
+const (
+	imageWidth   = 800
+	headerHeight = 96
+	rowHeight    = 44
+	footerHeight = 16
+	padding      = 24
+
+	rankColumn  = padding
+	deltaColumn = padding + 72
+	nameColumn  = padding + 150
+	scoreRight  = imageWidth - padding
+)
+
+var (
+	backgroundColor = color.RGBA{0x2f, 0x31, 0x36, 0xff}
+	stripeColor     = color.RGBA{0x36, 0x39, 0x3f, 0xff}
+	dividerColor    = color.RGBA{0x20, 0x22, 0x25, 0xff}
+	textColor       = color.RGBA{0xdc, 0xdd, 0xde, 0xff}
+	mutedColor      = color.RGBA{0x8e, 0x92, 0x97, 0xff}
+	upColor         = color.RGBA{0x3b, 0xa5, 0x5d, 0xff}
+	downColor       = color.RGBA{0xed, 0x42, 0x45, 0xff}
+	newColor        = color.RGBA{0x58, 0x65, 0xf2, 0xff}
+	podiumColors    = []color.RGBA{
+		{0xf1, 0xc4, 0x0f, 0xff},
+		{0xbd, 0xc3, 0xc7, 0xff},
+		{0xcd, 0x7f, 0x32, 0xff},
+	}
+)
+
+type faces struct {
+	title, subtitle, row, rowBold, small font.Face
+}
+
+var (
+	loadFaces sync.Once
+	loaded    *faces
+	loadErr   error
+)
+
+func getFaces() (*faces, error) {
+	loadFaces.Do(func() {
+		regular, err := opentype.Parse(goregular.TTF)
+		if err != nil {
+			loadErr = err
+			return
+		}
+		bold, err := opentype.Parse(gobold.TTF)
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		face := func(f *opentype.Font, size float64) font.Face {
+			if loadErr != nil {
+				return nil
+			}
+			var ff font.Face
+			ff, loadErr = opentype.NewFace(f, &opentype.FaceOptions{
+				Size:    size,
+				DPI:     72,
+				Hinting: font.HintingFull,
+			})
+			return ff
+		}
+
+		loaded = &faces{
+			title:    face(bold, 30),
+			subtitle: face(regular, 18),
+			row:      face(regular, 22),
+			rowBold:  face(bold, 22),
+			small:    face(bold, 15),
+		}
+	})
+
+	return loaded, loadErr
+}
+
+// RenderLeaderboard draws the leaderboard as a PNG into w
+func RenderLeaderboard(w io.Writer, lb *Leaderboard) error {
+	img, err := DrawLeaderboard(lb)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// DrawLeaderboard draws the leaderboard onto a new image
+func DrawLeaderboard(lb *Leaderboard) (*image.RGBA, error) {
+	f, err := getFaces()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := len(lb.Rows)
+	if rows == 0 {
+		rows = 1
+	}
+	height := headerHeight + rows*rowHeight + footerHeight
+
+	img := image.NewRGBA(image.Rect(0, 0, imageWidth, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+
+	drawText(img, f.title, textColor, padding, 44, fit(f.title, lb.Title, imageWidth-2*padding))
+	if lb.Subtitle != "" {
+		drawText(img, f.subtitle, mutedColor, padding, 74, fit(f.subtitle, lb.Subtitle, imageWidth-2*padding))
+	}
+	fillRect(img, image.Rect(padding, headerHeight-4, imageWidth-padding, headerHeight-2), dividerColor)
+
+	if len(lb.Rows) == 0 {
+		drawText(img, f.row, mutedColor, padding, headerHeight+29, "No submissions yet")
+		return img, nil
+	}
+
+	for i, row := range lb.Rows {
+		top := headerHeight + i*rowHeight
+		baseline := top + 29
+
+		if i%2 == 1 {
+			fillRect(img, image.Rect(0, top, imageWidth, top+rowHeight), stripeColor)
+		}
+
+		rankColor := textColor
+		if row.Rank >= 1 && row.Rank <= len(podiumColors) {
+			rankColor = podiumColors[row.Rank-1]
+		}
+		drawText(img, f.rowBold, rankColor, rankColumn, baseline, fmt.Sprintf("#%d", row.Rank))
+
+		drawDelta(img, f.small, row, deltaColumn, top+rowHeight/2)
+
+		score := fmt.Sprintf("%d", row.Score)
+		scoreWidth := font.MeasureString(f.rowBold, score).Ceil()
+		drawText(img, f.rowBold, textColor, scoreRight-scoreWidth, baseline, score)
+
+		nameWidth := scoreRight - scoreWidth - padding - nameColumn
+		drawText(img, f.row, textColor, nameColumn, baseline, fit(f.row, row.Name, nameWidth))
+	}
+
+	return img, nil
+}
+
+func drawDelta(img *image.RGBA, face font.Face, row LeaderboardRow, x, midY int) {
+	switch {
+	case row.New:
+		drawText(img, face, newColor, x, midY+5, "NEW")
+	case row.Delta > 0:
+		fillTriangle(img, x, midY, true, upColor)
+		drawText(img, face, upColor, x+16, midY+5, fmt.Sprintf("%d", row.Delta))
+	case row.Delta < 0:
+		fillTriangle(img, x, midY, false, downColor)
+		drawText(img, face, downColor, x+16, midY+5, fmt.Sprintf("%d", -row.Delta))
+	default:
+		fillRect(img, image.Rect(x+1, midY-1, x+11, midY+1), mutedColor)
+	}
+}
+
+func drawText(img *image.RGBA, face font.Face, c color.Color, x, y int, s string) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+// fit shortens s with an ellipsis until it fits in width pixels
+func fit(face font.Face, s string, width int) string {
+	if font.MeasureString(face, s).Ceil() <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "…"
+		if font.MeasureString(face, candidate).Ceil() <= width {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// fillTriangle draws a 12px wide arrow head centered vertically on midY
+func fillTriangle(img *image.RGBA, x, midY int, up bool, c color.Color) {
+	const size = 12
+	for row := 0; row < size/2+1; row++ {
+		// row 0 is the tip of the arrow
+		half := row
+		y := midY - size/4 + row
+		if !up {
+			y = midY + size/4 - row
+		}
+		for col := size/2 - half; col <= size/2+half; col++ {
+			img.Set(x+col, y, c)
+		}
+	}
+}
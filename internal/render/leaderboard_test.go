@@ -0,0 +1,82 @@
+package render_test
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/2785/warframe-assistant/internal/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden images")
+
+func TestRenderLeaderboardGolden(t *testing.T) {
+	cases := map[string]*render.Leaderboard{
+		"leaderboard": {
+			Title:    "Weekly Steel Path Disruption",
+			Subtitle: "Accumulative - 5 participants",
+			Rows: []render.LeaderboardRow{
+				{Rank: 1, Name: "test-ign-1", Score: 9003, Delta: 1},
+				{Rank: 2, Name: "test-ign-2", Score: 5, Delta: -1},
+				{Rank: 3, Name: "test-ign-3", Score: 4},
+				{Rank: 4, Name: "a-really-long-in-game-name-that-will-not-fit-on-the-row-at-all", Score: 3, New: true},
+				{Rank: 4, Name: "test-ign-5", Score: 3, Delta: 2},
+			},
+		},
+		"empty": {
+			Title: "Nothing to see here",
+		},
+	}
+
+	for name, lb := range cases {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			buf := &bytes.Buffer{}
+			require.NoError(render.RenderLeaderboard(buf, lb))
+
+			golden := filepath.Join("testdata", name+".golden.png")
+			if *update {
+				require.NoError(os.WriteFile(golden, buf.Bytes(), 0644))
+			}
+
+			want, err := os.ReadFile(golden)
+			require.NoError(err, "golden image missing, run the test with -update to create it")
+
+			assertSameImage(t, want, buf.Bytes())
+		})
+	}
+}
+
+func assertSameImage(t *testing.T, want, got []byte) {
+	wantImg, err := png.Decode(bytes.NewReader(want))
+	require.NoError(t, err)
+	gotImg, err := png.Decode(bytes.NewReader(got))
+	require.NoError(t, err)
+
+	require.Equal(t, wantImg.Bounds(), gotImg.Bounds())
+
+	diff := 0
+	b := wantImg.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if !samePixel(wantImg, gotImg, x, y) {
+				diff++
+			}
+		}
+	}
+
+	assert.Zero(t, diff, "rendered image differs from golden image in %d pixels", diff)
+}
+
+func samePixel(a, b image.Image, x, y int) bool {
+	r1, g1, b1, a1 := a.At(x, y).RGBA()
+	r2, g2, b2, a2 := b.At(x, y).RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
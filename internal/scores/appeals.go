@@ -0,0 +1,78 @@
+package scores
+
+import (
+	"errors"
+	"time"
+)
+
+// HistoryAction is something that happened to a submission after it was made
+type HistoryAction string
+
+const (
+	HistoryVerified  HistoryAction = "verified"
+	HistoryRejected  HistoryAction = "rejected"
+	HistoryAmended   HistoryAction = "amended"
+	HistoryRemoved   HistoryAction = "removed"
+	HistoryWithdrawn HistoryAction = "withdrawn"
+	HistoryAppealed  HistoryAction = "appealed"
+	// HistoryAppealDenied keeps the moderator decision the appeal was filed against
+	HistoryAppealDenied HistoryAction = "appeal-denied"
+	// HistoryAppealGranted overturns the moderator decision the appeal was filed against
+	HistoryAppealGranted HistoryAction = "appeal-granted"
+)
+
+// HistoryEntry is a single action taken on a submission.
+type HistoryEntry struct {
+	SID    string        `db:"submission_id"`
+	Action HistoryAction `db:"action"`
+	// Actor is the ID of the user who took the action
+	Actor string `db:"actor"`
+	// PreviousScore and Score are the raw score of the submission before and after the action,
+	// they are the same for actions that don't change it
+	PreviousScore int `db:"previous_score"`
+	Score         int `db:"score"`
+	// Detail is the reason given for appeals and the note left when resolving them
+	Detail    string    `db:"detail"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Appealable reports whether a moderator rejected, amended or removed the submission, the
+// decisions appeals can be filed against
+func Appealable(history []HistoryEntry) bool {
+	for _, v := range history {
+		switch v.Action {
+		case HistoryRejected, HistoryAmended, HistoryRemoved:
+			return true
+		}
+	}
+	return false
+}
+
+// ClaimedScore is the raw score the submission was made with before any moderator changed it,
+// current is its raw score now
+func ClaimedScore(history []HistoryEntry, current int) int {
+	if len(history) == 0 {
+		return current
+	}
+	return history[0].PreviousScore
+}
+
+// AppealStatus is where an appeal is at, only pending appeals can be resolved
+type AppealStatus string
+
+const (
+	AppealPending AppealStatus = "pending"
+	AppealDenied  AppealStatus = "denied"
+	AppealGranted AppealStatus = "granted"
+)
+
+// Appeal is a submission its submitter asked to have looked at again
+type Appeal struct {
+	ScoreRecord
+	Reason     string    `db:"reason"`
+	AppealedAt time.Time `db:"appealed_at"`
+}
+
+// ErrAppealExists is returned when appealing a submission that was appealed before, every
+// submission can be appealed once
+var ErrAppealExists = errors.New("submission was appealed already")
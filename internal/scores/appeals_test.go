@@ -0,0 +1,34 @@
+package scores_test
+
+import (
+	"testing"
+
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppealable(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(scores.Appealable(nil))
+	assert.False(scores.Appealable([]scores.HistoryEntry{{Action: scores.HistoryVerified}}))
+	assert.True(scores.Appealable([]scores.HistoryEntry{{Action: scores.HistoryRejected}}))
+	assert.True(scores.Appealable([]scores.HistoryEntry{{Action: scores.HistoryRemoved}}))
+	assert.True(scores.Appealable([]scores.HistoryEntry{
+		{Action: scores.HistoryVerified},
+		{Action: scores.HistoryAmended},
+	}))
+}
+
+func TestClaimedScore(t *testing.T) {
+	assert := assert.New(t)
+
+	// without any history the submission still has the score it was made with
+	assert.Equal(30, scores.ClaimedScore(nil, 30))
+
+	// amended twice, it was first claimed at 300
+	assert.Equal(300, scores.ClaimedScore([]scores.HistoryEntry{
+		{Action: scores.HistoryAmended, PreviousScore: 300, Score: 200},
+		{Action: scores.HistoryAmended, PreviousScore: 200, Score: 30},
+	}, 30))
+}
@@ -0,0 +1,103 @@
+package scores
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// CategoryMode is how the submissions of a category are counted
+type CategoryMode string
+
+const (
+	// CategorySum adds up every submission of a user
+	CategorySum CategoryMode = "sum"
+	// CategoryTop only counts the highest submission of a user, e.g. the highest Disruption round
+	CategoryTop CategoryMode = "top"
+	// CategoryLowest only counts the lowest submission of a user, e.g. the fastest Eidolon tri-cap
+	CategoryLowest CategoryMode = "lowest"
+)
+
+// CategoryModes lists every supported category mode
+var CategoryModes = []CategoryMode{CategorySum, CategoryTop, CategoryLowest}
+
+// Category is a track of an event with its own leaderboard, submitters pick the category their
+// submission is for
+type Category struct {
+	Name string       `db:"name"`
+	Mode CategoryMode `db:"mode"`
+}
+
+// Validate checks the category can be picked by name and has a supported mode
+func (c Category) Validate() error {
+	if !nameRe.MatchString(c.Name) {
+		return fmt.Errorf(
+			"invalid category name '%s', use up to 32 lowercase letters, digits and dashes",
+			c.Name,
+		)
+	}
+
+	for _, v := range CategoryModes {
+		if c.Mode == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown category mode '%s'", c.Mode)
+}
+
+// Description explains how the submissions of the category are counted
+func (c Category) Description() string {
+	switch c.Mode {
+	case CategorySum:
+		return "Accumulative"
+	case CategoryLowest:
+		return "Only lowest score counts"
+	default:
+		return "Only best score counts"
+	}
+}
+
+// ErrCategoryInUse is returned when deleting a category that submissions were made for
+var ErrCategoryInUse = errors.New("category has submissions")
+
+// CombineStandings ranks users across the standings of every category of an event by placement
+// points: in a category with n ranked users, rank r earns n-r+1 points, so placing well in a
+// crowded category is worth more. Users with the same points share their rank.
+func CombineStandings(categories [][]SummaryRecord) []SummaryRecord {
+	points := map[string]*SummaryRecord{}
+	for _, standings := range categories {
+		for _, v := range standings {
+			record, ok := points[v.UID]
+			if !ok {
+				record = &SummaryRecord{UID: v.UID, IGN: v.IGN}
+				points[v.UID] = record
+			}
+			record.Score += len(standings) - v.Rank + 1
+		}
+	}
+
+	combined := make([]SummaryRecord, 0, len(points))
+	for _, v := range points {
+		combined = append(combined, *v)
+	}
+
+	sort.Slice(combined, func(i, j int) bool {
+		if combined[i].Score != combined[j].Score {
+			return combined[i].Score > combined[j].Score
+		}
+		if combined[i].IGN != combined[j].IGN {
+			return combined[i].IGN < combined[j].IGN
+		}
+		return combined[i].UID < combined[j].UID
+	})
+
+	for i := range combined {
+		if i > 0 && combined[i].Score == combined[i-1].Score {
+			combined[i].Rank = combined[i-1].Rank
+		} else {
+			combined[i].Rank = i + 1
+		}
+	}
+
+	return combined
+}
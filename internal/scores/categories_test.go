@@ -0,0 +1,41 @@
+package scores_test
+
+import (
+	"testing"
+
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineStandings(t *testing.T) {
+	assert := assert.New(t)
+
+	combined := scores.CombineStandings([][]scores.SummaryRecord{
+		{
+			{UID: "user-1", IGN: "ign-1", Score: 50, Rank: 1},
+			{UID: "user-2", IGN: "ign-2", Score: 40, Rank: 2},
+			{UID: "user-3", IGN: "ign-3", Score: 40, Rank: 2},
+		},
+		{
+			{UID: "user-3", IGN: "ign-3", Score: 300, Rank: 1},
+		},
+	})
+
+	// 3 points for winning the first category, 2 for sharing second place, and 1 for winning
+	// the second category alone
+	assert.Equal([]scores.SummaryRecord{
+		{UID: "user-1", IGN: "ign-1", Score: 3, Rank: 1},
+		{UID: "user-3", IGN: "ign-3", Score: 3, Rank: 1},
+		{UID: "user-2", IGN: "ign-2", Score: 2, Rank: 3},
+	}, combined)
+
+	assert.Empty(scores.CombineStandings(nil))
+}
+
+func TestCategoryValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(scores.Category{Name: "tricap", Mode: scores.CategoryLowest}.Validate())
+	assert.Error(scores.Category{Name: "Eidolon Tricap", Mode: scores.CategoryLowest}.Validate())
+	assert.Error(scores.Category{Name: "tricap", Mode: "median"}.Validate())
+}
@@ -0,0 +1,196 @@
+package scores
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ModifierKind is how a modifier changes the score it's applied to
+type ModifierKind string
+
+const (
+	// ModifierMultiplier multiplies the score, e.g. ×1.5 for a Steel Path run
+	ModifierMultiplier ModifierKind = "multiplier"
+	// ModifierBonus adds to the score, e.g. +100 for a no-death clear
+	ModifierBonus ModifierKind = "bonus"
+)
+
+// ModifierKinds lists every supported modifier kind
+var ModifierKinds = []ModifierKind{ModifierMultiplier, ModifierBonus}
+
+// Modifier is a scoring rule an event defines, submitters pick the ones that apply to their run
+type Modifier struct {
+	Name  string       `db:"name" json:"name"`
+	Kind  ModifierKind `db:"kind" json:"kind"`
+	Value float64      `db:"value" json:"value"`
+}
+
+// nameRe is what submitters pick modifiers and categories by
+var nameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Validate checks the modifier can be picked by name and has a sensible value
+func (m Modifier) Validate() error {
+	if !nameRe.MatchString(m.Name) {
+		return fmt.Errorf(
+			"invalid modifier name '%s', use up to 32 lowercase letters, digits and dashes",
+			m.Name,
+		)
+	}
+
+	switch m.Kind {
+	case ModifierMultiplier:
+		if m.Value <= 0 {
+			return errors.New("multipliers must be greater than 0")
+		}
+	case ModifierBonus:
+	default:
+		return fmt.Errorf("unknown modifier kind '%s'", m.Kind)
+	}
+
+	if math.IsNaN(m.Value) || math.IsInf(m.Value, 0) {
+		return errors.New("modifier value must be a number")
+	}
+
+	return nil
+}
+
+// String shows the effect of the modifier, like `steel-path ×1.5` or `no-death +100`
+func (m Modifier) String() string {
+	value := strconv.FormatFloat(m.Value, 'f', -1, 64)
+	if m.Kind == ModifierMultiplier {
+		return fmt.Sprintf("%s ×%s", m.Name, value)
+	}
+	if m.Value < 0 {
+		return fmt.Sprintf("%s %s", m.Name, value)
+	}
+	return fmt.Sprintf("%s +%s", m.Name, value)
+}
+
+// Apply computes the effective score of a raw score with the given modifiers. Multipliers are
+// applied first and compound, bonuses are added afterwards so they aren't multiplied. The result
+// is rounded to the nearest integer.
+func Apply(raw int, modifiers []Modifier) int {
+	score := float64(raw)
+	for _, v := range modifiers {
+		if v.Kind == ModifierMultiplier {
+			score *= v.Value
+		}
+	}
+	for _, v := range modifiers {
+		if v.Kind == ModifierBonus {
+			score += v.Value
+		}
+	}
+	return int(math.Round(score))
+}
+
+// Breakdown explains how the effective score comes about, e.g. `100 × 1.5 (steel-path) + 100
+// (no-death) = 250`, or just the raw score without modifiers
+func Breakdown(raw int, modifiers []Modifier) string {
+	if len(modifiers) == 0 {
+		return strconv.Itoa(raw)
+	}
+
+	b := strings.Builder{}
+	b.WriteString(strconv.Itoa(raw))
+	for _, kind := range ModifierKinds {
+		for _, v := range modifiers {
+			if v.Kind != kind {
+				continue
+			}
+			op, value := "×", v.Value
+			if kind == ModifierBonus {
+				op = "+"
+				if value < 0 {
+					op, value = "-", -value
+				}
+			}
+			fmt.Fprintf(&b, " %s %s (%s)", op, strconv.FormatFloat(value, 'f', -1, 64), v.Name)
+		}
+	}
+	fmt.Fprintf(&b, " = %d", Apply(raw, modifiers))
+	return b.String()
+}
+
+// PickModifiers looks up the modifiers named by a submitter among those the event defines, names
+// are separated by commas or spaces and picking one twice only applies it once
+func PickModifiers(input string, defined []Modifier) ([]Modifier, error) {
+	byName := make(map[string]Modifier, len(defined))
+	for _, v := range defined {
+		byName[v.Name] = v
+	}
+
+	picked := []Modifier{}
+	seen := map[string]bool{}
+	for _, name := range strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		m, ok := byName[name]
+		if !ok {
+			return nil, &ErrUnknownModifier{Name: name}
+		}
+		if !seen[name] {
+			seen[name] = true
+			picked = append(picked, m)
+		}
+	}
+
+	return picked, nil
+}
+
+var _ error = &ErrUnknownModifier{}
+
+// ErrUnknownModifier is returned when a submitter picks a modifier the event doesn't define
+type ErrUnknownModifier struct {
+	Name string
+}
+
+func (e *ErrUnknownModifier) Error() string {
+	return fmt.Sprintf("unknown modifier '%s'", e.Name)
+}
+
+// AppliedModifiers are the modifiers a submission was made with, as they were defined at the
+// time so later rule changes don't alter past submissions
+type AppliedModifiers []Modifier
+
+var (
+	_ driver.Valuer = AppliedModifiers{}
+	_ sql.Scanner   = &AppliedModifiers{}
+)
+
+func (a AppliedModifiers) Value() (driver.Value, error) {
+	if a == nil {
+		a = AppliedModifiers{}
+	}
+	return json.Marshal([]Modifier(a))
+}
+
+func (a *AppliedModifiers) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = AppliedModifiers{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]Modifier)(a))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]Modifier)(a))
+	default:
+		return fmt.Errorf("cannot scan %T into modifiers", src)
+	}
+}
+
+// Names lists the names of the modifiers in order
+func (a AppliedModifiers) Names() []string {
+	names := make([]string, len(a))
+	for i, v := range a {
+		names[i] = v.Name
+	}
+	return names
+}
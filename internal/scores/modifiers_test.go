@@ -0,0 +1,66 @@
+package scores_test
+
+import (
+	"testing"
+
+	"github.com/2785/warframe-assistant/internal/scores"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	steelPath := scores.Modifier{Name: "steel-path", Kind: scores.ModifierMultiplier, Value: 1.5}
+	solo := scores.Modifier{Name: "solo", Kind: scores.ModifierMultiplier, Value: 2}
+	noDeath := scores.Modifier{Name: "no-death", Kind: scores.ModifierBonus, Value: 100}
+	penalty := scores.Modifier{Name: "revived", Kind: scores.ModifierBonus, Value: -25}
+
+	cases := []struct {
+		raw       int
+		modifiers []scores.Modifier
+		want      int
+		breakdown string
+	}{
+		{100, nil, 100, "100"},
+		{100, []scores.Modifier{steelPath}, 150, "100 × 1.5 (steel-path) = 150"},
+		// bonuses are never multiplied, whatever order they were picked in
+		{100, []scores.Modifier{noDeath, steelPath}, 250, "100 × 1.5 (steel-path) + 100 (no-death) = 250"},
+		{100, []scores.Modifier{steelPath, solo}, 300, "100 × 1.5 (steel-path) × 2 (solo) = 300"},
+		{100, []scores.Modifier{penalty}, 75, "100 - 25 (revived) = 75"},
+		{7, []scores.Modifier{steelPath}, 11, "7 × 1.5 (steel-path) = 11"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, scores.Apply(c.raw, c.modifiers), c.breakdown)
+		assert.Equal(t, c.breakdown, scores.Breakdown(c.raw, c.modifiers))
+	}
+}
+
+func TestModifierValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(scores.Modifier{Name: "steel-path", Kind: scores.ModifierMultiplier, Value: 1.5}.Validate())
+	assert.NoError(scores.Modifier{Name: "revived", Kind: scores.ModifierBonus, Value: -25}.Validate())
+
+	assert.Error(scores.Modifier{Name: "Steel Path", Kind: scores.ModifierMultiplier, Value: 1.5}.Validate())
+	assert.Error(scores.Modifier{Name: "steel-path", Kind: scores.ModifierMultiplier, Value: 0}.Validate())
+	assert.Error(scores.Modifier{Name: "steel-path", Kind: "exponent", Value: 2}.Validate())
+}
+
+func TestPickModifiers(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	defined := []scores.Modifier{
+		{Name: "no-death", Kind: scores.ModifierBonus, Value: 100},
+		{Name: "steel-path", Kind: scores.ModifierMultiplier, Value: 1.5},
+	}
+
+	picked, err := scores.PickModifiers("Steel-Path, no-death steel-path", defined)
+	require.NoError(err)
+	assert.Equal([]scores.Modifier{defined[1], defined[0]}, picked)
+
+	_, err = scores.PickModifiers("steel-path, solo", defined)
+	unknown := &scores.ErrUnknownModifier{}
+	require.ErrorAs(err, &unknown)
+	assert.Equal("solo", unknown.Name)
+}
@@ -2,10 +2,11 @@ package scores
 
 import (
 	"database/sql"
+	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
@@ -17,18 +18,34 @@ type PostgresService struct {
 	ScoresTableName        string
 	ParticipationTableName string
 	UserIGNTableName       string
+	ResultsTableName       string
+	ModifiersTableName     string
+	CategoriesTableName    string
+	HistoryTableName       string
+	AppealsTableName       string
 }
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+const pgErrUniqueConstraintViolation = "23505"
+
+// submissionColumns are selected for every ScoreRecord, with e the scores table, p the
+// participation table and u the user table. Submissions made before modifiers existed have no raw
+// score, it's the same as their score.
+var submissionColumns = []string{
+	"e.id as eid", "p.id as pid", "u.id as uid", "u.ign", "e.score", "coalesce(e.raw_score, e.score) as raw_score",
+	"e.proof", "e.verified", "e.modifiers", "e.category", "e.removed", "e.withdrawn",
+}
+
 func (ps *PostgresService) ClaimScore(
-	pid string,
+	pid, category string,
 	score int,
+	modifiers []Modifier,
 	proof string,
 ) (submissionID string, e error) {
 	q := psql.Insert(ps.ScoresTableName).
-		Columns("participation_id", "score", "proof").
-		Values(pid, score, proof).
+		Columns("participation_id", "category", "score", "raw_score", "modifiers", "proof").
+		Values(pid, category, Apply(score, modifiers), score, AppliedModifiers(modifiers), proof).
 		Suffix("RETURNING id").
 		RunWith(ps.DB)
 
@@ -42,11 +59,11 @@ func (ps *PostgresService) ClaimScore(
 }
 
 func (ps *PostgresService) GetOneUnverified() (*ScoreRecord, error) {
-	q := psql.Select("e.id as eid", "p.id as pid", "u.id as uid", "u.ign", "e.score", "e.proof", "e.verified").
+	q := psql.Select(submissionColumns...).
 		From(ps.ScoresTableName + " as e").
 		LeftJoin(ps.ParticipationTableName + " as p on p.id = e.participation_id").
 		LeftJoin(ps.UserIGNTableName + " as u on u.id = p.user_id").
-		Where(sq.Eq{"verified": false}).
+		Where(sq.Eq{"e.verified": false, "e.removed": false}).
 		Limit(1)
 
 	record := &ScoreRecord{}
@@ -67,11 +84,11 @@ func (ps *PostgresService) GetOneUnverified() (*ScoreRecord, error) {
 }
 
 func (ps *PostgresService) GetOneUnverifiedForEvent(eid string) (*ScoreRecord, error) {
-	q := psql.Select("e.id as eid", "p.id as pid", "u.id as uid", "u.ign", "e.score", "e.proof", "e.verified").
+	q := psql.Select(submissionColumns...).
 		From(ps.ScoresTableName + " as e").
 		LeftJoin(ps.ParticipationTableName + " as p on p.id = e.participation_id").
 		LeftJoin(ps.UserIGNTableName + " as u on u.id = p.user_id").
-		Where(sq.Eq{"p.event_id": eid, "verified": false})
+		Where(sq.Eq{"p.event_id": eid, "e.verified": false, "e.removed": false})
 
 	record := &ScoreRecord{}
 	query, args, err := q.ToSql()
@@ -90,8 +107,79 @@ func (ps *PostgresService) GetOneUnverifiedForEvent(eid string) (*ScoreRecord, e
 	return record, nil
 }
 
-func (ps *PostgresService) Verify(sid string) error {
-	q := psql.Update(ps.ScoresTableName).Set("verified", true).Where(sq.Eq{"id": sid})
+// ListUnverifiedForEvent returns up to limit submissions of the event waiting on verification,
+// grouped by user
+func (ps *PostgresService) ListUnverifiedForEvent(eid string, limit uint64) ([]ScoreRecord, error) {
+	q := psql.Select(submissionColumns...).
+		From(ps.ScoresTableName+" as e").
+		LeftJoin(ps.ParticipationTableName+" as p on p.id = e.participation_id").
+		LeftJoin(ps.UserIGNTableName+" as u on u.id = p.user_id").
+		Where(sq.Eq{"p.event_id": eid, "e.verified": false, "e.removed": false}).
+		OrderBy("u.ign", "e.id").
+		Limit(limit)
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	records := []ScoreRecord{}
+	err = ps.DB.Select(&records, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// GetEventIDForSubmission returns the ID of the event the submission was made for
+func (ps *PostgresService) GetEventIDForSubmission(sid string) (string, error) {
+	q := psql.Select("p.event_id").
+		From(ps.ScoresTableName + " as e").
+		Join(ps.ParticipationTableName + " as p on p.id = e.participation_id").
+		Where(sq.Eq{"e.id": sid})
+
+	eid := ""
+	err := q.RunWith(ps.DB).Scan(&eid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", &ErrNoRecord{}
+		}
+		return "", err
+	}
+
+	return eid, nil
+}
+
+// GetSubmission returns a single submission along with the event it was made for
+func (ps *PostgresService) GetSubmission(sid string) (*ScoreRecord, error) {
+	q := psql.Select(append(submissionColumns, "p.event_id")...).
+		From(ps.ScoresTableName + " as e").
+		Join(ps.ParticipationTableName + " as p on p.id = e.participation_id").
+		LeftJoin(ps.UserIGNTableName + " as u on u.id = p.user_id").
+		Where(sq.Eq{"e.id": sid})
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &ScoreRecord{}
+	err = ps.DB.Get(record, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &ErrNoRecord{}
+		}
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (ps *PostgresService) Verify(sid, verifier string) error {
+	q := psql.Update(ps.ScoresTableName).
+		SetMap(map[string]interface{}{"verified": true, "verified_by": verifier}).
+		Where(sq.Eq{"id": sid, "removed": false})
 	res, err := q.RunWith(ps.DB).Exec()
 	if err != nil {
 		return err
@@ -106,15 +194,28 @@ func (ps *PostgresService) Verify(sid string) error {
 	return nil
 }
 
-func (ps *PostgresService) MakeReportScoreSum(eid string) ([]SummaryRecord, error) {
-	q := psql.Select("e.score", "u.id as uid", "u.ign").
-		FromSelect(psql.Select("sum(e.score) as score", "e.uid").
-			FromSelect(psql.Select("e.score", "p.user_id as uid").
-				From(ps.ScoresTableName+" as e").
-				LeftJoin(ps.ParticipationTableName+" as p on p.id = e.participation_id").
-				Where(sq.Eq{"p.event_id": eid, "p.participating": true, "e.verified": true}),
-				"e").GroupBy("e.uid"),
-			"e").LeftJoin(ps.UserIGNTableName + " as u on u.id = e.uid").OrderBy("e.score desc")
+// tieBreakOrder is how users with the same score are ordered for each tie break, the report
+// queries expose the number of submissions and when the score was reached for each user
+var tieBreakOrder = map[TieBreak]string{
+	TieBreakShared:   "",
+	TieBreakEarliest: ", e.reached_at asc",
+	TieBreakFewest:   ", e.submissions asc",
+}
+
+// rankedReport ranks the per user rows of the report by score, in the given direction, then by
+// the tie break, users that are still tied share their rank
+func (ps *PostgresService) rankedReport(
+	report sq.SelectBuilder,
+	direction string,
+	tieBreak TieBreak,
+) ([]SummaryRecord, error) {
+	order := "e.score " + direction + tieBreakOrder[tieBreak.OrDefault()]
+
+	q := psql.Select("e.score", "u.id as uid", "u.ign", fmt.Sprintf("rank() over (order by %s) as rank", order)).
+		FromSelect(report, "e").
+		LeftJoin(ps.UserIGNTableName+" as u on u.id = e.uid").
+		OrderBy("rank", "u.ign", "u.id")
+
 	query, args, err := q.ToSql()
 	if err != nil {
 		return nil, err
@@ -129,29 +230,89 @@ func (ps *PostgresService) MakeReportScoreSum(eid string) ([]SummaryRecord, erro
 	return leaderboard, nil
 }
 
-func (ps *PostgresService) MakeReportScoreTop(eid string) ([]SummaryRecord, error) {
-	q := psql.Select("s.score", "u.id as uid", "u.ign").
-		FromSelect(psql.Select("s.uid", "max(s.score) as score").
-			FromSelect(psql.Select("s.participation_id as pid", "s.score as score", "p.user_id as uid").
-				From(ps.ScoresTableName+" as s").
-				LeftJoin(ps.ParticipationTableName+" as p on p.id = s.participation_id").
-				Where(sq.Eq{"p.participating": true, "p.event_id": eid, "s.verified": true}),
-				"s").
-			GroupBy("s.uid"), "s").
-		LeftJoin(ps.UserIGNTableName + " as u on u.id = s.uid")
+// verifiedSubmissions selects the verified submissions of participants matching the filter, with
+// s the scores table and p the participation table
+func (ps *PostgresService) verifiedSubmissions(filter sq.Eq, columns ...string) sq.SelectBuilder {
+	where := sq.Eq{"p.participating": true, "s.verified": true, "s.removed": false}
+	for k, v := range filter {
+		where[k] = v
+	}
 
-	query, args, err := q.ToSql()
-	if err != nil {
-		return nil, err
+	return psql.Select(columns...).
+		From(ps.ScoresTableName + " as s").
+		LeftJoin(ps.ParticipationTableName + " as p on p.id = s.participation_id").
+		Where(where)
+}
+
+// sumReport adds up the submissions of each user, a total is reached with the last submission
+// that counts towards it
+func (ps *PostgresService) sumReport(filter sq.Eq) sq.SelectBuilder {
+	return psql.Select("sum(s.score) as score", "s.uid", "count(*) as submissions", "max(s.created_at) as reached_at").
+		FromSelect(ps.verifiedSubmissions(filter, "s.score", "s.created_at", "p.user_id as uid"), "s").
+		GroupBy("s.uid")
+}
+
+// bestReport keeps the best submission of each user, best being what the aggregate (max or min)
+// picks. A best score is reached with the first submission that scored it.
+func (ps *PostgresService) bestReport(filter sq.Eq, aggregate string) sq.SelectBuilder {
+	return psql.Select(
+		aggregate+"(s.score) as score",
+		"s.uid",
+		"count(*) as submissions",
+		"min(s.created_at) filter (where s.score = s.best) as reached_at",
+	).
+		FromSelect(ps.verifiedSubmissions(
+			filter,
+			"s.score",
+			"s.created_at",
+			"p.user_id as uid",
+			aggregate+"(s.score) over (partition by p.user_id) as best",
+		), "s").
+		GroupBy("s.uid")
+}
+
+func (ps *PostgresService) MakeReportScoreSum(eid string, tieBreak TieBreak) ([]SummaryRecord, error) {
+	return ps.rankedReport(ps.sumReport(sq.Eq{"p.event_id": eid}), "desc", tieBreak)
+}
+
+func (ps *PostgresService) MakeReportScoreTop(eid string, tieBreak TieBreak) ([]SummaryRecord, error) {
+	return ps.rankedReport(ps.bestReport(sq.Eq{"p.event_id": eid}, "max"), "desc", tieBreak)
+}
+
+func (ps *PostgresService) MakeReportCategory(
+	eid string,
+	category Category,
+	tieBreak TieBreak,
+) ([]SummaryRecord, error) {
+	filter := sq.Eq{"p.event_id": eid, "s.category": category.Name}
+
+	switch category.Mode {
+	case CategorySum:
+		return ps.rankedReport(ps.sumReport(filter), "desc", tieBreak)
+	case CategoryTop:
+		return ps.rankedReport(ps.bestReport(filter, "max"), "desc", tieBreak)
+	case CategoryLowest:
+		return ps.rankedReport(ps.bestReport(filter, "min"), "asc", tieBreak)
+	default:
+		return nil, fmt.Errorf("unknown category mode '%s'", category.Mode)
 	}
+}
 
-	leaderboard := []SummaryRecord{}
-	err = ps.DB.Select(&leaderboard, query, args...)
+func (ps *PostgresService) MakeReportCombined(eid string, tieBreak TieBreak) ([]SummaryRecord, error) {
+	categories, err := ps.ListCategories(eid)
 	if err != nil {
 		return nil, err
 	}
 
-	return leaderboard, nil
+	standings := make([][]SummaryRecord, len(categories))
+	for i, v := range categories {
+		standings[i], err = ps.MakeReportCategory(eid, v, tieBreak)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return CombineStandings(standings), nil
 }
 
 func (ps *PostgresService) VerificationStatus(eid string) (total, verified int, e error) {
@@ -161,7 +322,7 @@ func (ps *PostgresService) VerificationStatus(eid string) (total, verified int,
 	).FromSelect(
 		psql.Select("s.verified").From(ps.ScoresTableName+" as s").
 			LeftJoin(ps.ParticipationTableName+" as p on p.id = s.participation_id").
-			Where(sq.Eq{"p.participating": true, "p.event_id": eid}),
+			Where(sq.Eq{"p.participating": true, "p.event_id": eid, "s.removed": false}),
 		"s",
 	)
 
@@ -175,9 +336,90 @@ func (ps *PostgresService) VerificationStatus(eid string) (total, verified int,
 	return done + pending, done, nil
 }
 
+func (ps *PostgresService) PendingVerifications() (map[string]int, error) {
+	query, args, err := psql.Select("p.event_id", "count(*)").
+		From(ps.ScoresTableName + " as s").
+		Join(ps.ParticipationTableName + " as p on p.id = s.participation_id").
+		Where(sq.Eq{"p.participating": true, "s.verified": false, "s.removed": false}).
+		GroupBy("p.event_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ps.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pending := map[string]int{}
+	for rows.Next() {
+		var eid string
+		var count int
+		if err := rows.Scan(&eid, &count); err != nil {
+			return nil, err
+		}
+		pending[eid] = count
+	}
+
+	return pending, rows.Err()
+}
+
+func (ps *PostgresService) ListForParticipation(pid string) ([]ScoreRecord, error) {
+	q := psql.Select(append(submissionColumns, "e.created_at")...).
+		From(ps.ScoresTableName+" as e").
+		Join(ps.ParticipationTableName+" as p on p.id = e.participation_id").
+		LeftJoin(ps.UserIGNTableName+" as u on u.id = p.user_id").
+		Where(sq.Eq{"e.participation_id": pid, "e.withdrawn": false}).
+		OrderBy("e.created_at", "e.id")
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	records := []ScoreRecord{}
+	err = ps.DB.Select(&records, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (ps *PostgresService) WithdrawUnverified(sid, pid string) error {
+	// verified is checked in the same statement, a moderator verifying the submission meanwhile
+	// keeps it. Withdrawn submissions are removed ones that can't be brought back, the row stays
+	// for their history to be looked up
+	res, err := psql.Update(ps.ScoresTableName).
+		Set("removed", true).
+		Set("withdrawn", true).
+		Where(sq.Eq{"id": sid, "participation_id": pid, "verified": false, "removed": false}).
+		RunWith(ps.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return &ErrNoRecord{}
+	}
+
+	return nil
+}
+
 func (ps *PostgresService) DeleteScore(sid string) error {
-	res, err := psql.Delete(ps.ScoresTableName).Where(sq.Eq{"id": sid}).
-		RunWith(ps.DB).Exec()
+	res, err := psql.Update(ps.ScoresTableName).
+		Set("removed", true).
+		Where(sq.Eq{"id": sid, "removed": false}).
+		RunWith(ps.DB).
+		Exec()
 	if err != nil {
 		return err
 	}
@@ -194,14 +436,362 @@ func (ps *PostgresService) DeleteScore(sid string) error {
 	return nil
 }
 
-func (ps *PostgresService) UpdateScoreAndVerify(sid string, score int) error {
+func (ps *PostgresService) UpdateScoreAndVerify(sid string, score int, verifier string) error {
+	var modifiers AppliedModifiers
+	err := psql.Select("modifiers").
+		From(ps.ScoresTableName).
+		Where(sq.Eq{"id": sid}).
+		RunWith(ps.DB).
+		Scan(&modifiers)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &ErrNoRecord{}
+		}
+		return err
+	}
+
 	res, err := psql.Update(ps.ScoresTableName).
-		Set("score", score).
+		Set("score", Apply(score, modifiers)).
+		Set("raw_score", score).
 		Set("verified", true).
-		Where(sq.Eq{"id": sid}).
+		Set("verified_by", verifier).
+		Set("removed", false).
+		Where(sq.Eq{"id": sid, "withdrawn": false}).
+		RunWith(ps.DB).
+		Exec()
+
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return &ErrNoRecord{}
+	}
+
+	return nil
+}
+
+func (ps *PostgresService) CountForEvent(eid string) (participations, submissions int, e error) {
+	q := psql.Select("count(distinct p.id)", "count(s.id)").
+		From(ps.ParticipationTableName + " as p").
+		LeftJoin(ps.ScoresTableName + " as s on s.participation_id = p.id and not s.removed").
+		Where(sq.Eq{"p.event_id": eid})
+
+	err := q.RunWith(ps.DB).QueryRow().Scan(&participations, &submissions)
+	if err != nil {
+		e = err
+		return
+	}
+
+	return participations, submissions, nil
+}
+
+func (ps *PostgresService) SaveResults(eid string, mode LeaderboardMode, leaderboard []SummaryRecord) error {
+	verifiers, err := ps.verifiersForEvent(eid)
+	if err != nil {
+		return err
+	}
+
+	tx, err := ps.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			ps.Logger.Error("could not roll back results", zap.Error(err))
+		}
+	}()
+
+	existing := 0
+	err = psql.Select("count(*)").From(ps.ResultsTableName).Where(sq.Eq{"event_id": eid}).
+		RunWith(tx).QueryRow().Scan(&existing)
+	if err != nil {
+		return err
+	}
+	if existing > 0 {
+		return ErrResultsFinal
+	}
+
+	if len(leaderboard) > 0 {
+		q := psql.Insert(ps.ResultsTableName).
+			Columns("event_id", "position", "rank", "user_id", "ign", "score", "verified_by", "mode")
+		for i, v := range leaderboard {
+			q = q.Values(eid, i+1, v.Rank, v.UID, v.IGN, v.Score, pq.StringArray(verifiers[v.UID]), mode)
+		}
+		if _, err := q.RunWith(tx).Exec(); err != nil {
+			// two snapshots racing past the check above both insert rank 1
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == pgErrUniqueConstraintViolation {
+				return ErrResultsFinal
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// verifiersForEvent maps each user to the moderators who verified their submissions in the event
+func (ps *PostgresService) verifiersForEvent(eid string) (map[string][]string, error) {
+	q := psql.Select("p.user_id as uid", "array_agg(distinct s.verified_by order by s.verified_by) as verified_by").
+		From(ps.ScoresTableName + " as s").
+		Join(ps.ParticipationTableName + " as p on p.id = s.participation_id").
+		Where(sq.And{
+			sq.Eq{"p.event_id": eid, "s.verified": true, "s.removed": false},
+			sq.NotEq{"s.verified_by": ""},
+		}).
+		GroupBy("p.user_id")
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []SummaryRecord{}
+	err = ps.DB.Select(&rows, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	verifiers := make(map[string][]string, len(rows))
+	for _, v := range rows {
+		verifiers[v.UID] = v.VerifiedBy
+	}
+	return verifiers, nil
+}
+
+func (ps *PostgresService) GetResults(eid string) (LeaderboardMode, []SummaryRecord, error) {
+	q := psql.Select("rank", "user_id as uid", "ign", "score", "verified_by", "mode").
+		From(ps.ResultsTableName).
+		Where(sq.Eq{"event_id": eid}).
+		OrderBy("position")
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+
+	// every row of a snapshot has the same mode
+	rows := []struct {
+		SummaryRecord
+		Mode LeaderboardMode `db:"mode"`
+	}{}
+	err = ps.DB.Select(&rows, query, args...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(rows) == 0 {
+		return "", nil, &ErrNoRecord{}
+	}
+
+	leaderboard := make([]SummaryRecord, len(rows))
+	for i, v := range rows {
+		leaderboard[i] = v.SummaryRecord
+	}
+
+	return rows[0].Mode, leaderboard, nil
+}
+
+func (ps *PostgresService) SetModifier(eid string, modifier Modifier) error {
+	_, err := psql.Insert(ps.ModifiersTableName).
+		Columns("event_id", "name", "kind", "value").
+		Values(eid, modifier.Name, modifier.Kind, modifier.Value).
+		Suffix("ON CONFLICT (event_id, name) DO UPDATE SET kind = excluded.kind, value = excluded.value").
+		RunWith(ps.DB).
+		Exec()
+	return err
+}
+
+func (ps *PostgresService) ListModifiers(eid string) ([]Modifier, error) {
+	query, args, err := psql.Select("name", "kind", "value").
+		From(ps.ModifiersTableName).
+		Where(sq.Eq{"event_id": eid}).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	modifiers := []Modifier{}
+	err = ps.DB.Select(&modifiers, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return modifiers, nil
+}
+
+func (ps *PostgresService) DeleteModifier(eid, name string) error {
+	res, err := psql.Delete(ps.ModifiersTableName).
+		Where(sq.Eq{"event_id": eid, "name": name}).
 		RunWith(ps.DB).
 		Exec()
+	if err != nil {
+		return err
+	}
 
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return &ErrNoRecord{}
+	}
+
+	return nil
+}
+
+func (ps *PostgresService) SetCategory(eid string, category Category) error {
+	_, err := psql.Insert(ps.CategoriesTableName).
+		Columns("event_id", "name", "mode").
+		Values(eid, category.Name, category.Mode).
+		Suffix("ON CONFLICT (event_id, name) DO UPDATE SET mode = excluded.mode").
+		RunWith(ps.DB).
+		Exec()
+	return err
+}
+
+func (ps *PostgresService) ListCategories(eid string) ([]Category, error) {
+	query, args, err := psql.Select("name", "mode").
+		From(ps.CategoriesTableName).
+		Where(sq.Eq{"event_id": eid}).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []Category{}
+	err = ps.DB.Select(&categories, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (ps *PostgresService) DeleteCategory(eid, name string) error {
+	submissions := 0
+	err := psql.Select("count(*)").
+		From(ps.ScoresTableName + " as s").
+		Join(ps.ParticipationTableName + " as p on p.id = s.participation_id").
+		Where(sq.Eq{"p.event_id": eid, "s.category": name}).
+		RunWith(ps.DB).
+		Scan(&submissions)
+	if err != nil {
+		return err
+	}
+
+	if submissions > 0 {
+		return ErrCategoryInUse
+	}
+
+	res, err := psql.Delete(ps.CategoriesTableName).
+		Where(sq.Eq{"event_id": eid, "name": name}).
+		RunWith(ps.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return &ErrNoRecord{}
+	}
+
+	return nil
+}
+
+func (ps *PostgresService) RecordHistory(entry HistoryEntry) error {
+	_, err := psql.Insert(ps.HistoryTableName).
+		Columns("submission_id", "action", "actor", "previous_score", "score", "detail").
+		Values(entry.SID, entry.Action, entry.Actor, entry.PreviousScore, entry.Score, entry.Detail).
+		RunWith(ps.DB).
+		Exec()
+	return err
+}
+
+func (ps *PostgresService) GetHistory(sid string) ([]HistoryEntry, error) {
+	query, args, err := psql.Select(
+		"submission_id", "action", "actor", "previous_score", "score", "detail", "created_at",
+	).
+		From(ps.HistoryTableName).
+		Where(sq.Eq{"submission_id": sid}).
+		OrderBy("created_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	history := []HistoryEntry{}
+	err = ps.DB.Select(&history, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (ps *PostgresService) FileAppeal(sid, reason string) error {
+	_, err := psql.Insert(ps.AppealsTableName).
+		Columns("submission_id", "reason", "status").
+		Values(sid, reason, AppealPending).
+		RunWith(ps.DB).
+		Exec()
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == pgErrUniqueConstraintViolation {
+			return ErrAppealExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (ps *PostgresService) ListPendingAppeals(eid string, limit uint64) ([]Appeal, error) {
+	columns := append(submissionColumns, "p.event_id", "a.reason", "a.created_at as appealed_at")
+	query, args, err := psql.Select(columns...).
+		From(ps.AppealsTableName+" as a").
+		Join(ps.ScoresTableName+" as e on e.id = a.submission_id").
+		Join(ps.ParticipationTableName+" as p on p.id = e.participation_id").
+		LeftJoin(ps.UserIGNTableName+" as u on u.id = p.user_id").
+		Where(sq.Eq{"p.event_id": eid, "a.status": AppealPending}).
+		OrderBy("a.created_at", "a.submission_id").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	appeals := []Appeal{}
+	err = ps.DB.Select(&appeals, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return appeals, nil
+}
+
+func (ps *PostgresService) ResolveAppeal(sid string, outcome AppealStatus, resolver string) error {
+	// only pending appeals are updated, two moderators resolving the same appeal can't both win
+	res, err := psql.Update(ps.AppealsTableName).
+		SetMap(map[string]interface{}{
+			"status":      outcome,
+			"resolved_by": resolver,
+			"resolved_at": sq.Expr("current_timestamp"),
+		}).
+		Where(sq.Eq{"submission_id": sid, "status": AppealPending}).
+		RunWith(ps.DB).
+		Exec()
 	if err != nil {
 		return err
 	}
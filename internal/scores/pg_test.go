@@ -97,10 +97,68 @@ func TestNewWorkflow(t *testing.T) {
 		score int NOT NULL,
 		proof text NOT NULL,
 		verified boolean DEFAULT FALSE,
+		verified_by text NOT NULL DEFAULT '',
+		created_at timestamptz DEFAULT current_timestamp,
+		raw_score int,
+		modifiers jsonb NOT NULL DEFAULT '[]',
+		category text NOT NULL DEFAULT '',
+		removed boolean NOT NULL DEFAULT FALSE,
+		withdrawn boolean NOT NULL DEFAULT FALSE,
 		participation_id uuid,
 		FOREIGN KEY (participation_id) REFERENCES participation(id) ON DELETE CASCADE
 	);
 
+	CREATE TABLE event_results (
+		event_id uuid NOT NULL,
+		position int NOT NULL,
+		rank int NOT NULL,
+		user_id text NOT NULL,
+		ign text NOT NULL,
+		score int NOT NULL,
+		verified_by text[] NOT NULL DEFAULT '{}',
+		mode text NOT NULL DEFAULT '',
+		PRIMARY KEY (event_id, position),
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE event_categories (
+		event_id uuid NOT NULL,
+		name text NOT NULL,
+		mode text NOT NULL,
+		PRIMARY KEY (event_id, name),
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE event_modifiers (
+		event_id uuid NOT NULL,
+		name text NOT NULL,
+		kind text NOT NULL,
+		value double precision NOT NULL,
+		PRIMARY KEY (event_id, name),
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE submission_history (
+		id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+		submission_id uuid NOT NULL,
+		action text NOT NULL,
+		actor text NOT NULL,
+		previous_score int NOT NULL,
+		score int NOT NULL,
+		detail text NOT NULL DEFAULT '',
+		created_at timestamptz DEFAULT current_timestamp
+	);
+
+	CREATE TABLE submission_appeals (
+		submission_id uuid PRIMARY KEY,
+		reason text NOT NULL,
+		status text NOT NULL,
+		resolved_by text NOT NULL DEFAULT '',
+		created_at timestamptz DEFAULT current_timestamp,
+		resolved_at timestamptz,
+		FOREIGN KEY (submission_id) REFERENCES event_scores(id) ON DELETE CASCADE
+	);
+
 	INSERT INTO users (
 		id, ign
 	) values (
@@ -156,13 +214,18 @@ func TestNewWorkflow(t *testing.T) {
 		Logger:                 zap.NewNop(),
 		UserIGNTableName:       "users",
 		ParticipationTableName: "participation",
+		ResultsTableName:       "event_results",
+		ModifiersTableName:     "event_modifiers",
+		CategoriesTableName:    "event_categories",
+		HistoryTableName:       "submission_history",
+		AppealsTableName:       "submission_appeals",
 	}
 
 	require := require.New(t)
 	assert := assert.New(t)
 
 	// make a new score claim
-	sid1, err := s.ClaimScore(pid1, 3, "some-url")
+	sid1, err := s.ClaimScore(pid1, "", 3, nil, "some-url")
 	require.NoError(err)
 	assert.NotEmpty(sid1)
 
@@ -172,6 +235,10 @@ func TestNewWorkflow(t *testing.T) {
 	assert.Equal(1, total)
 	assert.Equal(0, verified)
 
+	pendingByEvent, err := s.PendingVerifications()
+	assert.NoError(err)
+	assert.Equal(map[string]int{eid1: 1}, pendingByEvent)
+
 	// make sure we can get a record without specifying eid
 	record, err := s.GetOneUnverified()
 	assert.NoError(err)
@@ -188,8 +255,37 @@ func TestNewWorkflow(t *testing.T) {
 	nr := &scores.ErrNoRecord{}
 	assert.ErrorAs(err, &nr)
 
+	// the pending submission shows up in the list for event 1 only
+	pending, err := s.ListUnverifiedForEvent(eid1, 10)
+	assert.NoError(err)
+	if assert.Len(pending, 1) {
+		assert.Equal(sid1, pending[0].ID)
+		assert.Equal(3, pending[0].Score)
+	}
+
+	pending, err = s.ListUnverifiedForEvent(eid2, 10)
+	assert.NoError(err)
+	assert.Empty(pending)
+
+	// the submission should be traced back to event 1
+	eid, err := s.GetEventIDForSubmission(sid1)
+	assert.NoError(err)
+	assert.Equal(eid1, eid)
+
+	_, err = s.GetEventIDForSubmission(uuid.NewString())
+	assert.True(scores.AsErrNoRecord(err))
+
+	submission, err := s.GetSubmission(sid1)
+	assert.NoError(err)
+	assert.Equal(eid1, submission.EID)
+	assert.Equal(3, submission.Score)
+	assert.False(submission.Verified)
+
+	_, err = s.GetSubmission(uuid.NewString())
+	assert.True(scores.AsErrNoRecord(err))
+
 	// verify the submission
-	err = s.Verify(sid1)
+	err = s.Verify(sid1, "mod-1")
 	assert.NoError(err)
 
 	// make sure the verification status is as expected - 1 total, 1 verified
@@ -202,24 +298,29 @@ func TestNewWorkflow(t *testing.T) {
 	assert.Error(err)
 	assert.ErrorAs(err, &nr)
 
-	leaderboard, err := s.MakeReportScoreSum(eid1)
+	pendingByEvent, err = s.PendingVerifications()
+	assert.NoError(err)
+	assert.Empty(pendingByEvent)
+
+	leaderboard, err := s.MakeReportScoreSum(eid1, scores.TieBreakShared)
 	assert.NoError(err)
 	assert.Equal([]scores.SummaryRecord{
 		{
 			UID:   "test-user-1",
 			IGN:   "test-ign-1",
 			Score: 3,
+			Rank:  1,
 		},
 	}, leaderboard)
 
 	// make another submission user 2 to take over user 1
-	_, err = s.ClaimScore(pid2, 5, "some-url")
+	_, err = s.ClaimScore(pid2, "", 5, nil, "some-url")
 	require.NoError(err)
 
 	// lets verify it
 	score2, err := s.GetOneUnverifiedForEvent(eid1)
 	require.NoError(err)
-	err = s.Verify(score2.ID)
+	err = s.Verify(score2.ID, "mod-2")
 	require.NoError(err)
 
 	// and check verification status / leaderboard
@@ -228,94 +329,444 @@ func TestNewWorkflow(t *testing.T) {
 	assert.Equal(2, total)
 	assert.Equal(2, verified)
 
-	leaderboard, err = s.MakeReportScoreSum(eid1)
+	leaderboard, err = s.MakeReportScoreSum(eid1, scores.TieBreakShared)
 	assert.NoError(err)
 	assert.Equal([]scores.SummaryRecord{
 		{
 			UID:   "test-user-2",
 			IGN:   "test-ign-2",
 			Score: 5,
+			Rank:  1,
 		},
 		{
 			UID:   "test-user-1",
 			IGN:   "test-ign-1",
 			Score: 3,
+			Rank:  2,
 		},
 	}, leaderboard)
 
 	// make another submission by user 1 with 1 score
-	sid3, err := s.ClaimScore(pid1, 1, "http://google.ca")
+	sid3, err := s.ClaimScore(pid1, "", 1, nil, "http://google.ca")
 	require.NoError(err)
 
 	// check leaderboard now
-	leaderboard, err = s.MakeReportScoreSum(eid1)
+	leaderboard, err = s.MakeReportScoreSum(eid1, scores.TieBreakShared)
 	assert.NoError(err)
 	assert.Equal([]scores.SummaryRecord{
 		{
 			UID:   "test-user-2",
 			IGN:   "test-ign-2",
 			Score: 5,
+			Rank:  1,
 		},
 		{
 			UID:   "test-user-1",
 			IGN:   "test-ign-1",
 			Score: 3,
+			Rank:  2,
 		},
 	}, leaderboard)
 
 	// lets buff this score up
-	err = s.UpdateScoreAndVerify(sid3, 9000)
+	err = s.UpdateScoreAndVerify(sid3, 9000, "mod-1")
 	assert.NoError(err)
 
 	// check the leaderboard now
-	leaderboard, err = s.MakeReportScoreSum(eid1)
+	leaderboard, err = s.MakeReportScoreSum(eid1, scores.TieBreakShared)
 	assert.NoError(err)
 	assert.Equal([]scores.SummaryRecord{
 		{
 			UID:   "test-user-1",
 			IGN:   "test-ign-1",
 			Score: 9003,
+			Rank:  1,
 		},
 		{
 			UID:   "test-user-2",
 			IGN:   "test-ign-2",
 			Score: 5,
+			Rank:  2,
 		},
 	}, leaderboard)
 
 	// check the leaderboard in top score mode now
-	leaderboard, err = s.MakeReportScoreTop(eid1)
+	leaderboard, err = s.MakeReportScoreTop(eid1, scores.TieBreakShared)
 	assert.NoError(err)
 	assert.Equal([]scores.SummaryRecord{
 		{
 			UID:   "test-user-1",
 			IGN:   "test-ign-1",
 			Score: 9000,
+			Rank:  1,
 		},
 		{
 			UID:   "test-user-2",
 			IGN:   "test-ign-2",
 			Score: 5,
+			Rank:  2,
 		},
 	}, leaderboard)
 
+	participations, submissions, err := s.CountForEvent(eid1)
+	assert.NoError(err)
+	assert.Equal(2, participations)
+	assert.Equal(3, submissions)
+
+	// user 1 sees both of their submissions to event 1, oldest first
+	mine, err := s.ListForParticipation(pid1)
+	assert.NoError(err)
+	if assert.Len(mine, 2) {
+		assert.Equal(sid1, mine[0].ID)
+		assert.Equal(sid3, mine[1].ID)
+		assert.True(mine[1].Verified)
+		assert.False(mine[0].CreatedAt.IsZero())
+	}
+
+	mine, err = s.ListForParticipation(pid3)
+	assert.NoError(err)
+	assert.Empty(mine)
+
+	// only pending submissions can be withdrawn, and only with the participation they were made with
+	withdrawn, err := s.ClaimScore(pid3, "", 7, nil, "some-url")
+	require.NoError(err)
+	assert.True(scores.AsErrNoRecord(s.WithdrawUnverified(withdrawn, pid1)))
+	assert.True(scores.AsErrNoRecord(s.WithdrawUnverified(sid1, pid1)))
+	assert.NoError(s.WithdrawUnverified(withdrawn, pid3))
+	assert.True(scores.AsErrNoRecord(s.WithdrawUnverified(withdrawn, pid3)))
+
+	mine, err = s.ListForParticipation(pid3)
+	assert.NoError(err)
+	assert.Empty(mine)
+
+	// withdrawn submissions are kept for their history, but can't come back
+	submission, err = s.GetSubmission(withdrawn)
+	assert.NoError(err)
+	assert.True(submission.Withdrawn)
+	assert.True(submission.Removed)
+	assert.True(scores.AsErrNoRecord(s.UpdateScoreAndVerify(withdrawn, 7, "mod-1")))
+	assert.True(scores.AsErrNoRecord(s.Verify(withdrawn, "mod-1")))
+
+	// nothing was snapshotted yet
+	_, _, err = s.GetResults(eid1)
+	assert.True(scores.AsErrNoRecord(err))
+
+	require.NoError(s.SaveResults(eid1, scores.LeaderboardSum, leaderboard))
+	mode, results, err := s.GetResults(eid1)
+	assert.NoError(err)
+	assert.Equal(scores.LeaderboardSum, mode)
+	assert.Equal([]scores.SummaryRecord{
+		{
+			UID:        "test-user-1",
+			IGN:        "test-ign-1",
+			Score:      9000,
+			Rank:       1,
+			VerifiedBy: []string{"mod-1"},
+		},
+		{
+			UID:        "test-user-2",
+			IGN:        "test-ign-2",
+			Score:      5,
+			Rank:       2,
+			VerifiedBy: []string{"mod-2"},
+		},
+	}, results)
+
 	// buffed too much, lets remove it
 	err = s.DeleteScore(sid3)
 	assert.NoError(err)
 
 	// check leaderboard now
-	leaderboard, err = s.MakeReportScoreSum(eid1)
+	leaderboard, err = s.MakeReportScoreSum(eid1, scores.TieBreakShared)
 	assert.NoError(err)
 	assert.Equal([]scores.SummaryRecord{
 		{
 			UID:   "test-user-2",
 			IGN:   "test-ign-2",
 			Score: 5,
+			Rank:  1,
 		},
 		{
 			UID:   "test-user-1",
 			IGN:   "test-ign-1",
 			Score: 3,
+			Rank:  2,
 		},
 	}, leaderboard)
+
+	// the snapshot doesn't follow the change
+	_, results, err = s.GetResults(eid1)
+	assert.NoError(err)
+	assert.Equal(9000, results[0].Score)
+
+	// nor can it be replaced
+	err = s.SaveResults(eid1, scores.LeaderboardSum, leaderboard)
+	assert.ErrorIs(err, scores.ErrResultsFinal)
+	_, results, err = s.GetResults(eid1)
+	assert.NoError(err)
+	assert.Equal(9000, results[0].Score)
+
+	// user 1 catches up to user 2 with a second submission
+	sid4, err := s.ClaimScore(pid1, "", 2, nil, "some-url")
+	require.NoError(err)
+	require.NoError(s.Verify(sid4, "mod-1"))
+
+	// both share first place unless the tie is broken
+	leaderboard, err = s.MakeReportScoreSum(eid1, scores.TieBreakShared)
+	assert.NoError(err)
+	assert.Equal([]scores.SummaryRecord{
+		{
+			UID:   "test-user-1",
+			IGN:   "test-ign-1",
+			Score: 5,
+			Rank:  1,
+		},
+		{
+			UID:   "test-user-2",
+			IGN:   "test-ign-2",
+			Score: 5,
+			Rank:  1,
+		},
+	}, leaderboard)
+
+	// user 2 got there first and with a single submission
+	for _, tieBreak := range []scores.TieBreak{scores.TieBreakEarliest, scores.TieBreakFewest} {
+		leaderboard, err = s.MakeReportScoreSum(eid1, tieBreak)
+		assert.NoError(err)
+		assert.Equal([]scores.SummaryRecord{
+			{
+				UID:   "test-user-2",
+				IGN:   "test-ign-2",
+				Score: 5,
+				Rank:  1,
+			},
+			{
+				UID:   "test-user-1",
+				IGN:   "test-ign-1",
+				Score: 5,
+				Rank:  2,
+			},
+		}, leaderboard, tieBreak)
+	}
+
+	// in top score mode user 1 is behind, and ties are broken by the first submission of the best
+	// score
+	sid5, err := s.ClaimScore(pid1, "", 5, nil, "some-url")
+	require.NoError(err)
+	require.NoError(s.Verify(sid5, "mod-1"))
+
+	leaderboard, err = s.MakeReportScoreTop(eid1, scores.TieBreakEarliest)
+	assert.NoError(err)
+	assert.Equal([]scores.SummaryRecord{
+		{
+			UID:   "test-user-2",
+			IGN:   "test-ign-2",
+			Score: 5,
+			Rank:  1,
+		},
+		{
+			UID:   "test-user-1",
+			IGN:   "test-ign-1",
+			Score: 5,
+			Rank:  2,
+		},
+	}, leaderboard)
+
+	leaderboard, err = s.MakeReportScoreTop(eid1, scores.TieBreakShared)
+	assert.NoError(err)
+	if assert.Len(leaderboard, 2) {
+		assert.Equal(1, leaderboard[1].Rank)
+	}
+
+	// event 2 defines modifiers, setting one with the same name replaces it
+	steelPath := scores.Modifier{Name: "steel-path", Kind: scores.ModifierMultiplier, Value: 2}
+	noDeath := scores.Modifier{Name: "no-death", Kind: scores.ModifierBonus, Value: 100}
+	require.NoError(s.SetModifier(eid2, steelPath))
+	require.NoError(s.SetModifier(eid2, noDeath))
+	steelPath.Value = 1.5
+	require.NoError(s.SetModifier(eid2, steelPath))
+
+	modifiers, err := s.ListModifiers(eid2)
+	assert.NoError(err)
+	assert.Equal([]scores.Modifier{noDeath, steelPath}, modifiers)
+
+	modifiers, err = s.ListModifiers(eid1)
+	assert.NoError(err)
+	assert.Empty(modifiers)
+
+	// both raw and effective scores are kept
+	sid6, err := s.ClaimScore(pid3, "", 100, []scores.Modifier{steelPath, noDeath}, "some-url")
+	require.NoError(err)
+	submission, err = s.GetSubmission(sid6)
+	assert.NoError(err)
+	assert.Equal(250, submission.Score)
+	assert.Equal(100, submission.RawScore)
+	assert.Equal(scores.AppliedModifiers{steelPath, noDeath}, submission.Modifiers)
+
+	// submissions keep the modifiers as they were when made
+	require.NoError(s.DeleteModifier(eid2, "steel-path"))
+	assert.True(scores.AsErrNoRecord(s.DeleteModifier(eid2, "steel-path")))
+
+	// amending replaces the raw score, the modifiers still apply
+	require.NoError(s.UpdateScoreAndVerify(sid6, 200, "mod-1"))
+	submission, err = s.GetSubmission(sid6)
+	assert.NoError(err)
+	assert.Equal(400, submission.Score)
+	assert.Equal(200, submission.RawScore)
+
+	leaderboard, err = s.MakeReportScoreSum(eid2, scores.TieBreakShared)
+	assert.NoError(err)
+	assert.Equal([]scores.SummaryRecord{{UID: "test-user-1", IGN: "test-ign-1", Score: 400, Rank: 1}}, leaderboard)
+
+	// submissions without modifiers have the same raw and effective score
+	submission, err = s.GetSubmission(sid1)
+	assert.NoError(err)
+	assert.Equal(3, submission.RawScore)
+	assert.Empty(submission.Modifiers)
+
+	// event 2 is split into categories, each counted its own way
+	require.NoError(s.SetCategory(eid2, scores.Category{Name: "disruption", Mode: scores.CategorySum}))
+	require.NoError(s.SetCategory(eid2, scores.Category{Name: "disruption", Mode: scores.CategoryTop}))
+	require.NoError(s.SetCategory(eid2, scores.Category{Name: "tricap", Mode: scores.CategoryLowest}))
+
+	categories, err := s.ListCategories(eid2)
+	assert.NoError(err)
+	assert.Equal([]scores.Category{
+		{Name: "disruption", Mode: scores.CategoryTop},
+		{Name: "tricap", Mode: scores.CategoryLowest},
+	}, categories)
+
+	pid4 := uuid.NewString()
+	db.MustExec(fmt.Sprintf(`
+	INSERT INTO participation (
+		id, user_id, event_id, participating
+	) values (
+		'%s', 'test-user-2', '%s', TRUE
+	);
+	`, pid4, eid2))
+
+	for _, v := range []struct {
+		pid, category string
+		score         int
+	}{
+		{pid3, "disruption", 40},
+		{pid4, "disruption", 50},
+		{pid3, "tricap", 300},
+		{pid4, "tricap", 420},
+		{pid4, "tricap", 360},
+	} {
+		sid, err := s.ClaimScore(v.pid, v.category, v.score, nil, "some-url")
+		require.NoError(err)
+		require.NoError(s.Verify(sid, "mod-1"))
+	}
+
+	leaderboard, err = s.MakeReportCategory(eid2, categories[0], scores.TieBreakShared)
+	assert.NoError(err)
+	assert.Equal([]scores.SummaryRecord{
+		{UID: "test-user-2", IGN: "test-ign-2", Score: 50, Rank: 1},
+		{UID: "test-user-1", IGN: "test-ign-1", Score: 40, Rank: 2},
+	}, leaderboard)
+
+	// the fastest time wins
+	leaderboard, err = s.MakeReportCategory(eid2, categories[1], scores.TieBreakShared)
+	assert.NoError(err)
+	assert.Equal([]scores.SummaryRecord{
+		{UID: "test-user-1", IGN: "test-ign-1", Score: 300, Rank: 1},
+		{UID: "test-user-2", IGN: "test-ign-2", Score: 360, Rank: 2},
+	}, leaderboard)
+
+	// one win each makes a tie overall
+	leaderboard, err = s.MakeReportCombined(eid2, scores.TieBreakShared)
+	assert.NoError(err)
+	assert.Equal([]scores.SummaryRecord{
+		{UID: "test-user-1", IGN: "test-ign-1", Score: 3, Rank: 1},
+		{UID: "test-user-2", IGN: "test-ign-2", Score: 3, Rank: 1},
+	}, leaderboard)
+
+	submission, err = s.GetSubmission(sid6)
+	assert.NoError(err)
+	assert.Empty(submission.Category)
+
+	// categories with submissions can't go away
+	assert.ErrorIs(s.DeleteCategory(eid2, "tricap"), scores.ErrCategoryInUse)
+	require.NoError(s.SetCategory(eid2, scores.Category{Name: "unused", Mode: scores.CategorySum}))
+	assert.NoError(s.DeleteCategory(eid2, "unused"))
+	assert.True(scores.AsErrNoRecord(s.DeleteCategory(eid2, "unused")))
+
+	// a moderator amends the submission of user 1, who appeals it
+	appealed, err := s.ClaimScore(pid1, "", 500, nil, "some-url")
+	require.NoError(err)
+	require.NoError(s.UpdateScoreAndVerify(appealed, 50, "mod-1"))
+	require.NoError(s.RecordHistory(scores.HistoryEntry{
+		SID: appealed, Action: scores.HistoryAmended, Actor: "mod-1", PreviousScore: 500, Score: 50,
+	}))
+
+	history, err := s.GetHistory(appealed)
+	assert.NoError(err)
+	assert.True(scores.Appealable(history))
+	assert.Equal(500, scores.ClaimedScore(history, 50))
+
+	require.NoError(s.FileAppeal(appealed, "it really was 500"))
+	assert.ErrorIs(s.FileAppeal(appealed, "again"), scores.ErrAppealExists)
+
+	appeals, err := s.ListPendingAppeals(eid1, 10)
+	assert.NoError(err)
+	if assert.Len(appeals, 1) {
+		assert.Equal(appealed, appeals[0].ID)
+		assert.Equal("test-ign-1", appeals[0].IGN)
+		assert.Equal(50, appeals[0].RawScore)
+		assert.Equal("it really was 500", appeals[0].Reason)
+	}
+
+	appeals, err = s.ListPendingAppeals(eid2, 10)
+	assert.NoError(err)
+	assert.Empty(appeals)
+
+	// only the first resolution counts, and resolved appeals leave the queue
+	require.NoError(s.ResolveAppeal(appealed, scores.AppealGranted, "admin-1"))
+	assert.True(scores.AsErrNoRecord(s.ResolveAppeal(appealed, scores.AppealDenied, "admin-2")))
+
+	appeals, err = s.ListPendingAppeals(eid1, 10)
+	assert.NoError(err)
+	assert.Empty(appeals)
+
+	// removed submissions stop counting but are kept, along with their history, to be appealed
+	require.NoError(s.DeleteScore(appealed))
+	assert.True(scores.AsErrNoRecord(s.DeleteScore(appealed)))
+	assert.True(scores.AsErrNoRecord(s.Verify(appealed, "mod-2")))
+
+	submission, err = s.GetSubmission(appealed)
+	assert.NoError(err)
+	assert.True(submission.Removed)
+
+	history, err = s.GetHistory(appealed)
+	assert.NoError(err)
+	if assert.Len(history, 1) {
+		assert.Equal(scores.HistoryAmended, history[0].Action)
+		assert.Equal("mod-1", history[0].Actor)
+	}
+
+	removed, err := s.ClaimScore(pid4, "disruption", 80, nil, "some-url")
+	require.NoError(err)
+	require.NoError(s.DeleteScore(removed))
+	assert.True(scores.AsErrNoRecord(s.WithdrawUnverified(removed, pid4)))
+
+	_, err = s.GetOneUnverifiedForEvent(eid2)
+	assert.True(scores.AsErrNoRecord(err))
+
+	require.NoError(s.FileAppeal(removed, "it was 80"))
+	appeals, err = s.ListPendingAppeals(eid2, 10)
+	assert.NoError(err)
+	if assert.Len(appeals, 1) {
+		assert.True(appeals[0].Removed)
+	}
+
+	// granting the appeal brings the submission back
+	require.NoError(s.UpdateScoreAndVerify(removed, 80, "admin-1"))
+	leaderboard, err = s.MakeReportCategory(eid2, categories[0], scores.TieBreakShared)
+	assert.NoError(err)
+	assert.Equal([]scores.SummaryRecord{
+		{UID: "test-user-2", IGN: "test-ign-2", Score: 80, Rank: 1},
+		{UID: "test-user-1", IGN: "test-ign-1", Score: 40, Rank: 2},
+	}, leaderboard)
 }
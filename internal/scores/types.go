@@ -1,17 +1,118 @@
 package scores
 
-import "errors"
+import (
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type ScoresService interface {
-	ClaimScore(pid string, score int, proof string) (submissionID string, e error)
+	// ClaimScore records a submission for the category, empty for events without categories, with
+	// the raw score and the modifiers picked for it. The effective score is what the modifiers make
+	// of the raw score.
+	ClaimScore(
+		pid, category string,
+		score int,
+		modifiers []Modifier,
+		proof string,
+	) (submissionID string, e error)
 	GetOneUnverified() (*ScoreRecord, error)
 	GetOneUnverifiedForEvent(eid string) (*ScoreRecord, error)
-	Verify(sid string) error
-	MakeReportScoreSum(eid string) ([]SummaryRecord, error)
-	MakeReportScoreTop(eid string) ([]SummaryRecord, error)
+	ListUnverifiedForEvent(eid string, limit uint64) ([]ScoreRecord, error)
+	GetEventIDForSubmission(sid string) (string, error)
+	GetSubmission(sid string) (*ScoreRecord, error)
+	// Verify marks the submission verified by the given moderator
+	Verify(sid, verifier string) error
+	MakeReportScoreSum(eid string, tieBreak TieBreak) ([]SummaryRecord, error)
+	MakeReportScoreTop(eid string, tieBreak TieBreak) ([]SummaryRecord, error)
+	// MakeReportCategory ranks the submissions of a single category of the event
+	MakeReportCategory(eid string, category Category, tieBreak TieBreak) ([]SummaryRecord, error)
+	// MakeReportCombined ranks users across every category of the event, see CombineStandings
+	MakeReportCombined(eid string, tieBreak TieBreak) ([]SummaryRecord, error)
 	VerificationStatus(eid string) (total, verified int, e error)
+	// PendingVerifications counts the submissions waiting on verification by event, events
+	// without any are left out
+	PendingVerifications() (map[string]int, error)
+	// ListForParticipation returns the submissions made with the participation record, oldest
+	// first
+	ListForParticipation(pid string) ([]ScoreRecord, error)
+	// DeleteScore marks the submission removed, it stops counting anywhere but is kept for its
+	// submitter to appeal. ErrNoRecord is returned if it doesn't exist or was removed already
 	DeleteScore(sid string) error
-	UpdateScoreAndVerify(sid string, score int) error
+	// WithdrawUnverified marks the submission withdrawn if it was made with the participation
+	// record and is not verified yet, ErrNoRecord is returned otherwise. Withdrawn submissions are
+	// kept for their history only, they are left out of ListForParticipation too
+	WithdrawUnverified(sid, pid string) error
+	// UpdateScoreAndVerify replaces the raw score of the submission, the modifiers it was made with
+	// are applied to the new score. Removed submissions are restored, withdrawn ones are reported
+	// missing
+	UpdateScoreAndVerify(sid string, score int, verifier string) error
+	// CountForEvent counts the participation records and submissions of the event, participating
+	// or not
+	CountForEvent(eid string) (participations, submissions int, e error)
+	ResultsService
+	ModifierService
+	CategoryService
+	HistoryService
+	AppealService
+}
+
+// HistoryService keeps track of what happened to each submission after it was made
+type HistoryService interface {
+	// RecordHistory adds the entry to the history of its submission
+	RecordHistory(entry HistoryEntry) error
+	// GetHistory returns the history of the submission oldest first, it's kept after the
+	// submission is gone
+	GetHistory(sid string) ([]HistoryEntry, error)
+}
+
+// AppealService keeps the queue of appeals against moderator decisions, it's separate from the
+// verification queue so the submissions stay counted the way they were decided until resolved
+type AppealService interface {
+	// FileAppeal queues the submission for review, ErrAppealExists is returned if it was
+	// appealed before
+	FileAppeal(sid, reason string) error
+	// ListPendingAppeals returns the unresolved appeals of the event, oldest first
+	ListPendingAppeals(eid string, limit uint64) ([]Appeal, error)
+	// ResolveAppeal closes the pending appeal of the submission with the outcome, ErrNoRecord is
+	// returned if the submission has none
+	ResolveAppeal(sid string, outcome AppealStatus, resolver string) error
+}
+
+// CategoryService keeps the categories each event is split into
+type CategoryService interface {
+	// SetCategory adds the category to the event, replacing the mode of the one with the same name
+	SetCategory(eid string, category Category) error
+	// ListCategories returns the categories of the event ordered by name, events without any take
+	// submissions without a category
+	ListCategories(eid string) ([]Category, error)
+	// DeleteCategory returns ErrNoRecord if the event has no category with that name and
+	// ErrCategoryInUse if submissions were made for it
+	DeleteCategory(eid, name string) error
+}
+
+// ModifierService keeps the scoring modifiers each event defines
+type ModifierService interface {
+	// SetModifier adds the modifier to the event, replacing the one with the same name if any.
+	// Submissions already made keep the modifier as it was.
+	SetModifier(eid string, modifier Modifier) error
+	// ListModifiers returns the modifiers of the event ordered by name
+	ListModifiers(eid string) ([]Modifier, error)
+	// DeleteModifier returns ErrNoRecord if the event has no modifier with that name
+	DeleteModifier(eid, name string) error
+}
+
+// ResultsService keeps leaderboard snapshots of events whose standings must not change anymore,
+// unlike the reports they don't follow later score, IGN or user changes.
+type ResultsService interface {
+	// SaveResults snapshots the given leaderboard of the event in leaderboard order, along with who
+	// verified the submissions of each user and the mode it was made with. Snapshots can't be
+	// changed once taken, saving one for an event that already has one returns ErrResultsFinal.
+	SaveResults(eid string, mode LeaderboardMode, leaderboard []SummaryRecord) error
+	// GetResults returns the snapshot of the event in leaderboard order with the mode it was made
+	// with, ErrNoRecord if there is none. The mode is empty for snapshots taken before it was kept.
+	GetResults(eid string) (LeaderboardMode, []SummaryRecord, error)
 }
 
 type ScoreRecord struct {
@@ -22,14 +123,82 @@ type ScoreRecord struct {
 	Score    int    `db:"score"`
 	Proof    string `db:"proof"`
 	Verified bool   `db:"verified"`
+	// RawScore is the score as submitted, Score is what the modifiers the submission was made
+	// with make of it
+	RawScore  int              `db:"raw_score"`
+	Modifiers AppliedModifiers `db:"modifiers"`
+	// Category is empty for submissions to events without categories
+	Category string `db:"category"`
+	// Removed submissions were taken out by a moderator, they are kept only to be appealed
+	Removed bool `db:"removed"`
+	// Withdrawn submissions were taken back by their submitter, they are Removed as well
+	Withdrawn bool `db:"withdrawn"`
+	// EID is only filled in by GetSubmission
+	EID string `db:"event_id"`
+	// CreatedAt is only filled in by ListForParticipation
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Breakdown explains the score of a submission made with modifiers, empty without any
+func (r ScoreRecord) Breakdown() string {
+	if len(r.Modifiers) == 0 {
+		return ""
+	}
+	return Breakdown(r.RawScore, r.Modifiers)
+}
+
+// LeaderboardMode is how the leaderboard of an event is made
+type LeaderboardMode string
+
+const (
+	// LeaderboardSum adds up every submission of each user
+	LeaderboardSum LeaderboardMode = "sum"
+	// LeaderboardTop only counts the best submission of each user
+	LeaderboardTop LeaderboardMode = "top"
+	// LeaderboardCombined ranks users by their placements across the categories of the event
+	LeaderboardCombined LeaderboardMode = "combined"
+)
+
+// TieBreak decides the order of users with the same score in the reports
+type TieBreak string
+
+const (
+	// TieBreakShared gives users with the same score the same rank
+	TieBreakShared TieBreak = "shared-rank"
+	// TieBreakEarliest ranks whoever reached the score first higher
+	TieBreakEarliest TieBreak = "earliest-submission"
+	// TieBreakFewest ranks whoever needed fewer submissions for the score higher
+	TieBreakFewest TieBreak = "fewest-submissions"
+)
+
+// TieBreaks lists every supported tie break, TieBreakShared is the default
+var TieBreaks = []TieBreak{TieBreakShared, TieBreakEarliest, TieBreakFewest}
+
+// OrDefault returns the tie break, or TieBreakShared if it's empty or not supported
+func (t TieBreak) OrDefault() TieBreak {
+	for _, v := range TieBreaks {
+		if t == v {
+			return t
+		}
+	}
+	return TieBreakShared
 }
 
 type SummaryRecord struct {
 	UID   string `db:"uid"`
 	IGN   string `db:"ign"`
 	Score int    `db:"score"`
+	// Rank is the position of the user on the leaderboard, users still tied after the tie break
+	// share it
+	Rank int `db:"rank"`
+	// VerifiedBy lists the moderators who verified the submissions of the user, only filled in by
+	// GetResults
+	VerifiedBy pq.StringArray `db:"verified_by"`
 }
 
+// ErrResultsFinal is returned when saving the results of an event that already has them
+var ErrResultsFinal = errors.New("results of the event are final")
+
 var _ error = &ErrNoRecord{}
 
 type ErrNoRecord struct{}
@@ -0,0 +1,35 @@
+package settings
+
+import (
+	"github.com/2785/warframe-assistant/internal/cache"
+	"go.uber.org/zap"
+)
+
+// CacheService caches the settings of each guild, the prefix is looked up on every message so
+// this saves a query for most of them.
+type CacheService struct {
+	c cache.Cache
+	l *zap.Logger
+
+	Service
+}
+
+func NewWithCache(s Service, c cache.Cache, l *zap.Logger) *CacheService {
+	return &CacheService{c, l, s}
+}
+
+func (s *CacheService) GetSettings(gid string) (*Settings, error) {
+	settings := &Settings{}
+	err := s.c.Once("settings:"+gid, settings, func() (interface{}, error) {
+		return s.Service.GetSettings(gid)
+	})
+	return settings, err
+}
+
+func (s *CacheService) UpdateSettings(settings *Settings) error {
+	err := s.c.Drop("settings:" + settings.GID)
+	if err != nil {
+		s.l.Error("could not delete entry from cache", zap.Error(err), zap.String("gid", settings.GID))
+	}
+	return s.Service.UpdateSettings(settings)
+}
@@ -0,0 +1,81 @@
+package settings
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+var _ Service = &PostgresService{}
+
+type PostgresService struct {
+	DB        *sqlx.DB
+	Logger    *zap.Logger
+	TableName string
+}
+
+var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+var columns = []string{
+	"guild_id",
+	"prefix",
+	"announcement_channel_id",
+	"submission_channel_ids",
+	"timezone",
+	"default_event_type",
+	"language",
+	"submission_threads",
+	"delete_submissions",
+}
+
+func (ps *PostgresService) GetSettings(gid string) (*Settings, error) {
+	q := psql.Select(columns...).From(ps.TableName).Where(sq.Eq{"guild_id": gid})
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Settings{}
+	err = ps.DB.Get(s, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &Settings{GID: gid}, nil
+		}
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (ps *PostgresService) UpdateSettings(s *Settings) error {
+	_, err := psql.Insert(ps.TableName).
+		Columns(columns...).
+		Values(
+			s.GID,
+			s.Prefix,
+			s.AnnouncementChannel,
+			s.SubmissionChannels,
+			s.Timezone,
+			s.DefaultEventType,
+			s.Language,
+			s.SubmissionThreads,
+			s.DeleteSubmissions,
+		).
+		Suffix(`ON CONFLICT (guild_id) DO UPDATE SET
+			prefix = excluded.prefix,
+			announcement_channel_id = excluded.announcement_channel_id,
+			submission_channel_ids = excluded.submission_channel_ids,
+			timezone = excluded.timezone,
+			default_event_type = excluded.default_event_type,
+			language = excluded.language,
+			submission_threads = excluded.submission_threads,
+			delete_submissions = excluded.delete_submissions,
+			updated_at = current_timestamp`).
+		RunWith(ps.DB).
+		Exec()
+
+	return err
+}
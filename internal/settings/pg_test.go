@@ -0,0 +1,102 @@
+package settings_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/2785/warframe-assistant/internal/settings"
+	"github.com/jmoiron/sqlx"
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var db *sqlx.DB
+
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	dockerHost := os.Getenv("DOCKER_HOST")
+	if dockerHost == "" {
+		dockerHost = "localhost"
+	}
+
+	postgres, err := pool.Run("postgres", "13.2-alpine", []string{"POSTGRES_PASSWORD=password"})
+
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+
+	if err := pool.Retry(func() error {
+		var err error
+		conn := fmt.Sprintf("host=%s port=%s user=postgres password=password dbname=postgres sslmode=disable", dockerHost, postgres.GetPort("5432/tcp"))
+		db, err = sqlx.Open("postgres", conn)
+		if err != nil {
+			return err
+		}
+		return db.Ping()
+	}); err != nil {
+		log.Fatalf("Could not connect to postgres docker container: %s", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(postgres); err != nil {
+		log.Fatalf("Could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+func TestSettingsCrud(t *testing.T) {
+	db.MustExec(`
+	CREATE TABLE guild_settings_test (
+		guild_id text PRIMARY KEY,
+		prefix text NOT NULL DEFAULT '',
+		announcement_channel_id text NOT NULL DEFAULT '',
+		submission_channel_ids text NOT NULL DEFAULT '',
+		timezone text NOT NULL DEFAULT '',
+		default_event_type text NOT NULL DEFAULT '',
+		language text NOT NULL DEFAULT '',
+		submission_threads text NOT NULL DEFAULT '',
+		delete_submissions text NOT NULL DEFAULT '',
+		updated_at timestamptz DEFAULT current_timestamp
+	);
+	`)
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := &settings.PostgresService{DB: db, Logger: zap.NewNop(), TableName: "guild_settings_test"}
+
+	// guilds without settings get empty ones
+	got, err := s.GetSettings("guild-1")
+	require.NoError(err)
+	assert.Equal(&settings.Settings{GID: "guild-1"}, got)
+
+	got.Prefix = "!"
+	got.Timezone = "Europe/Berlin"
+	require.NoError(s.UpdateSettings(got))
+
+	got, err = s.GetSettings("guild-1")
+	require.NoError(err)
+	assert.Equal(&settings.Settings{GID: "guild-1", Prefix: "!", Timezone: "Europe/Berlin"}, got)
+
+	// updating overwrites every setting
+	require.NoError(s.UpdateSettings(&settings.Settings{GID: "guild-1", Language: "en"}))
+
+	got, err = s.GetSettings("guild-1")
+	require.NoError(err)
+	assert.Equal(&settings.Settings{GID: "guild-1", Language: "en"}, got)
+
+	// other guilds are unaffected
+	got, err = s.GetSettings("guild-2")
+	require.NoError(err)
+	assert.Equal(&settings.Settings{GID: "guild-2"}, got)
+}
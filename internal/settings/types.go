@@ -0,0 +1,296 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	// guild timezones are loaded by name, the container images don't ship a zoneinfo database
+	_ "time/tzdata"
+
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/thoas/go-funk"
+)
+
+type Service interface {
+	// GetSettings returns the settings of the guild, guilds that never changed anything get
+	// empty settings
+	GetSettings(gid string) (*Settings, error)
+	// UpdateSettings stores every setting of the guild
+	UpdateSettings(s *Settings) error
+}
+
+// Settings holds the preferences of a guild, empty fields mean the default is used.
+type Settings struct {
+	GID                 string `db:"guild_id"`
+	Prefix              string `db:"prefix"`
+	AnnouncementChannel string `db:"announcement_channel_id"`
+	// SubmissionChannels are the IDs of the channels submissions are taken in, comma separated
+	SubmissionChannels string `db:"submission_channel_ids"`
+	Timezone           string `db:"timezone"`
+	DefaultEventType   string `db:"default_event_type"`
+	Language           string `db:"language"`
+	SubmissionThreads  string `db:"submission_threads"`
+	DeleteSubmissions  string `db:"delete_submissions"`
+}
+
+const (
+	DefaultTimezone = "UTC"
+	DefaultLanguage = "en"
+	maxPrefixLength = 5
+	// maxSubmissionChannels keeps the redirect to the submission channels within a short reply
+	maxSubmissionChannels = 10
+
+	toggleOn = "on"
+)
+
+// SupportedLanguages are the languages the bot can reply in
+var SupportedLanguages = []string{DefaultLanguage}
+
+// Location is the timezone of the guild, UTC unless set
+func (s *Settings) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		// only valid timezones get stored, but the tz database could have dropped one since
+		return time.UTC
+	}
+	return loc
+}
+
+// SubmissionChannelIDs are the channels submissions are taken in, empty if they are taken anywhere
+func (s *Settings) SubmissionChannelIDs() []string {
+	if s.SubmissionChannels == "" {
+		return nil
+	}
+	return strings.Split(s.SubmissionChannels, ",")
+}
+
+// AcceptsSubmissionsIn reports whether submissions can be made in the channel
+func (s *Settings) AcceptsSubmissionsIn(cid string) bool {
+	ids := s.SubmissionChannelIDs()
+	return len(ids) == 0 || funk.ContainsString(ids, cid)
+}
+
+// ThreadsSubmissions reports whether a thread is started for every submission for moderators to
+// discuss it in
+func (s *Settings) ThreadsSubmissions() bool {
+	return s.SubmissionThreads == toggleOn
+}
+
+// DeletesSubmissions reports whether submission messages are deleted once the bot keeps a copy of
+// their proof
+func (s *Settings) DeletesSubmissions() bool {
+	return s.DeleteSubmissions == toggleOn
+}
+
+// ErrInvalidValue is returned when a setting is given a value it can't take, the message is meant
+// for the user.
+type ErrInvalidValue struct{ M string }
+
+func (e *ErrInvalidValue) Error() string {
+	return e.M
+}
+
+func AsErrInvalidValue(e error) (*ErrInvalidValue, bool) {
+	iv := &ErrInvalidValue{}
+	return iv, errors.As(e, &iv)
+}
+
+// Key is a setting that can be viewed and changed by name
+type Key struct {
+	Name        string
+	Description string
+	// Default describes what an empty value falls back to
+	Default string
+
+	field func(s *Settings) *string
+	parse func(v string) (string, error)
+}
+
+// Get returns the stored value of the setting, empty if unset
+func (k *Key) Get(s *Settings) string {
+	return *k.field(s)
+}
+
+// Set validates and normalizes the value before setting it, an empty value resets the setting.
+func (k *Key) Set(s *Settings, v string) error {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		*k.field(s) = ""
+		return nil
+	}
+
+	parsed, err := k.parse(v)
+	if err != nil {
+		return err
+	}
+	*k.field(s) = parsed
+	return nil
+}
+
+// Keys lists every setting in the order they are shown
+var Keys = []*Key{
+	{
+		Name:        "prefix",
+		Description: "Prefix of the message commands, such as submit",
+		field:       func(s *Settings) *string { return &s.Prefix },
+		parse:       parsePrefix,
+	},
+	{
+		Name:        "announcement-channel",
+		Description: "Channel event announcements are posted in",
+		Default:     "not posted",
+		field:       func(s *Settings) *string { return &s.AnnouncementChannel },
+		parse:       parseChannel,
+	},
+	{
+		Name:        "submission-channels",
+		Description: "Channels score submissions are taken in, separated by spaces",
+		Default:     "any channel",
+		field:       func(s *Settings) *string { return &s.SubmissionChannels },
+		parse:       parseChannels,
+	},
+	{
+		Name:        "submission-threads",
+		Description: "Start a thread on every submission for moderators to discuss it in, on or off",
+		Default:     "off",
+		field:       func(s *Settings) *string { return &s.SubmissionThreads },
+		parse:       parseToggle,
+	},
+	{
+		Name:        "delete-submissions",
+		Description: "Delete submission messages once the bot has posted a copy of the proof, on or off",
+		Default:     "off",
+		field:       func(s *Settings) *string { return &s.DeleteSubmissions },
+		parse:       parseToggle,
+	},
+	{
+		Name:        "timezone",
+		Description: "IANA timezone dates are read and shown in, e.g. Europe/Berlin",
+		Default:     DefaultTimezone,
+		field:       func(s *Settings) *string { return &s.Timezone },
+		parse:       parseTimezone,
+	},
+	{
+		Name:        "default-event-type",
+		Description: "Event type used when creating an event without one",
+		Default:     "none, the type has to be given",
+		field:       func(s *Settings) *string { return &s.DefaultEventType },
+		parse:       parseEventType,
+	},
+	{
+		Name:        "language",
+		Description: "Language the bot replies in",
+		Default:     DefaultLanguage,
+		field:       func(s *Settings) *string { return &s.Language },
+		parse:       parseLanguage,
+	},
+}
+
+// Lookup finds a setting by name
+func Lookup(name string) (*Key, bool) {
+	for _, v := range Keys {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func parsePrefix(v string) (string, error) {
+	if len(v) > maxPrefixLength || strings.ContainsAny(v, " \t\n") {
+		return "", &ErrInvalidValue{fmt.Sprintf(
+			"The prefix can't contain spaces and can be at most %d characters long",
+			maxPrefixLength,
+		)}
+	}
+	return v, nil
+}
+
+var channelRe = regexp.MustCompile(`^(?:<#(\d+)>|(\d+))$`)
+
+// parseChannel takes either a channel mention or a bare channel ID
+func parseChannel(v string) (string, error) {
+	match := channelRe.FindStringSubmatch(v)
+	if match == nil {
+		return "", &ErrInvalidValue{"Please mention the channel, e.g. #submissions, or give its ID"}
+	}
+	if match[1] != "" {
+		return match[1], nil
+	}
+	return match[2], nil
+}
+
+// parseChannels takes channel mentions or IDs separated by spaces or commas
+func parseChannels(v string) (string, error) {
+	fields := strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(fields) > maxSubmissionChannels {
+		return "", &ErrInvalidValue{fmt.Sprintf("At most %d channels can be given", maxSubmissionChannels)}
+	}
+
+	ids := []string{}
+	for _, f := range fields {
+		id, err := parseChannel(f)
+		if err != nil {
+			return "", err
+		}
+		if !funk.ContainsString(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return strings.Join(ids, ","), nil
+}
+
+// parseToggle stores switched off settings as empty, the same as when they were never set
+func parseToggle(v string) (string, error) {
+	switch strings.ToLower(v) {
+	case "on", "yes", "true":
+		return toggleOn, nil
+	case "off", "no", "false":
+		return "", nil
+	default:
+		return "", &ErrInvalidValue{fmt.Sprintf("'%s' is neither on nor off", v)}
+	}
+}
+
+func parseTimezone(v string) (string, error) {
+	loc, err := time.LoadLocation(v)
+	if err != nil || v == "Local" {
+		return "", &ErrInvalidValue{fmt.Sprintf(
+			"Unknown timezone '%s', please use an IANA timezone name such as America/New_York",
+			v,
+		)}
+	}
+	return loc.String(), nil
+}
+
+func parseEventType(v string) (string, error) {
+	types := []string{meta.EventTypeScoreCampaign, meta.EventTypeScoreLeaderboard, meta.EventTypeTournament}
+	if !funk.ContainsString(types, v) {
+		return "", &ErrInvalidValue{fmt.Sprintf(
+			"Unknown event type '%s', supported types: %s",
+			v,
+			strings.Join(types, ", "),
+		)}
+	}
+	return v, nil
+}
+
+func parseLanguage(v string) (string, error) {
+	v = strings.ToLower(v)
+	if !funk.ContainsString(SupportedLanguages, v) {
+		return "", &ErrInvalidValue{fmt.Sprintf(
+			"Unsupported language '%s', supported languages: %s",
+			v,
+			strings.Join(SupportedLanguages, ", "),
+		)}
+	}
+	return v, nil
+}
@@ -0,0 +1,78 @@
+package settings_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		want    string
+		invalid bool
+	}{
+		{key: "prefix", value: "!", want: "!"},
+		{key: "prefix", value: "too-long", invalid: true},
+		{key: "prefix", value: "a b", invalid: true},
+		{key: "announcement-channel", value: "<#123456>", want: "123456"},
+		{key: "submission-channels", value: "123456", want: "123456"},
+		{key: "submission-channels", value: "<#123> 456, <#123>", want: "123,456"},
+		{key: "submission-channels", value: "123 #general", invalid: true},
+		{key: "submission-threads", value: "On", want: "on"},
+		{key: "submission-threads", value: "maybe", invalid: true},
+		{key: "delete-submissions", value: "yes", want: "on"},
+		{key: "timezone", value: "America/New_York", want: "America/New_York"},
+		{key: "timezone", value: "Mars/Olympus_Mons", invalid: true},
+		{key: "timezone", value: "Local", invalid: true},
+		{key: "default-event-type", value: "scoreboard-campaign", want: "scoreboard-campaign"},
+		{key: "default-event-type", value: "raid", invalid: true},
+		{key: "language", value: "EN", want: "en"},
+		{key: "language", value: "xx", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			k, ok := settings.Lookup(tt.key)
+			require.True(t, ok)
+
+			s := &settings.Settings{}
+			err := k.Set(s, tt.value)
+			if tt.invalid {
+				_, ok := settings.AsErrInvalidValue(err)
+				assert.True(t, ok)
+				assert.Empty(t, k.Get(s))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, k.Get(s))
+
+			// empty values reset the setting
+			require.NoError(t, k.Set(s, " "))
+			assert.Empty(t, k.Get(s))
+		})
+	}
+
+	_, ok := settings.Lookup("nope")
+	assert.False(t, ok)
+}
+
+func TestSubmissionChannels(t *testing.T) {
+	anywhere := &settings.Settings{}
+	assert.Empty(t, anywhere.SubmissionChannelIDs())
+	assert.True(t, anywhere.AcceptsSubmissionsIn("123"))
+
+	restricted := &settings.Settings{SubmissionChannels: "123,456"}
+	assert.Equal(t, []string{"123", "456"}, restricted.SubmissionChannelIDs())
+	assert.True(t, restricted.AcceptsSubmissionsIn("456"))
+	assert.False(t, restricted.AcceptsSubmissionsIn("789"))
+}
+
+func TestLocation(t *testing.T) {
+	assert.Equal(t, time.UTC, (&settings.Settings{}).Location())
+	assert.Equal(t, "Asia/Tokyo", (&settings.Settings{Timezone: "Asia/Tokyo"}).Location().String())
+}
@@ -0,0 +1,351 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Event types sent to webhooks
+const (
+	EventCreated        = "event.created"
+	EventActivated      = "event.activated"
+	EventClosed         = "event.closed"
+	EventUpdated        = "event.updated"
+	EventFinalized      = "event.finalized"
+	EventArchived       = "event.archived"
+	EventDeleted        = "event.deleted"
+	SubmissionCreated   = "submission.created"
+	SubmissionVerified  = "submission.verified"
+	SubmissionRejected  = "submission.rejected"
+	SubmissionAmended   = "submission.amended"
+	SubmissionWithdrawn = "submission.withdrawn"
+	ParticipantJoined   = "participant.joined"
+	ParticipantBailed   = "participant.bailed"
+	Ping                = "ping"
+)
+
+// Headers set on every delivery
+const (
+	SignatureHeader  = "X-Warframe-Assistant-Signature"
+	EventTypeHeader  = "X-Warframe-Assistant-Event"
+	DeliveryIDHeader = "X-Warframe-Assistant-Delivery"
+)
+
+const (
+	signaturePrefix     = "sha256="
+	defaultMaxAttempts  = 5
+	defaultBackoff      = 2 * time.Second
+	defaultQueueSize    = 256
+	defaultTimeout      = 10 * time.Second
+	maxResponseBodySize = 1 << 10
+)
+
+// Payload is the JSON body every delivery carries, Data depends on the event type.
+type Payload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	GuildID   string      `json:"guild_id"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+type Event struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Start       time.Time  `json:"start"`
+	End         time.Time  `json:"end"`
+	Active      bool       `json:"active"`
+	Archived    bool       `json:"archived"`
+	FinalizedAt *time.Time `json:"finalized_at,omitempty"`
+}
+
+type Submission struct {
+	ID      string `json:"id"`
+	EventID string `json:"event_id"`
+	// Category is empty for events without categories
+	Category string `json:"category,omitempty"`
+	UserID   string `json:"user_id,omitempty"`
+	IGN      string `json:"ign,omitempty"`
+	// Score is the effective score, RawScore the score as submitted before modifiers
+	Score     int      `json:"score"`
+	RawScore  int      `json:"raw_score"`
+	Modifiers []string `json:"modifiers,omitempty"`
+	Proof     string   `json:"proof,omitempty"`
+	// Reason is only given for rejected submissions when the moderator left one
+	Reason string `json:"reason,omitempty"`
+}
+
+type Participant struct {
+	EventID string `json:"event_id"`
+	UserID  string `json:"user_id"`
+	IGN     string `json:"ign,omitempty"`
+}
+
+// ToEvent converts an event into its webhook representation
+func ToEvent(e *meta.Event) Event {
+	return Event{
+		ID:          e.ID,
+		Name:        e.Name,
+		Type:        e.EventType,
+		Start:       e.Begin,
+		End:         e.End,
+		Active:      e.Active,
+		Archived:    e.Archived,
+		FinalizedAt: e.FinalizedAt,
+	}
+}
+
+// Sign returns the value of the signature header for the body, receivers should compute the same
+// HMAC-SHA256 over the raw request body with the secret of the webhook and compare.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+type delivery struct {
+	hook    *meta.Webhook
+	payload *Payload
+	body    []byte
+}
+
+// Dispatcher sends payloads to the webhooks of a guild in the background, failed deliveries are
+// retried with exponential backoff. A nil Dispatcher drops everything, so callers don't need to
+// check whether webhooks are enabled.
+type Dispatcher struct {
+	Hooks meta.WebhookService
+	// Client refuses to connect to anything but public addresses unless replaced
+	Client *http.Client
+	Logger *zap.Logger
+	// MaxAttempts is how many times a delivery is tried before giving up
+	MaxAttempts int
+	// Backoff is the delay before the first retry, it doubles with every further attempt
+	Backoff time.Duration
+
+	queue chan *delivery
+	wg    sync.WaitGroup
+	// ctx is cancelled when Close gives up on the remaining deliveries
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewDispatcher(hooks meta.WebhookService, logger *zap.Logger) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		ctx:         ctx,
+		cancel:      cancel,
+		Hooks:       hooks,
+		Client:      newClient(),
+		Logger:      logger,
+		MaxAttempts: defaultMaxAttempts,
+		Backoff:     defaultBackoff,
+		queue:       make(chan *delivery, defaultQueueSize),
+	}
+}
+
+// Start starts the workers delivering the queued payloads
+func (d *Dispatcher) Start(workers int) {
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for del := range d.queue {
+				if d.ctx.Err() != nil {
+					d.deliveryLogger(del).Warn("abandoned webhook delivery on shutdown")
+					continue
+				}
+				d.deliverWithRetry(del)
+			}
+		}()
+	}
+}
+
+// Close stops accepting new payloads and waits for the queued ones to be delivered, deliveries
+// still pending when ctx expires are logged and abandoned.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		return ctx.Err()
+	}
+}
+
+// Dispatch queues the payload for every webhook of the guild, it never blocks on the deliveries
+// themselves. Payloads are dropped when the queue is full.
+func (d *Dispatcher) Dispatch(gid, eventType string, data interface{}) {
+	if d == nil {
+		return
+	}
+
+	logger := d.Logger.With(zap.String("gid", gid), zap.String("webhook-event", eventType))
+
+	hooks, err := d.Hooks.ListWebhooksForGuild(gid)
+	if err != nil {
+		logger.Error("could not list webhooks", zap.Error(err))
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	payload, body, err := newPayload(gid, eventType, data)
+	if err != nil {
+		logger.Error("could not encode webhook payload", zap.Error(err))
+		return
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		logger.Warn("dispatcher is closed, dropping webhook payload")
+		return
+	}
+
+	for _, hook := range hooks {
+		select {
+		case d.queue <- &delivery{hook: hook, payload: payload, body: body}:
+		default:
+			logger.Warn("webhook queue is full, dropping payload", zap.String("webhook-id", hook.ID))
+		}
+	}
+}
+
+// Ping sends a test payload to every webhook of the guild right away without any retries, the
+// result of each delivery is keyed by webhook ID.
+func (d *Dispatcher) Ping(ctx context.Context, gid string) ([]*meta.Webhook, map[string]error, error) {
+	hooks, err := d.Hooks.ListWebhooksForGuild(gid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload, body, err := newPayload(gid, Ping, struct{}{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make(map[string]error, len(hooks))
+	for _, hook := range hooks {
+		_, results[hook.ID] = d.deliver(ctx, &delivery{hook: hook, payload: payload, body: body})
+	}
+
+	return hooks, results, nil
+}
+
+func newPayload(gid, eventType string, data interface{}) (*Payload, []byte, error) {
+	payload := &Payload{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		GuildID:   gid,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return payload, body, nil
+}
+
+func (d *Dispatcher) deliveryLogger(del *delivery) *zap.Logger {
+	return d.Logger.With(
+		zap.String("gid", del.hook.GID),
+		zap.String("webhook-id", del.hook.ID),
+		zap.String("webhook-event", del.payload.Type),
+		zap.String("delivery-id", del.payload.ID),
+	)
+}
+
+func (d *Dispatcher) deliverWithRetry(del *delivery) {
+	logger := d.deliveryLogger(del)
+
+	backoff := d.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := d.deliver(d.ctx, del)
+		if err == nil {
+			logger.Debug("delivered webhook", zap.Int("attempt", attempt))
+			return
+		}
+
+		if !retry || attempt >= d.MaxAttempts {
+			logger.Warn("giving up on webhook delivery", zap.Error(err), zap.Int("attempt", attempt))
+			return
+		}
+
+		logger.Debug("webhook delivery failed, retrying", zap.Error(err), zap.Int("attempt", attempt))
+		select {
+		case <-time.After(backoff):
+		case <-d.ctx.Done():
+			logger.Warn("abandoned webhook delivery on shutdown", zap.Int("attempt", attempt))
+			return
+		}
+		backoff *= 2
+	}
+}
+
+// deliver makes a single attempt, it reports whether the failure is worth retrying - client
+// errors other than rate limits won't go away by themselves.
+func (d *Dispatcher) deliver(ctx context.Context, del *delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.hook.URL, bytes.NewReader(del.body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "warframe-assistant-webhooks")
+	req.Header.Set(SignatureHeader, Sign(del.hook.Secret, del.body))
+	req.Header.Set(EventTypeHeader, del.payload.Type)
+	req.Header.Set(DeliveryIDHeader, del.payload.ID)
+
+	res, err := d.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+	// receivers only need to acknowledge, but reading the body lets the connection be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxResponseBodySize))
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	return res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests, err
+}
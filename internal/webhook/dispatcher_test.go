@@ -0,0 +1,239 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/2785/warframe-assistant/internal/meta"
+	"github.com/2785/warframe-assistant/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeHooks struct {
+	meta.WebhookService
+	hooks []*meta.Webhook
+}
+
+func (f *fakeHooks) ListWebhooksForGuild(gid string) ([]*meta.Webhook, error) {
+	res := []*meta.Webhook{}
+	for _, v := range f.hooks {
+		if v.GID == gid {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+// receiver records the deliveries it gets, failing the first few with the given status
+type receiver struct {
+	mu       sync.Mutex
+	fail     int
+	status   int
+	attempts int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.attempts++
+	if rc.attempts <= rc.fail {
+		w.WriteHeader(rc.status)
+		return
+	}
+	rc.bodies = append(rc.bodies, body)
+	rc.headers = append(rc.headers, r.Header.Clone())
+}
+
+func newDispatcher(urls ...string) *webhook.Dispatcher {
+	hooks := &fakeHooks{}
+	for i, v := range urls {
+		hooks.hooks = append(hooks.hooks, &meta.Webhook{
+			ID:     string(rune('a' + i)),
+			GID:    "guild-1",
+			URL:    v,
+			Secret: "secret",
+		})
+	}
+
+	d := webhook.NewDispatcher(hooks, zap.NewNop())
+	// the receivers listen on loopback, which the default client refuses
+	d.Client = &http.Client{Timeout: time.Second}
+	d.Backoff = time.Millisecond
+	d.MaxAttempts = 3
+	return d
+}
+
+func TestDeliverySigned(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	d := newDispatcher(srv.URL)
+	d.Start(1)
+	d.Dispatch("guild-1", webhook.SubmissionVerified, webhook.Submission{ID: "sid", EventID: "eid", Score: 10})
+	// other guilds' events don't go to this webhook
+	d.Dispatch("guild-2", webhook.SubmissionVerified, webhook.Submission{ID: "sid", EventID: "eid", Score: 10})
+	require.NoError(d.Close(context.Background()))
+
+	require.Len(rc.bodies, 1)
+	assert.Equal(webhook.Sign("secret", rc.bodies[0]), rc.headers[0].Get(webhook.SignatureHeader))
+	assert.Equal(webhook.SubmissionVerified, rc.headers[0].Get(webhook.EventTypeHeader))
+	assert.Equal("application/json", rc.headers[0].Get("Content-Type"))
+
+	payload := struct {
+		webhook.Payload
+		Data webhook.Submission `json:"data"`
+	}{}
+	require.NoError(json.Unmarshal(rc.bodies[0], &payload))
+	assert.Equal(webhook.SubmissionVerified, payload.Type)
+	assert.Equal("guild-1", payload.GuildID)
+	assert.Equal(rc.headers[0].Get(webhook.DeliveryIDHeader), payload.ID)
+	assert.Equal(webhook.Submission{ID: "sid", EventID: "eid", Score: 10}, payload.Data)
+
+	assert.NotEqual(webhook.Sign("other-secret", rc.bodies[0]), rc.headers[0].Get(webhook.SignatureHeader))
+}
+
+func TestDeliveryRetries(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// server errors are retried until they succeed
+	flaky := &receiver{fail: 2, status: http.StatusBadGateway}
+	flakySrv := httptest.NewServer(flaky)
+	defer flakySrv.Close()
+
+	// and given up on after MaxAttempts
+	down := &receiver{fail: 10, status: http.StatusInternalServerError}
+	downSrv := httptest.NewServer(down)
+	defer downSrv.Close()
+
+	// client errors are never retried
+	bad := &receiver{fail: 10, status: http.StatusBadRequest}
+	badSrv := httptest.NewServer(bad)
+	defer badSrv.Close()
+
+	d := newDispatcher(flakySrv.URL, downSrv.URL, badSrv.URL)
+	d.Start(3)
+	d.Dispatch("guild-1", webhook.EventClosed, webhook.Event{ID: "eid", Name: "event"})
+	require.NoError(d.Close(context.Background()))
+
+	assert.Equal(3, flaky.attempts)
+	assert.Len(flaky.bodies, 1)
+	assert.Equal(3, down.attempts)
+	assert.Empty(down.bodies)
+	assert.Equal(1, bad.attempts)
+}
+
+func TestPing(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ok := &receiver{}
+	okSrv := httptest.NewServer(ok)
+	defer okSrv.Close()
+
+	down := &receiver{fail: 10, status: http.StatusServiceUnavailable}
+	downSrv := httptest.NewServer(down)
+	defer downSrv.Close()
+
+	d := newDispatcher(okSrv.URL, downSrv.URL)
+	hooks, results, err := d.Ping(context.Background(), "guild-1")
+	require.NoError(err)
+	require.Len(hooks, 2)
+
+	assert.NoError(results[hooks[0].ID])
+	assert.Error(results[hooks[1].ID])
+	// pings are not retried
+	assert.Equal(1, down.attempts)
+}
+
+func TestNilDispatcher(t *testing.T) {
+	var d *webhook.Dispatcher
+	assert.NotPanics(t, func() {
+		d.Dispatch("guild-1", webhook.EventCreated, webhook.Event{})
+		assert.NoError(t, d.Close(context.Background()))
+	})
+}
+
+func TestCloseAbandons(t *testing.T) {
+	assert := assert.New(t)
+
+	down := &receiver{fail: 10, status: http.StatusInternalServerError}
+	downSrv := httptest.NewServer(down)
+	defer downSrv.Close()
+
+	d := newDispatcher(downSrv.URL)
+	d.Backoff = time.Hour
+	d.Start(1)
+	d.Dispatch("guild-1", webhook.EventCreated, webhook.Event{ID: "eid"})
+	d.Dispatch("guild-1", webhook.EventActivated, webhook.Event{ID: "eid"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.ErrorIs(d.Close(ctx), context.DeadlineExceeded)
+	assert.Less(int64(time.Since(start)), int64(time.Second))
+
+	// nothing gets queued once closed
+	d.Dispatch("guild-1", webhook.EventClosed, webhook.Event{ID: "eid"})
+}
+
+func TestNonPublicAddressesRefused(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	// the receiver is on loopback, which a webhook can't be pointed at
+	d := newDispatcher(srv.URL)
+	d.Client = webhook.NewDispatcher(nil, zap.NewNop()).Client
+
+	hooks, results, err := d.Ping(context.Background(), "guild-1")
+	require.NoError(err)
+	require.Len(hooks, 1)
+	assert.ErrorIs(results[hooks[0].ID], webhook.ErrNonPublicAddress)
+	assert.Zero(rc.attempts)
+}
+
+func TestValidateURL(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, v := range []string{
+		"http://1.1.1.1/hook",
+		"https://",
+		"not a url",
+		"https://127.0.0.1/hook",
+		"https://10.0.0.1/hook",
+		"https://172.16.3.4/hook",
+		"https://192.168.1.1/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/hook",
+		"https://[fd00::1]/hook",
+		"https://[::ffff:127.0.0.1]/hook",
+	} {
+		_, err := webhook.ValidateURL(v)
+		assert.Error(err, v)
+	}
+
+	u, err := webhook.ValidateURL("https://1.1.1.1/hook")
+	assert.NoError(err)
+	assert.Equal("https://1.1.1.1/hook", u.String())
+}